@@ -8,6 +8,7 @@ import (
 type AddressResponse struct {
 	Balance          map[string]*big.Int `json:"balance"`
 	TransactionCount uint64              `json:"transaction_count"`
+	AutoCompound     bool                `json:"auto_compound"`
 }
 
 func Address(address types.Address, height int) (*AddressResponse, error) {
@@ -19,6 +20,7 @@ func Address(address types.Address, height int) (*AddressResponse, error) {
 	response := AddressResponse{
 		Balance:          make(map[string]*big.Int),
 		TransactionCount: cState.GetNonce(address),
+		AutoCompound:     cState.IsAutoCompound(address),
 	}
 
 	balances := cState.GetBalances(address)
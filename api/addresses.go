@@ -9,6 +9,7 @@ type AddressesResponse struct {
 	Address          types.Address       `json:"address"`
 	Balance          map[string]*big.Int `json:"balance"`
 	TransactionCount uint64              `json:"transaction_count"`
+	AutoCompound     bool                `json:"auto_compound"`
 }
 
 func Addresses(addresses []types.Address, height int) (*[]AddressesResponse, error) {
@@ -24,6 +25,7 @@ func Addresses(addresses []types.Address, height int) (*[]AddressesResponse, err
 			Address:          address,
 			Balance:          make(map[string]*big.Int),
 			TransactionCount: cState.GetNonce(address),
+			AutoCompound:     cState.IsAutoCompound(address),
 		}
 
 		balances := cState.GetBalances(address)
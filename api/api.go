@@ -9,12 +9,14 @@ import (
 	"github.com/MinterTeam/minter-go-node/eventsdb/events"
 	"github.com/MinterTeam/minter-go-node/log"
 	"github.com/MinterTeam/minter-go-node/rpc/lib/server"
+	"github.com/MinterTeam/minter-go-node/rpc/lib/types"
 	"github.com/rs/cors"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/crypto/multisig"
 	"github.com/tendermint/tendermint/crypto/secp256k1"
 	"github.com/tendermint/tendermint/evidence"
+	dbm "github.com/tendermint/tendermint/libs/db"
 	rpc "github.com/tendermint/tendermint/rpc/client"
 	"github.com/tendermint/tendermint/types"
 	"net/http"
@@ -28,8 +30,17 @@ var (
 	blockchain *minter.Blockchain
 	client     *rpc.Local
 	minterCfg  *config.Config
+
+	offlineStateDB     dbm.DB
+	offlineStateHeight uint64
 )
 
+func init() {
+	RegisterCryptoAmino(cdc)
+	events.RegisterAminoEvents(cdc)
+	RegisterEvidenceMessages(cdc)
+}
+
 var Routes = map[string]*rpcserver.RPCFunc{
 	"status":                 rpcserver.NewRPCFunc(Status, ""),
 	"candidates":             rpcserver.NewRPCFunc(Candidates, "height,include_stakes"),
@@ -50,17 +61,22 @@ var Routes = map[string]*rpcserver.RPCFunc{
 	"estimate_tx_commission": rpcserver.NewRPCFunc(EstimateTxCommission, "tx,height"),
 	"unconfirmed_txs":        rpcserver.NewRPCFunc(UnconfirmedTxs, "limit"),
 	"max_gas":                rpcserver.NewRPCFunc(MaxGas, "height"),
+	"max_gas_history":        rpcserver.NewRPCFunc(MaxGasHistory, "from,to"),
 	"min_gas_price":          rpcserver.NewRPCFunc(MinGasPrice, ""),
+	"gas_price":              rpcserver.NewRPCFunc(GasPrice, "blocks"),
 	"genesis":                rpcserver.NewRPCFunc(Genesis, ""),
 	"missed_blocks":          rpcserver.NewRPCFunc(MissedBlocks, "pub_key,height"),
+	"frozen_funds":           rpcserver.NewRPCFunc(FrozenFunds, "address,height"),
+	"state_diff":             rpcserver.NewRPCFunc(StateDiff, "from,to"),
+	"features":               rpcserver.NewRPCFunc(Features, "height"),
+	"proposals":              rpcserver.NewRPCFunc(Proposals, "height"),
+	"proposal":               rpcserver.NewRPCFunc(Proposal, "id,height"),
+	"block_rewards":          rpcserver.NewRPCFunc(BlockRewards, "height"),
+	"address_rewards":        rpcserver.NewRPCFunc(AddressRewards, "address,from,to"),
 }
 
 func RunAPI(b *minter.Blockchain, tmRPC *rpc.Local, cfg *config.Config) {
 	minterCfg = cfg
-	RegisterCryptoAmino(cdc)
-	events.RegisterAminoEvents(cdc)
-	RegisterEvidenceMessages(cdc)
-
 	client = tmRPC
 	blockchain = b
 	waitForTendermint()
@@ -124,7 +140,31 @@ type Response struct {
 	Log    string      `json:"log,omitempty"`
 }
 
+// UseStateDB makes API functions read state directly from given db instead of a running node.
+// It is used to inspect state of a stopped node.
+func UseStateDB(db dbm.DB, lastHeight uint64) {
+	offlineStateDB, offlineStateHeight = db, lastHeight
+}
+
+// MarshalJSON encodes value the same way as API responses are encoded
+func MarshalJSON(value interface{}) ([]byte, error) {
+	return cdc.MarshalJSONIndent(value, "", "  ")
+}
+
 func GetStateForHeight(height int) (*state.StateDB, error) {
+	if blockchain == nil {
+		if height <= 0 {
+			height = int(offlineStateHeight)
+		}
+
+		cState, err := state.NewForCheck(uint64(height), offlineStateDB)
+		if err != nil {
+			return nil, rpctypes.RPCError{Code: 404, Message: "State at given height not found", Data: err.Error()}
+		}
+
+		return cState, nil
+	}
+
 	if height > 0 {
 		cState, err := blockchain.GetStateForHeight(uint64(height))
 
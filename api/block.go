@@ -71,9 +71,11 @@ func Block(height int64) (*BlockResponse, error) {
 		return nil, rpctypes.RPCError{Code: 404, Message: "Validators for block not found", Data: err.Error()}
 	}
 
+	commissions := blockchain.ChainParams().Commissions
 	txs := make([]BlockTransactionResponse, len(block.Block.Data.Txs))
 	for i, rawTx := range block.Block.Data.Txs {
 		tx, _ := transaction.TxDecoder.DecodeFromBytes(rawTx)
+		tx.SetCommissions(commissions)
 		sender, _ := tx.Sender()
 
 		tags := make(map[string]string)
@@ -144,7 +146,7 @@ func Block(height int64) (*BlockResponse, error) {
 		NumTxs:       block.Block.NumTxs,
 		TotalTxs:     block.Block.TotalTxs,
 		Transactions: txs,
-		BlockReward:  rewards.GetRewardForBlock(uint64(height)),
+		BlockReward:  rewards.GetRewardForBlock(blockchain.ChainParams(), uint64(height)),
 		Size:         len(cdc.MustMarshalBinaryLengthPrefixed(block)),
 		Proposer:     proposer,
 		Validators:   validators,
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/eventsdb/events"
+	"github.com/MinterTeam/minter-go-node/rpc/lib/types"
+	"math/big"
+)
+
+// addressRewardsLimit is a max number of blocks which rewards of address are aggregated for at once
+const addressRewardsLimit = 1000
+
+// BlockRewards returns distribution of reward of a block between validators
+func BlockRewards(height uint64) (*appdb.BlockRewards, error) {
+	rewards := blockchain.GetBlockRewards(height)
+	if rewards == nil {
+		return nil, rpctypes.RPCError{Code: 404, Message: "Block rewards not found"}
+	}
+
+	return rewards, nil
+}
+
+type AddressRewardsResponse struct {
+	Address types.Address `json:"address"`
+	From    uint64        `json:"from"`
+	To      uint64        `json:"to"`
+	// Accrued is a sum of block reward shares of validators with this reward address
+	Accrued string `json:"accrued"`
+	// Paid are rewards paid to address by role
+	Paid      map[string]string `json:"paid"`
+	TotalPaid string            `json:"total_paid"`
+}
+
+// AddressRewards aggregates rewards of address in given range of heights
+func AddressRewards(address types.Address, from uint64, to uint64) (*AddressRewardsResponse, error) {
+	if from == 0 || from > to || to-from >= addressRewardsLimit {
+		return nil, rpctypes.RPCError{Code: 400, Message: "Range of heights is invalid or too large"}
+	}
+
+	accrued := big.NewInt(0)
+	totalPaid := big.NewInt(0)
+	paid := map[string]*big.Int{}
+
+	for height := from; height <= to; height++ {
+		if rewards := blockchain.GetBlockRewards(height); rewards != nil {
+			for _, validator := range rewards.Validators {
+				if validator.RewardAddress != address {
+					continue
+				}
+
+				reward, _ := big.NewInt(0).SetString(validator.Reward, 10)
+				accrued.Add(accrued, reward)
+			}
+		}
+
+		for _, event := range blockchain.EventsDB().LoadEvents(height) {
+			reward, ok := event.(events.RewardEvent)
+			if !ok || reward.Address != address {
+				continue
+			}
+
+			amount := big.NewInt(0).SetBytes(reward.Amount)
+			role := reward.Role.String()
+			if _, exists := paid[role]; !exists {
+				paid[role] = big.NewInt(0)
+			}
+
+			paid[role].Add(paid[role], amount)
+			totalPaid.Add(totalPaid, amount)
+		}
+	}
+
+	response := &AddressRewardsResponse{
+		Address:   address,
+		From:      from,
+		To:        to,
+		Accrued:   accrued.String(),
+		Paid:      make(map[string]string, len(paid)),
+		TotalPaid: totalPaid.String(),
+	}
+
+	for role, amount := range paid {
+		response.Paid[role] = amount.String()
+	}
+
+	return response, nil
+}
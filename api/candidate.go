@@ -23,6 +23,21 @@ type CandidateResponse struct {
 	Stakes         []Stake       `json:"stakes,omitempty"`
 	CreatedAtBlock uint          `json:"created_at_block"`
 	Status         byte          `json:"status"`
+	Jail           *Jail         `json:"jail,omitempty"`
+}
+
+type Jail struct {
+	Jailed      bool        `json:"jailed"`
+	JailedUntil uint64      `json:"jailed_until"`
+	Offences    uint64      `json:"offences"`
+	History     []JailEntry `json:"history"`
+}
+
+type JailEntry struct {
+	Height       uint64 `json:"height"`
+	JailedUntil  uint64 `json:"jailed_until"`
+	SlashPercent uint64 `json:"slash_percent"`
+	UnjailedAt   uint64 `json:"unjailed_at,omitempty"`
 }
 
 func makeResponseCandidate(c state.Candidate, includeStakes bool) CandidateResponse {
@@ -63,5 +78,29 @@ func Candidate(pubkey []byte, height int) (*CandidateResponse, error) {
 	}
 
 	response := makeResponseCandidate(*candidate, true)
+	if record := cState.GetJailRecord(pubkey); record != nil {
+		response.Jail = makeResponseJail(*record)
+	}
+
 	return &response, nil
 }
+
+func makeResponseJail(record state.JailRecord) *Jail {
+	jail := &Jail{
+		Jailed:      record.Jailed,
+		JailedUntil: record.JailedUntil,
+		Offences:    record.Offences,
+		History:     make([]JailEntry, len(record.History)),
+	}
+
+	for i, entry := range record.History {
+		jail.History[i] = JailEntry{
+			Height:       entry.Height,
+			JailedUntil:  entry.JailedUntil,
+			SlashPercent: entry.SlashPercent,
+			UnjailedAt:   entry.UnjailedAt,
+		}
+	}
+
+	return jail
+}
@@ -2,7 +2,6 @@ package api
 
 import (
 	"fmt"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/transaction"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -38,7 +37,7 @@ func EstimateCoinBuy(coinToSellString string, coinToBuyString string, valueToBuy
 		return nil, rpctypes.RPCError{Code: 404, Message: "Coin to buy not exists"}
 	}
 
-	commissionInBaseCoin := big.NewInt(commissions.ConvertTx)
+	commissionInBaseCoin := big.NewInt(int64(cState.GetChainParams().Commissions.ConvertTx))
 	commissionInBaseCoin.Mul(commissionInBaseCoin, transaction.CommissionMultiplier)
 	commission := big.NewInt(0).Set(commissionInBaseCoin)
 
@@ -23,6 +23,7 @@ func EstimateTxCommission(tx []byte, height int) (*TxCommissionResponse, error)
 		return nil, rpctypes.RPCError{Code: 400, Message: "Cannot decode transaction", Data: err.Error()}
 	}
 
+	decodedTx.SetCommissions(cState.GetChainParams().Commissions)
 	commissionInBaseCoin := decodedTx.CommissionInBaseCoin()
 	commission := big.NewInt(0).Set(commissionInBaseCoin)
 
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"github.com/MinterTeam/minter-go-node/eventsdb"
 	"github.com/MinterTeam/minter-go-node/eventsdb/events"
 )
 
@@ -11,6 +10,6 @@ type EventsResponse struct {
 
 func Events(height uint64) (*EventsResponse, error) {
 	return &EventsResponse{
-		Events: eventsdb.GetCurrent().LoadEvents(height),
+		Events: blockchain.EventsDB().LoadEvents(height),
 	}, nil
 }
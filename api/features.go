@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/MinterTeam/minter-go-node/upgrades"
+)
+
+type FeaturesResponse struct {
+	Height   uint64                   `json:"height"`
+	Features []upgrades.FeatureStatus `json:"features"`
+}
+
+// Features lists features scheduled on the current chain and whether they are active at given height, the latest
+// block is used if height is 0
+func Features(height int) (*FeaturesResponse, error) {
+	h := uint64(height)
+	if h == 0 {
+		h = blockchain.Height()
+	}
+
+	return &FeaturesResponse{
+		Height:   h,
+		Features: upgrades.Features(h),
+	}, nil
+}
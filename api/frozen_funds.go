@@ -0,0 +1,39 @@
+package api
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+)
+
+type FrozenFundResponse struct {
+	Height       uint64           `json:"height"`
+	Address      types.Address    `json:"address"`
+	CandidateKey types.Pubkey     `json:"candidate_key"`
+	Coin         types.CoinSymbol `json:"coin"`
+	Value        string           `json:"value"`
+}
+
+func FrozenFunds(address *types.Address, height int) (*[]FrozenFundResponse, error) {
+	cState, err := GetStateForHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []FrozenFundResponse{}
+	for _, frozenFunds := range cState.GetAllStateFrozenFunds() {
+		for _, fund := range frozenFunds.List() {
+			if address != nil && fund.Address != *address {
+				continue
+			}
+
+			result = append(result, FrozenFundResponse{
+				Height:       frozenFunds.BlockHeight(),
+				Address:      fund.Address,
+				CandidateKey: fund.CandidateKey,
+				Coin:         fund.Coin,
+				Value:        fund.Value.String(),
+			})
+		}
+	}
+
+	return &result, nil
+}
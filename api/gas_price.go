@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/minter"
+)
+
+// defaultInclusionBlocks is a number of blocks for which recommended gas price is computed if not set
+const defaultInclusionBlocks = 1
+
+type GasPriceResponse struct {
+	MinGasPrice         uint64                     `json:"min_gas_price"`
+	StaticMinGasPrice   uint64                     `json:"static_min_gas_price"`
+	MempoolSize         int                        `json:"mempool_size"`
+	Thresholds          []minter.GasPriceThreshold `json:"thresholds"`
+	InclusionBlocks     int                        `json:"inclusion_blocks"`
+	RecommendedGasPrice uint64                     `json:"recommended_gas_price"`
+}
+
+// GasPrice returns minimal gas price of txs accepted to mempool along with its curve and gas price which is enough
+// for a tx to be included within given number of blocks
+func GasPrice(blocks int) (*GasPriceResponse, error) {
+	if blocks <= 0 {
+		blocks = defaultInclusionBlocks
+	}
+
+	return &GasPriceResponse{
+		MinGasPrice:         uint64(blockchain.MinGasPrice()),
+		StaticMinGasPrice:   uint64(blockchain.StaticMinGasPrice()),
+		MempoolSize:         blockchain.MempoolSize(),
+		Thresholds:          blockchain.GasPriceThresholds(),
+		InclusionBlocks:     blocks,
+		RecommendedGasPrice: uint64(blockchain.RecommendedGasPrice(blocks)),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/rpc/lib/types"
+)
+
+// maxGasHistoryLimit is a max number of blocks in max gas history returned at once
+const maxGasHistoryLimit = 1000
+
+// MaxGasHistory returns max gas of blocks in given range of heights. Missing bound of the range is set to get up to
+// maxGasHistoryLimit blocks.
+func MaxGasHistory(from int, to int) ([]appdb.MaxGasRecord, error) {
+	if from < 0 || to < 0 {
+		return nil, rpctypes.RPCError{Code: 400, Message: "Range of heights should not be negative"}
+	}
+
+	start, end := uint64(from), uint64(to)
+	if end == 0 {
+		if start == 0 {
+			end = blockchain.LastCommittedHeight()
+		} else {
+			end = start + maxGasHistoryLimit - 1
+		}
+	}
+
+	if start == 0 && end >= maxGasHistoryLimit {
+		start = end - maxGasHistoryLimit + 1
+	}
+
+	if start > end || end-start >= maxGasHistoryLimit {
+		return nil, rpctypes.RPCError{Code: 400, Message: "Range of heights is invalid or too large"}
+	}
+
+	return blockchain.GetMaxGasHistory(start, end), nil
+}
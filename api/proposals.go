@@ -0,0 +1,101 @@
+package api
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/rpc/lib/types"
+)
+
+type ProposalVoteResponse struct {
+	Voter  types.Address `json:"voter"`
+	Option string        `json:"option"`
+}
+
+type ProposalTallyResponse struct {
+	Yes     string `json:"yes"`
+	No      string `json:"no"`
+	Abstain string `json:"abstain"`
+	Total   string `json:"total"`
+}
+
+type ProposalResponse struct {
+	ID              uint64                 `json:"id"`
+	Proposer        types.Address          `json:"proposer"`
+	Type            string                 `json:"type"`
+	Param           string                 `json:"param"`
+	Value           uint64                 `json:"value"`
+	Height          uint64                 `json:"height"`
+	VotingEndHeight uint64                 `json:"voting_end_height"`
+	Status          string                 `json:"status"`
+	Votes           []ProposalVoteResponse `json:"votes"`
+	Tally           ProposalTallyResponse  `json:"tally"`
+}
+
+// makeResponseProposal returns proposal with its final tally, or with a tally by current stakes if voting is not
+// finished yet
+func makeResponseProposal(cState *state.StateDB, p state.Proposal) ProposalResponse {
+	tally := p.Tally
+	if p.Status == state.ProposalStatusVoting {
+		tally = cState.TallyProposal(p)
+	}
+
+	votes := cState.GetProposalVotes(p.ID)
+
+	proposal := ProposalResponse{
+		ID:              p.ID,
+		Proposer:        p.Proposer,
+		Type:            p.Type.String(),
+		Param:           p.Param,
+		Value:           p.Value,
+		Height:          p.Height,
+		VotingEndHeight: p.VotingEndHeight,
+		Status:          p.Status.String(),
+		Votes:           make([]ProposalVoteResponse, len(votes)),
+		Tally: ProposalTallyResponse{
+			Yes:     tally.Yes.String(),
+			No:      tally.No.String(),
+			Abstain: tally.Abstain.String(),
+			Total:   tally.Total.String(),
+		},
+	}
+
+	for i, vote := range votes {
+		proposal.Votes[i] = ProposalVoteResponse{
+			Voter:  vote.Voter,
+			Option: vote.Option.String(),
+		}
+	}
+
+	return proposal
+}
+
+func Proposals(height int) ([]ProposalResponse, error) {
+	cState, err := GetStateForHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	proposals := cState.GetProposals()
+
+	result := make([]ProposalResponse, len(proposals))
+	for i, proposal := range proposals {
+		result[i] = makeResponseProposal(cState, proposal)
+	}
+
+	return result, nil
+}
+
+func Proposal(id uint64, height int) (*ProposalResponse, error) {
+	cState, err := GetStateForHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal := cState.GetProposal(id)
+	if proposal == nil {
+		return nil, rpctypes.RPCError{Code: 404, Message: "Proposal not found"}
+	}
+
+	response := makeResponseProposal(cState, *proposal)
+	return &response, nil
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/rpc/lib/types"
+)
+
+func StateDiff(from int, to int) (*state.Diff, error) {
+	if from <= 0 || to <= 0 || from >= to {
+		return nil, rpctypes.RPCError{Code: 400, Message: "Invalid heights range"}
+	}
+
+	fromState, err := GetStateForHeight(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toState, err := GetStateForHeight(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return state.GetDiff(fromState, toState), nil
+}
@@ -14,9 +14,16 @@ type StatusResponse struct {
 	LatestBlockHeight int64                    `json:"latest_block_height"`
 	LatestBlockTime   time.Time                `json:"latest_block_time"`
 	StateHistory      string                   `json:"state_history"`
+	PendingHalt       *PendingHalt             `json:"pending_halt,omitempty"`
 	TmStatus          *core_types.ResultStatus `json:"tm_status"`
 }
 
+// PendingHalt describes a scheduled stop of the node, e.g. for an upgrade
+type PendingHalt struct {
+	Height uint64 `json:"height"`
+	Reason string `json:"reason"`
+}
+
 func Status() (*StatusResponse, error) {
 	result, err := client.Status()
 	if err != nil {
@@ -28,6 +35,14 @@ func Status() (*StatusResponse, error) {
 		stateHistory = "on"
 	}
 
+	var pendingHalt *PendingHalt
+	if height, reason := blockchain.PendingHalt(); height != 0 {
+		pendingHalt = &PendingHalt{
+			Height: height,
+			Reason: reason,
+		}
+	}
+
 	return &StatusResponse{
 		MinterVersion:     version.Version,
 		LatestBlockHash:   fmt.Sprintf("%X", result.SyncInfo.LatestBlockHash),
@@ -35,6 +50,7 @@ func Status() (*StatusResponse, error) {
 		LatestBlockHeight: result.SyncInfo.LatestBlockHeight,
 		LatestBlockTime:   result.SyncInfo.LatestBlockTime,
 		StateHistory:      stateHistory,
+		PendingHalt:       pendingHalt,
 		TmStatus:          result,
 	}, nil
 }
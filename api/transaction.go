@@ -18,6 +18,7 @@ func Transaction(hash []byte) (*TransactionResponse, error) {
 	}
 
 	decodedTx, _ := transaction.TxDecoder.DecodeFromBytes(tx.Tx)
+	decodedTx.SetCommissions(blockchain.ChainParams().Commissions)
 	sender, _ := decodedTx.Sender()
 
 	tags := make(map[string]string)
@@ -79,6 +80,14 @@ func encodeTxData(decodedTx *transaction.Transaction) ([]byte, error) {
 		return cdc.MarshalJSON(decodedTx.GetDecodedData().(*transaction.MultisendData))
 	case transaction.TypeEditCandidate:
 		return cdc.MarshalJSON(decodedTx.GetDecodedData().(*transaction.EditCandidateData))
+	case transaction.TypeSubmitProposal:
+		return cdc.MarshalJSON(decodedTx.GetDecodedData().(*transaction.SubmitProposalData))
+	case transaction.TypeVote:
+		return cdc.MarshalJSON(decodedTx.GetDecodedData().(*transaction.VoteData))
+	case transaction.TypeUnjail:
+		return cdc.MarshalJSON(decodedTx.GetDecodedData().(*transaction.UnjailData))
+	case transaction.TypeSetAutoCompound:
+		return cdc.MarshalJSON(decodedTx.GetDecodedData().(*transaction.SetAutoCompoundData))
 	}
 
 	return nil, rpctypes.RPCError{Code: 500, Message: "unknown tx type"}
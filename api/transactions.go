@@ -45,9 +45,11 @@ func Transactions(query string, page, perPage int) (*[]TransactionResponse, erro
 		return nil, err
 	}
 
+	commissions := blockchain.ChainParams().Commissions
 	result := make([]TransactionResponse, len(rpcResult.Txs))
 	for i, tx := range rpcResult.Txs {
 		decodedTx, _ := transaction.TxDecoder.DecodeFromBytes(tx.Tx)
+		decodedTx.SetCommissions(commissions)
 		sender, _ := decodedTx.Sender()
 
 		tags := make(map[string]string)
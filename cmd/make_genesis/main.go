@@ -150,7 +150,7 @@ func main() {
 		Validators:   validators,
 		Candidates:   candidates,
 		Accounts:     bals,
-		MaxGas:       minter.DefaultMaxGas,
+		MaxGas:       types.DefaultChainParams().MaxMaxGas,
 		TotalSlashed: big.NewInt(0),
 		FrozenFunds:  frozenFunds,
 	}, "", "	")
@@ -168,7 +168,7 @@ func main() {
 		ConsensusParams: &tmTypes.ConsensusParams{
 			Block: tmTypes.BlockParams{
 				MaxBytes:   minter.BlockMaxBytes,
-				MaxGas:     minter.DefaultMaxGas,
+				MaxGas:     int64(types.DefaultChainParams().MaxMaxGas),
 				TimeIotaMs: 1000,
 			},
 			Evidence: tmTypes.EvidenceParams{
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/MinterTeam/minter-go-node/api"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/spf13/cobra"
+)
+
+var CheckInvariants = &cobra.Command{
+	Use:     "check-invariants",
+	Short:   "Check state of a stopped node against invariants",
+	PreRunE: openState,
+	PostRun: closeState,
+	RunE:    checkInvariants,
+}
+
+func init() {
+	CheckInvariants.Flags().IntVar(&stateHeight, "height", 0, "height of the state (default is the latest one)")
+}
+
+func checkInvariants(cmd *cobra.Command, args []string) error {
+	cState, err := api.GetStateForHeight(stateHeight)
+	if err != nil {
+		return err
+	}
+
+	genesisState, err := state.LoadGenesisAppState(cfg.GenesisFile())
+	if err != nil {
+		return err
+	}
+
+	report := cState.CheckInvariants(genesisState)
+	if err := printJSON(report, nil); err != nil {
+		return err
+	}
+
+	if !report.IsValid() {
+		return errors.New("invariants are violated")
+	}
+
+	return nil
+}
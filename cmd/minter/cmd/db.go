@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/cmd/utils"
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/eventsdb"
+	"github.com/spf13/cobra"
+	bc "github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/libs/db"
+	tmNode "github.com/tendermint/tendermint/node"
+	sm "github.com/tendermint/tendermint/state"
+)
+
+var Db = &cobra.Command{
+	Use:   "db",
+	Short: "Maintain node databases",
+}
+
+var DbVerify = &cobra.Command{
+	Use:   "verify",
+	Short: "Cross-check state, application, events and Tendermint databases of a stopped node",
+	RunE:  verifyDB,
+}
+
+func init() {
+	DbVerify.Flags().Bool("repair", false, "roll stores which are ahead back to the consistent height")
+
+	Db.AddCommand(DbVerify)
+}
+
+func verifyDB(cmd *cobra.Command, args []string) error {
+	repair, err := cmd.Flags().GetBool("repair")
+	if err != nil {
+		return err
+	}
+
+	applicationDB := appdb.NewAppDB(cfg)
+	defer applicationDB.Close()
+
+	stateDB := db.NewDB("state", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data")
+	defer stateDB.Close()
+
+	tmConfig := config.GetTmConfig(cfg)
+	tmStateDB, err := tmNode.DefaultDBProvider(&tmNode.DBContext{ID: "state", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer tmStateDB.Close()
+
+	blockStoreDB, err := tmNode.DefaultDBProvider(&tmNode.DBContext{ID: "blockstore", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer blockStoreDB.Close()
+
+	report, err := checkDatabases(stateDB, applicationDB, tmStateDB, blockStoreDB)
+	if err != nil {
+		return err
+	}
+
+	if len(report.issues) == 0 {
+		fmt.Println("Databases are consistent")
+		return nil
+	}
+
+	printIssues(report.issues)
+
+	if !repair {
+		return errors.New("databases are inconsistent")
+	}
+
+	if report.consistentHeight == 0 {
+		return errors.New("cannot repair databases without a consistent height")
+	}
+
+	// validators are not known to Tendermint yet if the application is one block ahead of it
+	validators, err := sm.LoadValidators(tmStateDB, int64(report.consistentHeight)+2)
+	if err != nil {
+		validators = nil
+	}
+
+	if err := rollbackApplication(stateDB, applicationDB, report.consistentHeight, report.consistentHash,
+		validators); err != nil {
+		return err
+	}
+
+	fmt.Printf("Application stores are moved to height %d, app hash %X\n", report.consistentHeight,
+		report.consistentHash)
+
+	// rollback does not fix everything, e.g. state which diverged from Tendermint
+	fmt.Println("Verifying repaired databases")
+	repaired, err := checkDatabases(stateDB, applicationDB, tmStateDB, blockStoreDB)
+	if err != nil {
+		return err
+	}
+
+	if len(repaired.issues) != 0 {
+		printIssues(repaired.issues)
+		return fmt.Errorf("databases are still inconsistent after rollback to height %d", report.consistentHeight)
+	}
+
+	fmt.Println("Databases are consistent")
+	return nil
+}
+
+// dbReport describes consistency of node databases
+type dbReport struct {
+	issues []string
+
+	// consistentHeight is the height all application stores can agree on
+	consistentHeight uint64
+	consistentHash   []byte
+}
+
+func checkDatabases(stateDB db.DB, applicationDB *appdb.AppDB, tmStateDB db.DB, blockStoreDB db.DB) (*dbReport,
+	error) {
+	appHeight := applicationDB.GetLastHeight()
+	stateVersion, err := state.NewMutableTree(stateDB).LoadVersion(0)
+	if err != nil {
+		return nil, err
+	}
+
+	blockStore := bc.NewBlockStore(blockStoreDB)
+	tmState := sm.LoadState(tmStateDB)
+
+	var eventsHeight uint64
+	if !cfg.ValidatorMode {
+		eventsDB := db.NewDB("events", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data")
+		eventsHeight = eventsdb.NewEventsDB(eventsDB).LastHeight()
+		eventsDB.Close()
+	}
+
+	fmt.Printf("State version:       %d\n", stateVersion)
+	fmt.Printf("App db height:       %d\n", appHeight)
+	fmt.Printf("Events height:       %d\n", eventsHeight)
+	fmt.Printf("Block store height:  %d\n", blockStore.Height())
+	fmt.Printf("Tendermint height:   %d\n", tmState.LastBlockHeight)
+
+	// the height all application stores can agree on
+	consistentHeight := appHeight
+	if uint64(stateVersion) < consistentHeight {
+		consistentHeight = uint64(stateVersion)
+	}
+	if uint64(blockStore.Height()) < consistentHeight {
+		consistentHeight = uint64(blockStore.Height())
+	}
+	if !tmState.IsEmpty() && uint64(tmState.LastBlockHeight+1) < consistentHeight {
+		consistentHeight = uint64(tmState.LastBlockHeight + 1)
+	}
+
+	var issues []string
+
+	// non-archive nodes keep only the latest state version, so if the node stopped after the state was saved but
+	// before app db was, the state at app db height is pruned. Then app db can only be rolled forward to the state
+	// version, which is possible if Tendermint agrees with the state.
+	var consistentTree *state.ImmutableTree
+	if consistentHeight > 0 {
+		stateTree := state.NewMutableTree(stateDB)
+		consistentTree, err = stateTree.GetImmutableAtHeight(int64(consistentHeight))
+		if err != nil && uint64(stateVersion) > consistentHeight {
+			latestTree, latestErr := stateTree.GetImmutableAtHeight(stateVersion)
+			tmAppHash := tmAppHashAt(blockStore, tmState, uint64(stateVersion))
+			if latestErr == nil && tmAppHash != nil && bytes.Equal(tmAppHash, latestTree.Hash()) {
+				issues = append(issues, fmt.Sprintf("state at height %d is pruned, app db should be rolled "+
+					"forward to state version %d", consistentHeight, stateVersion))
+				consistentHeight = uint64(stateVersion)
+				consistentTree, err = latestTree, nil
+			}
+		}
+
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("state at consistent height %d is not available: %s, the node "+
+				"has to be synced from scratch", consistentHeight, err))
+			return &dbReport{issues: issues}, nil
+		}
+	}
+
+	if uint64(stateVersion) != appHeight {
+		issues = append(issues, fmt.Sprintf("state version %d does not match app db height %d", stateVersion,
+			appHeight))
+	}
+
+	if appHeight > consistentHeight {
+		issues = append(issues, fmt.Sprintf("app db height %d is ahead of Tendermint", appHeight))
+	}
+
+	if eventsHeight > consistentHeight {
+		issues = append(issues, fmt.Sprintf("events are saved up to height %d, which is ahead of the state",
+			eventsHeight))
+	}
+
+	var consistentHash []byte
+	if consistentHeight > 0 {
+		consistentHash = consistentTree.Hash()
+
+		if appHeight == consistentHeight && !bytes.Equal(applicationDB.GetLastBlockHash(), consistentHash) {
+			issues = append(issues, fmt.Sprintf("app db hash %X does not match state hash %X",
+				applicationDB.GetLastBlockHash(), consistentHash))
+		}
+
+		tmAppHash := tmAppHashAt(blockStore, tmState, consistentHeight)
+		if tmAppHash != nil && !bytes.Equal(tmAppHash, consistentHash) {
+			issues = append(issues, fmt.Sprintf("state hash %X at height %d does not match app hash %X known "+
+				"to Tendermint, use `minter rollback` to revert to an earlier height", consistentHash,
+				consistentHeight, tmAppHash))
+		}
+
+		cState, err := state.NewForCheck(consistentHeight, stateDB)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, frozenFunds := range cState.GetAllStateFrozenFunds() {
+			if frozenFunds.BlockHeight() <= consistentHeight {
+				issues = append(issues, fmt.Sprintf("frozen funds of block %d were not unbonded",
+					frozenFunds.BlockHeight()))
+			}
+		}
+	}
+
+	return &dbReport{
+		issues:           issues,
+		consistentHeight: consistentHeight,
+		consistentHash:   consistentHash,
+	}, nil
+}
+
+// tmAppHashAt returns app hash after block of given height known to Tendermint, or nil if it's unknown
+func tmAppHashAt(blockStore *bc.BlockStore, tmState sm.State, height uint64) []byte {
+	if meta := blockStore.LoadBlockMeta(int64(height) + 1); meta != nil {
+		return meta.Header.AppHash
+	}
+
+	if tmState.LastBlockHeight == int64(height) {
+		return tmState.AppHash
+	}
+
+	return nil
+}
+
+func printIssues(issues []string) {
+	for _, issue := range issues {
+		fmt.Println("ERROR:", issue)
+	}
+}
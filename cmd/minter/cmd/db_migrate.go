@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/cmd/utils"
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/spf13/cobra"
+	bc "github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/libs/db"
+	"os"
+	"path/filepath"
+)
+
+const (
+	migrateBatchSize = 10000
+
+	// migrateBlockSamples is a number of blocks read from migrated block store to verify it
+	migrateBlockSamples = 100
+)
+
+var DbMigrate = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy databases of a stopped node to another backend, e.g. from cleveldb to goleveldb",
+	RunE:  migrateDB,
+}
+
+func init() {
+	DbMigrate.Flags().String("from", "cleveldb", "backend databases are stored with")
+	DbMigrate.Flags().String("to", "goleveldb", "backend to copy databases to")
+
+	Db.AddCommand(DbMigrate)
+}
+
+// dbLocation describes a database which is migrated. New database is written to a temporary directory next to
+// the original one and moved in place when all databases are copied and verified.
+type dbLocation struct {
+	name string
+	dir  string
+}
+
+func (l dbLocation) path() string {
+	return filepath.Join(l.dir, l.name+".db")
+}
+
+func (l dbLocation) tmpDir(backend string) string {
+	return filepath.Join(l.dir, "migrate-"+backend)
+}
+
+func (l dbLocation) exists() bool {
+	_, err := os.Stat(l.path())
+	return err == nil
+}
+
+func migrateDB(cmd *cobra.Command, args []string) error {
+	from, err := cmd.Flags().GetString("from")
+	if err != nil {
+		return err
+	}
+
+	to, err := cmd.Flags().GetString("to")
+	if err != nil {
+		return err
+	}
+
+	if from == to {
+		return errors.New("source and target backends should differ")
+	}
+
+	dataDir := utils.GetMinterHome() + "/data"
+	tmDataDir := config.GetTmConfig(cfg).DBDir()
+
+	minterState := dbLocation{name: "state", dir: dataDir}
+	app := dbLocation{name: "app", dir: dataDir}
+	locations := []dbLocation{
+		minterState,
+		app,
+		{name: "events", dir: dataDir},
+		{name: "blockstore", dir: tmDataDir},
+		{name: "state", dir: tmDataDir},
+		{name: "tx_index", dir: tmDataDir},
+		{name: "evidence", dir: tmDataDir},
+	}
+
+	var migrated []dbLocation
+	for _, location := range locations {
+		if !location.exists() {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(location.tmpDir(to), location.name+".db")); err != nil {
+			return err
+		}
+
+		src := db.NewDB(location.name, db.DBBackendType(from), location.dir)
+		dst := db.NewDB(location.name, db.DBBackendType(to), location.tmpDir(to))
+
+		count := copyDB(location.path(), src, dst)
+		fmt.Printf("\r%s: %d keys copied\n", location.path(), count)
+
+		src.Close()
+		dst.Close()
+
+		migrated = append(migrated, location)
+	}
+
+	if !minterState.exists() || !app.exists() {
+		return errors.New("state and application databases are not found")
+	}
+
+	if err := verifyMigratedState(from, to, minterState, app); err != nil {
+		return err
+	}
+
+	if blockStore := (dbLocation{name: "blockstore", dir: tmDataDir}); blockStore.exists() {
+		if err := verifyMigratedBlockStore(from, to, blockStore); err != nil {
+			return err
+		}
+	}
+
+	for _, location := range migrated {
+		backup := location.path() + "." + from
+		if err := os.Rename(location.path(), backup); err != nil {
+			return err
+		}
+
+		if err := os.Rename(filepath.Join(location.tmpDir(to), location.name+".db"), location.path()); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s is migrated, original database is kept at %s\n", location.path(), backup)
+	}
+
+	for _, location := range migrated {
+		if err := os.RemoveAll(location.tmpDir(to)); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Databases are migrated, set db_backend = \"%s\" in %s\n", to, utils.GetMinterConfigPath())
+	return nil
+}
+
+// copyDB copies all keys from src to dst in batches and returns number of copied keys
+func copyDB(name string, src db.DB, dst db.DB) uint64 {
+	it := src.Iterator(nil, nil)
+	defer it.Close()
+
+	var count uint64
+	batch := dst.NewBatch()
+	for ; it.Valid(); it.Next() {
+		batch.Set(it.Key(), it.Value())
+		count++
+
+		if count%migrateBatchSize == 0 {
+			batch.Write()
+			batch = dst.NewBatch()
+			fmt.Printf("\r%s: %d keys copied", name, count)
+		}
+	}
+	batch.WriteSync()
+
+	return count
+}
+
+// verifyMigratedState loads the migrated state at the last height, recomputes hashes of all its nodes and compares
+// its content with the original state
+func verifyMigratedState(from, to string, minterState dbLocation, app dbLocation) error {
+	applicationDB := appdb.NewAppDBWithDB(db.NewDB(app.name, db.DBBackendType(to), app.tmpDir(to)))
+	defer applicationDB.Close()
+
+	height := applicationDB.GetLastHeight()
+	if height == 0 {
+		fmt.Println("Application has no blocks yet, state verification is skipped")
+		return nil
+	}
+
+	srcDB := db.NewDB(minterState.name, db.DBBackendType(from), minterState.dir)
+	defer srcDB.Close()
+
+	dstDB := db.NewDB(minterState.name, db.DBBackendType(to), minterState.tmpDir(to))
+	defer dstDB.Close()
+
+	srcTree, err := state.NewMutableTree(srcDB).GetImmutableAtHeight(int64(height))
+	if err != nil {
+		return fmt.Errorf("cannot load original state at height %d: %s", height, err)
+	}
+
+	dstTree, err := state.NewMutableTree(dstDB).GetImmutableAtHeight(int64(height))
+	if err != nil {
+		return fmt.Errorf("cannot load migrated state at height %d: %s", height, err)
+	}
+
+	if !bytes.Equal(srcTree.Hash(), dstTree.Hash()) {
+		return fmt.Errorf("migrated state hash %X does not match original hash %X at height %d", dstTree.Hash(),
+			srcTree.Hash(), height)
+	}
+
+	if !bytes.Equal(applicationDB.GetLastBlockHash(), dstTree.Hash()) {
+		return fmt.Errorf("migrated state hash %X does not match app hash %X at height %d", dstTree.Hash(),
+			applicationDB.GetLastBlockHash(), height)
+	}
+
+	// root hash is read from the root record only, so every node is rehashed to make sure it's readable and intact
+	leaves, err := state.VerifyTree(dstDB, int64(height))
+	if err != nil {
+		return fmt.Errorf("migrated state at height %d is corrupted: %s", height, err)
+	}
+
+	// iterate the loaded tree and compare it with the original one key by key
+	var count uint64
+	var mismatch []byte
+	dstTree.Iterate(func(key []byte, value []byte) bool {
+		count++
+		if _, srcValue := srcTree.Get(key); !bytes.Equal(srcValue, value) {
+			mismatch = key
+			return true
+		}
+
+		return false
+	})
+
+	if mismatch != nil {
+		return fmt.Errorf("value of key %X of migrated state does not match original value at height %d", mismatch,
+			height)
+	}
+
+	if count != leaves {
+		return fmt.Errorf("migrated state at height %d has %d keys, but %d leaves", height, count, leaves)
+	}
+
+	fmt.Printf("State at height %d is verified, %d keys, app hash %X\n", height, count, dstTree.Hash())
+	return nil
+}
+
+// verifyMigratedBlockStore reads sample blocks from the migrated block store and checks them against the original
+func verifyMigratedBlockStore(from, to string, location dbLocation) (err error) {
+	srcDB := db.NewDB(location.name, db.DBBackendType(from), location.dir)
+	defer srcDB.Close()
+
+	dstDB := db.NewDB(location.name, db.DBBackendType(to), location.tmpDir(to))
+	defer dstDB.Close()
+
+	src, dst := bc.NewBlockStore(srcDB), bc.NewBlockStore(dstDB)
+	if src.Height() != dst.Height() {
+		return fmt.Errorf("migrated block store height %d does not match original height %d", dst.Height(),
+			src.Height())
+	}
+
+	// block store panics on corrupted data
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("migrated block store is corrupted: %v", r)
+		}
+	}()
+
+	heights := sampleHeights(dst.Height(), migrateBlockSamples)
+	for _, height := range heights {
+		srcMeta := src.LoadBlockMeta(height)
+		if srcMeta == nil {
+			// blocks below the base of a restored from snapshot node are not stored
+			continue
+		}
+
+		dstMeta := dst.LoadBlockMeta(height)
+		if dstMeta == nil || !dstMeta.BlockID.Equals(srcMeta.BlockID) {
+			return fmt.Errorf("block meta at height %d of migrated block store does not match original", height)
+		}
+
+		block := dst.LoadBlock(height)
+		if block == nil || !bytes.Equal(block.Hash(), srcMeta.BlockID.Hash) {
+			return fmt.Errorf("block at height %d of migrated block store does not match original", height)
+		}
+	}
+
+	fmt.Printf("Block store is verified, %d of %d blocks checked\n", len(heights), dst.Height())
+	return nil
+}
+
+// sampleHeights returns up to count heights evenly spread from 1 to height, the last height is always included
+func sampleHeights(height int64, count int64) []int64 {
+	if height <= 0 {
+		return nil
+	}
+
+	step := (height + count - 1) / count
+
+	var heights []int64
+	for h := height; h > 0; h -= step {
+		heights = append(heights, h)
+	}
+
+	return heights
+}
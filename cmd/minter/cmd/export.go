@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/cmd/utils"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/libs/db"
+)
+
+var Export = &cobra.Command{
+	Use:   "export",
+	Short: "Export state of a stopped node as a genesis of a new chain",
+	RunE:  export,
+}
+
+func init() {
+	Export.Flags().Uint64("height", 0, "height to export state at (default is the latest one)")
+	Export.Flags().String("output", "genesis.json", "path of the resulting genesis file")
+	addGenesisDocFlags(Export, "")
+}
+
+func export(cmd *cobra.Command, args []string) error {
+	height, err := cmd.Flags().GetUint64("height")
+	if err != nil {
+		return err
+	}
+
+	applicationDB := appdb.NewAppDB(cfg)
+	lastHeight := applicationDB.GetLastHeight()
+	applicationDB.Close()
+
+	if height == 0 {
+		height = lastHeight
+	}
+
+	if height == 0 || height > lastHeight {
+		return fmt.Errorf("state at height %d is not available, last height is %d", height, lastHeight)
+	}
+
+	stateDB := db.NewDB("state", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data")
+	defer stateDB.Close()
+
+	currentState, err := state.New(height, stateDB, false)
+	if err != nil {
+		return fmt.Errorf("state at height %d is not available: %s", height, err)
+	}
+
+	appState := currentState.Export(height)
+
+	report, err := state.CheckAppStateInvariants(appState)
+	if err != nil {
+		return err
+	}
+
+	if !report.IsValid() {
+		for _, violation := range report.Violations {
+			fmt.Println("ERROR:", violation)
+		}
+
+		return errors.New("exported state violates invariants")
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if err := saveGenesisDoc(cmd, appState, output); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported state at height %d to %s\n", height, output)
+	printAppStateSummary(appState)
+	return nil
+}
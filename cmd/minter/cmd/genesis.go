@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/go-amino"
+	"github.com/MinterTeam/minter-go-node/core/genesis"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/spf13/cobra"
+	tmTypes "github.com/tendermint/tendermint/types"
+	"math/big"
+	"time"
+)
+
+var Genesis = &cobra.Command{
+	Use:   "genesis",
+	Short: "Genesis utilities",
+}
+
+var GenesisBuild = &cobra.Command{
+	Use:   "build [input files]",
+	Short: "Build genesis from YAML, JSON and CSV files with accounts, coins, candidates and frozen funds",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  buildGenesis,
+}
+
+func init() {
+	GenesisBuild.Flags().String("output", "genesis.json", "path of the resulting genesis file")
+	addGenesisDocFlags(GenesisBuild, "")
+
+	Genesis.AddCommand(GenesisBuild)
+}
+
+func buildGenesis(cmd *cobra.Command, args []string) error {
+	var input genesis.Input
+	for _, file := range args {
+		fileInput, err := genesis.LoadInput(file)
+		if err != nil {
+			return fmt.Errorf("%s: %s", file, err)
+		}
+
+		input.Merge(fileInput)
+	}
+
+	appState, err := genesis.Build(input)
+	if err != nil {
+		return err
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if err := saveGenesisDoc(cmd, appState, output); err != nil {
+		return err
+	}
+
+	fmt.Printf("Genesis is saved to %s\n", output)
+	printAppStateSummary(appState)
+
+	powers := genesis.GetValidatorsPower(appState.Validators)
+	for i, validator := range appState.Validators {
+		fmt.Printf("Validator %s, stake %s pip, power %d\n", validator.PubKey, validator.TotalBipStake, powers[i])
+	}
+
+	return nil
+}
+
+// addGenesisDocFlags adds flags of genesis document which are shared by commands producing a genesis
+func addGenesisDocFlags(cmd *cobra.Command, chainID string) {
+	cmd.Flags().String("chain-id", chainID, "chain ID of the new chain")
+	cmd.Flags().String("genesis-time", "", "genesis time of the new chain in RFC3339 format (default is now)")
+	cmd.Flags().Int64("max-bytes", 10000000, "consensus param: maximum block size in bytes")
+	cmd.Flags().Int64("max-gas", int64(types.DefaultChainParams().MaxMaxGas), "consensus param: maximum gas per block")
+	cmd.Flags().Int64("time-iota-ms", 1000, "consensus param: minimum time increment between blocks in ms")
+	cmd.Flags().Int64("evidence-max-age", 1000, "consensus param: maximum age of evidence in blocks")
+}
+
+// saveGenesisDoc composes genesis document with given app state and flags of the command and saves it to path
+func saveGenesisDoc(cmd *cobra.Command, appState types.AppState, path string) error {
+	chainID, _ := cmd.Flags().GetString("chain-id")
+	if chainID == "" {
+		return errors.New("chain ID should be specified")
+	}
+
+	genesisTime := time.Now().UTC()
+	if t, _ := cmd.Flags().GetString("genesis-time"); t != "" {
+		var err error
+		genesisTime, err = time.Parse(time.RFC3339, t)
+		if err != nil {
+			return fmt.Errorf("invalid genesis time: %s", err)
+		}
+	}
+
+	maxBytes, _ := cmd.Flags().GetInt64("max-bytes")
+	maxGas, _ := cmd.Flags().GetInt64("max-gas")
+	timeIotaMs, _ := cmd.Flags().GetInt64("time-iota-ms")
+	evidenceMaxAge, _ := cmd.Flags().GetInt64("evidence-max-age")
+
+	jsonBytes, err := amino.NewCodec().MarshalJSONIndent(appState, "", "	")
+	if err != nil {
+		return err
+	}
+
+	appHash := [32]byte{}
+
+	genesisDoc := tmTypes.GenesisDoc{
+		GenesisTime: genesisTime,
+		ChainID:     chainID,
+		ConsensusParams: &tmTypes.ConsensusParams{
+			Block: tmTypes.BlockParams{
+				MaxBytes:   maxBytes,
+				MaxGas:     maxGas,
+				TimeIotaMs: timeIotaMs,
+			},
+			Evidence: tmTypes.EvidenceParams{
+				MaxAge: evidenceMaxAge,
+			},
+			Validator: tmTypes.ValidatorParams{
+				PubKeyTypes: []string{tmTypes.ABCIPubKeyTypeEd25519},
+			},
+		},
+		AppHash:  appHash[:],
+		AppState: json.RawMessage(jsonBytes),
+	}
+
+	if err := genesisDoc.ValidateAndComplete(); err != nil {
+		return err
+	}
+
+	return genesisDoc.SaveAs(path)
+}
+
+func printAppStateSummary(appState types.AppState) {
+	totalStake := big.NewInt(0)
+	for _, candidate := range appState.Candidates {
+		totalStake.Add(totalStake, candidate.TotalBipStake)
+	}
+
+	totalSupply := big.NewInt(0)
+	for _, account := range appState.Accounts {
+		for _, balance := range account.Balance {
+			if balance.Coin.IsBaseCoin() {
+				totalSupply.Add(totalSupply, balance.Value)
+			}
+		}
+	}
+	for _, candidate := range appState.Candidates {
+		for _, stake := range candidate.Stakes {
+			if stake.Coin.IsBaseCoin() {
+				totalSupply.Add(totalSupply, stake.Value)
+			}
+		}
+	}
+	for _, coin := range appState.Coins {
+		totalSupply.Add(totalSupply, coin.ReserveBalance)
+	}
+	for _, frozenFund := range appState.FrozenFunds {
+		if frozenFund.Coin.IsBaseCoin() {
+			totalSupply.Add(totalSupply, frozenFund.Value)
+		}
+	}
+	for _, validator := range appState.Validators {
+		totalSupply.Add(totalSupply, validator.AccumReward)
+	}
+
+	fmt.Printf("Accounts:      %d\n", len(appState.Accounts))
+	fmt.Printf("Coins:         %d\n", len(appState.Coins))
+	fmt.Printf("Candidates:    %d\n", len(appState.Candidates))
+	fmt.Printf("Validators:    %d\n", len(appState.Validators))
+	fmt.Printf("Total stake:   %s pip\n", totalStake)
+	fmt.Printf("Total supply:  %s pip\n", totalSupply)
+}
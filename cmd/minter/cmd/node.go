@@ -6,15 +6,14 @@ import (
 	"github.com/MinterTeam/minter-go-node/cmd/utils"
 	"github.com/MinterTeam/minter-go-node/config"
 	"github.com/MinterTeam/minter-go-node/core/minter"
-	"github.com/MinterTeam/minter-go-node/eventsdb"
 	"github.com/MinterTeam/minter-go-node/gui"
 	"github.com/MinterTeam/minter-go-node/log"
 	"github.com/gobuffalo/packr"
 	"github.com/spf13/cobra"
-	"github.com/tendermint/tendermint/abci/types"
 	bc "github.com/tendermint/tendermint/blockchain"
 	tmCfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/db"
 	tmNode "github.com/tendermint/tendermint/node"
 	"github.com/tendermint/tendermint/p2p"
 	"github.com/tendermint/tendermint/privval"
@@ -28,10 +27,23 @@ var RunNode = &cobra.Command{
 	Use:   "node",
 	Short: "Run the Minter node",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if debug, _ := cmd.Flags().GetBool("debug-check-divergence"); debug {
+			cfg.DebugCheckDivergence = true
+		}
+
+		if haltHeight, _ := cmd.Flags().GetUint64("halt-height"); haltHeight != 0 {
+			cfg.HaltHeight = haltHeight
+		}
+
 		return runNode()
 	},
 }
 
+func init() {
+	RunNode.Flags().Bool("debug-check-divergence", false, "replay delivered txs on check states and log divergences")
+	RunNode.Flags().Uint64("halt-height", 0, "stop the node after committing a block at this height")
+}
+
 func runNode() error {
 	now := time.Now()
 	startTime := time.Date(2019, time.June, 5, 17, 0, 0, 0, time.UTC)
@@ -50,11 +62,19 @@ func runNode() error {
 		return err
 	}
 
-	// init events db
-	eventsdb.InitDB(cfg)
-
 	app := minter.NewMinterBlockchain(cfg)
 
+	if cfg.HaltHeight != 0 && app.Height() >= cfg.HaltHeight {
+		app.Stop()
+		return fmt.Errorf("node is halted at height %d, remove halt_height option to continue", app.Height())
+	}
+
+	if upgrade := app.UnsupportedUpgrade(); upgrade != nil {
+		app.Stop()
+		return fmt.Errorf("node is halted at height %d for upgrade %s approved by proposal %d, install a version "+
+			"which supports the upgrade to continue", upgrade.Height, upgrade.Param, upgrade.ID)
+	}
+
 	// update BlocksTimeDelta in case it was corrupted
 	updateBlocksTimeDelta(app, tmConfig)
 
@@ -74,9 +94,6 @@ func runNode() error {
 		go gui.Run(cfg.GUIListenAddress)
 	}
 
-	// Recheck mempool. Currently kind a hack.
-	go recheckMempool(node, cfg)
-
 	common.TrapSignal(log.With("module", "trap"), func() {
 		// Cleanup
 		err := node.Stop()
@@ -86,24 +103,16 @@ func runNode() error {
 		}
 	})
 
-	// Run forever
-	select {}
-}
+	// Run until the node is halted
+	<-app.Halted()
 
-func recheckMempool(node *tmNode.Node, config *config.Config) {
-	ticker := time.NewTicker(time.Minute)
-	mempool := node.MempoolReactor().Mempool
-	for {
-		select {
-		case <-ticker.C:
-			txs := mempool.ReapMaxTxs(config.Mempool.Size)
-			mempool.Flush()
-
-			for _, tx := range txs {
-				_ = mempool.CheckTx(tx, func(res *types.Response) {})
-			}
-		}
+	log.Info("Node is halted, stopping")
+	if err := node.Stop(); err != nil {
+		return err
 	}
+	app.Stop()
+
+	return nil
 }
 
 func updateBlocksTimeDelta(app *minter.Blockchain, config *tmCfg.Config) {
@@ -114,7 +123,7 @@ func updateBlocksTimeDelta(app *minter.Blockchain, config *tmCfg.Config) {
 
 	blockStore := bc.NewBlockStore(blockStoreDB)
 	height := uint64(blockStore.Height())
-	count := uint64(3)
+	count := app.ChainParams().MaxGasWindow
 	if _, err := app.GetBlocksTimeDelta(height, count); height >= 20 && err != nil {
 		blockA := blockStore.LoadBlockMeta(int64(height - count - 1))
 		blockB := blockStore.LoadBlockMeta(int64(height - 1))
@@ -125,19 +134,29 @@ func updateBlocksTimeDelta(app *minter.Blockchain, config *tmCfg.Config) {
 	blockStoreDB.Close()
 }
 
-func startTendermintNode(app types.Application, cfg *tmCfg.Config) *tmNode.Node {
+func startTendermintNode(app *minter.Blockchain, cfg *tmCfg.Config) *tmNode.Node {
 	nodeKey, err := p2p.LoadOrGenNodeKey(cfg.NodeKeyFile())
 	if err != nil {
 		panic(err)
 	}
 
+	// Tendermint's state db is passed to the app to take snapshots
+	dbProvider := func(ctx *tmNode.DBContext) (db.DB, error) {
+		database, err := tmNode.DefaultDBProvider(ctx)
+		if err == nil && ctx.ID == "state" {
+			app.SetTmStateDB(database)
+		}
+
+		return database, err
+	}
+
 	node, err := tmNode.NewNode(
 		cfg,
 		privval.LoadOrGenFilePV(cfg.PrivValidatorKeyFile(), cfg.PrivValidatorStateFile()),
 		nodeKey,
 		proxy.NewLocalClientCreator(app),
 		getGenesis,
-		tmNode.DefaultDBProvider,
+		dbProvider,
 		tmNode.DefaultMetricsProvider(cfg.Instrumentation),
 		log.With("module", "tendermint"),
 	)
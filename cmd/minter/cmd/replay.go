@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/cmd/utils"
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/minter"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/eventsdb"
+	"github.com/spf13/cobra"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	bc "github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/libs/db"
+	tmNode "github.com/tendermint/tendermint/node"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+	"os"
+	"path/filepath"
+)
+
+var Replay = &cobra.Command{
+	Use:   "replay",
+	Short: "Re-execute blocks from Tendermint block store against a copy of state and compare results",
+	RunE:  replay,
+}
+
+func init() {
+	Replay.Flags().Int64("from", 0, "first height to re-execute")
+	Replay.Flags().Int64("to", 0, "last height to re-execute (default is the last stored block)")
+}
+
+func replay(cmd *cobra.Command, args []string) error {
+	from, _ := cmd.Flags().GetInt64("from")
+	to, _ := cmd.Flags().GetInt64("to")
+
+	if from < 2 {
+		return errors.New("from height should be greater than 1")
+	}
+
+	tmConfig := config.GetTmConfig(cfg)
+	tmStateDB, err := tmNode.DefaultDBProvider(&tmNode.DBContext{ID: "state", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer tmStateDB.Close()
+
+	blockStoreDB, err := tmNode.DefaultDBProvider(&tmNode.DBContext{ID: "blockstore", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer blockStoreDB.Close()
+
+	blockStore := bc.NewBlockStore(blockStoreDB)
+	if to == 0 {
+		to = blockStore.Height()
+	}
+
+	if to < from || to > blockStore.Height() {
+		return fmt.Errorf("blocks %d-%d are not available, block store height is %d", from, to, blockStore.Height())
+	}
+
+	stateDB := db.NewDB("state", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data")
+	defer stateDB.Close()
+
+	tree, err := state.NewMutableTree(stateDB).GetImmutableAtHeight(from - 1)
+	if err != nil {
+		return fmt.Errorf("state at height %d is not available, replay is possible only from heights kept with "+
+			"keep_state_history", from-1)
+	}
+
+	sourceAppDB := appdb.NewAppDB(cfg)
+	startHeight := sourceAppDB.GetStartHeight()
+	sourceAppDB.Close()
+
+	// blocks are executed against a copy, so the original state is kept untouched
+	replayDir := filepath.Join(utils.GetMinterHome(), "data", "replay")
+	if err := os.RemoveAll(replayDir); err != nil {
+		return err
+	}
+	defer os.RemoveAll(replayDir)
+
+	stateCopy := db.NewDB("state", db.DBBackendType(cfg.DBBackend), replayDir)
+	defer stateCopy.Close()
+
+	copyDB("state", stateDB, stateCopy)
+	fmt.Println()
+
+	if err := state.RollbackTree(stateCopy, from-1); err != nil {
+		return err
+	}
+
+	validators, err := sm.LoadValidators(tmStateDB, from+1)
+	if err != nil {
+		return err
+	}
+
+	applicationDB := appdb.NewAppDBWithDB(db.NewMemDB())
+	applicationDB.SetStartHeight(startHeight)
+	applicationDB.SetLastHeight(uint64(from - 1))
+	applicationDB.SetLastBlockHash(tree.Hash())
+	applicationDB.SaveValidators(types.TM2PB.ValidatorUpdates(validators))
+
+	// blocks are replayed regardless of halt_height option
+	replayCfg := *cfg
+	replayCfg.HaltHeight = 0
+
+	app := minter.NewMinterBlockchainWithDB(&replayCfg, stateCopy, applicationDB, eventsdb.NOOPEventsDB{})
+
+	for height := from; height <= to; height++ {
+		if err := replayBlock(app, tmStateDB, blockStore, height); err != nil {
+			return err
+		}
+
+		select {
+		case <-app.Halted():
+			fmt.Printf("Blocks %d-%d are re-executed without divergence, chain is halted for an upgrade at height "+
+				"%d\n", from, height, height)
+			return nil
+		default:
+		}
+	}
+
+	fmt.Printf("Blocks %d-%d are re-executed without divergence\n", from, to)
+	return nil
+}
+
+// replayBlock feeds block through ABCI methods the same way Tendermint does and compares results with recorded ones
+func replayBlock(app *minter.Blockchain, tmStateDB db.DB, blockStore *bc.BlockStore, height int64) error {
+	block := blockStore.LoadBlock(height)
+	if block == nil {
+		return fmt.Errorf("block %d is not found", height)
+	}
+
+	lastValidators, err := sm.LoadValidators(tmStateDB, height-1)
+	if err != nil {
+		return err
+	}
+
+	var votes []abciTypes.VoteInfo
+	for i, validator := range lastValidators.Validators {
+		votes = append(votes, abciTypes.VoteInfo{
+			Validator:       types.TM2PB.Validator(validator),
+			SignedLastBlock: block.LastCommit != nil && block.LastCommit.Precommits[i] != nil,
+		})
+	}
+
+	var byzantineValidators []abciTypes.Evidence
+	for _, evidence := range block.Evidence.Evidence {
+		validators, err := sm.LoadValidators(tmStateDB, evidence.Height())
+		if err != nil {
+			return err
+		}
+
+		byzantineValidators = append(byzantineValidators, types.TM2PB.Evidence(evidence, validators, block.Time))
+	}
+
+	recorded, err := sm.LoadABCIResponses(tmStateDB, height)
+	if err != nil {
+		return err
+	}
+
+	// max gas depends on time of previous blocks, which is known to Tendermint only. The window is a chain param
+	// which can be changed by governance, so it's read from the state being replayed.
+	blocksTimeDeltaCount := int64(app.ChainParams().MaxGasWindow)
+	if height-blocksTimeDeltaCount-1 >= 1 {
+		blockA := blockStore.LoadBlockMeta(height - blocksTimeDeltaCount - 1)
+		blockB := blockStore.LoadBlockMeta(height - 1)
+		app.SetBlocksTimeDelta(uint64(height), int(blockB.Header.Time.Sub(blockA.Header.Time).Seconds()))
+	}
+
+	app.BeginBlock(abciTypes.RequestBeginBlock{
+		Hash:                block.Hash(),
+		Header:              types.TM2PB.Header(&block.Header),
+		LastCommitInfo:      abciTypes.LastCommitInfo{Votes: votes},
+		ByzantineValidators: byzantineValidators,
+	})
+
+	var diverged []string
+	for i, tx := range block.Data.Txs {
+		result := app.DeliverTx(tx)
+		fmt.Printf("  tx %d %X: code %d, gas %d\n", i, tx.Hash(), result.Code, result.GasUsed)
+
+		if i >= len(recorded.DeliverTx) {
+			diverged = append(diverged, fmt.Sprintf("tx %d is not recorded", i))
+			continue
+		}
+
+		expected := recorded.DeliverTx[i]
+		if result.Code != expected.Code || result.GasUsed != expected.GasUsed {
+			diverged = append(diverged, fmt.Sprintf("tx %d: code %d, gas %d, recorded code %d, gas %d (%s)", i,
+				result.Code, result.GasUsed, expected.Code, expected.GasUsed, expected.Log))
+		}
+	}
+
+	endBlock := app.EndBlock(abciTypes.RequestEndBlock{Height: height})
+	if recorded.EndBlock != nil && !equalValidatorUpdates(endBlock.ValidatorUpdates, recorded.EndBlock.ValidatorUpdates) {
+		diverged = append(diverged, "validator updates differ from recorded ones")
+	}
+
+	appHash := app.Commit().Data
+
+	// app hash of a block is stored in the header of the next one
+	var expectedHash []byte
+	if meta := blockStore.LoadBlockMeta(height + 1); meta != nil {
+		expectedHash = meta.Header.AppHash
+	} else if tmState := sm.LoadState(tmStateDB); tmState.LastBlockHeight == height {
+		expectedHash = tmState.AppHash
+	}
+
+	if expectedHash != nil && !bytes.Equal(appHash, expectedHash) {
+		diverged = append(diverged, fmt.Sprintf("app hash %X, recorded %X", appHash, expectedHash))
+	}
+
+	fmt.Printf("Height %d: %d txs, app hash %X\n", height, len(block.Data.Txs), appHash)
+
+	if len(diverged) != 0 {
+		for _, reason := range diverged {
+			fmt.Println("DIVERGENCE:", reason)
+		}
+
+		return fmt.Errorf("execution of block %d diverged from recorded results", height)
+	}
+
+	return nil
+}
+
+func equalValidatorUpdates(a, b []abciTypes.ValidatorUpdate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/cmd/utils"
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/tmdb"
+	"github.com/MinterTeam/minter-go-node/eventsdb"
+	"github.com/spf13/cobra"
+	bc "github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/libs/db"
+	tmNode "github.com/tendermint/tendermint/node"
+	"github.com/tendermint/tendermint/types"
+	"os"
+	"path/filepath"
+)
+
+var Rollback = &cobra.Command{
+	Use:   "rollback",
+	Short: "Revert blockchain state to given height, so the node re-executes blocks above it",
+	RunE:  rollback,
+}
+
+func init() {
+	Rollback.Flags().Uint64("height", 0, "height to revert state to")
+}
+
+func rollback(cmd *cobra.Command, args []string) error {
+	height, err := cmd.Flags().GetUint64("height")
+	if err != nil {
+		return err
+	}
+
+	if height == 0 {
+		return errors.New("height should be specified")
+	}
+
+	applicationDB := appdb.NewAppDB(cfg)
+	defer applicationDB.Close()
+
+	lastHeight := applicationDB.GetLastHeight()
+	if height >= lastHeight {
+		return fmt.Errorf("current height is %d, nothing to revert", lastHeight)
+	}
+
+	stateDB := db.NewDB("state", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data")
+	defer stateDB.Close()
+
+	tree, err := state.NewMutableTree(stateDB).GetImmutableAtHeight(int64(height))
+	if err != nil {
+		return fmt.Errorf("state at height %d was pruned, rollback is possible only to heights kept with keep_state_history",
+			height)
+	}
+	appHash := tree.Hash()
+
+	tmConfig := config.GetTmConfig(cfg)
+	tmStateDB, err := tmNode.DefaultDBProvider(&tmNode.DBContext{ID: "state", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer tmStateDB.Close()
+
+	blockStoreDB, err := tmNode.DefaultDBProvider(&tmNode.DBContext{ID: "blockstore", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer blockStoreDB.Close()
+
+	tmState, err := tmdb.StateAtHeight(tmStateDB, bc.NewBlockStore(blockStoreDB), int64(height), appHash)
+	if err != nil {
+		return err
+	}
+
+	if err := rollbackApplication(stateDB, applicationDB, height, appHash, tmState.NextValidators); err != nil {
+		return err
+	}
+
+	tmdb.SaveState(tmStateDB, tmState)
+	tmdb.RollbackBlockStore(blockStoreDB, int64(height))
+
+	// consensus WAL contains messages of reverted heights
+	if err := os.RemoveAll(filepath.Dir(tmConfig.Consensus.WalFile())); err != nil {
+		return err
+	}
+
+	fmt.Printf("Reverted state from height %d to %d, app hash %X\n", lastHeight, height, appHash)
+	return nil
+}
+
+// rollbackApplication reverts state tree, events and application db to given height. Validators are kept
+// untouched if nil is passed.
+func rollbackApplication(stateDB db.DB, applicationDB *appdb.AppDB, height uint64, appHash []byte,
+	validators *types.ValidatorSet) error {
+	if err := state.RollbackTree(stateDB, int64(height)); err != nil {
+		return err
+	}
+
+	if !cfg.ValidatorMode {
+		eventsDB := db.NewDB("events", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data")
+		eventsdb.NewEventsDB(eventsDB).Rollback(height)
+		eventsDB.Close()
+	}
+
+	if validators != nil {
+		applicationDB.SaveValidators(types.TM2PB.ValidatorUpdates(validators))
+	}
+	applicationDB.DeleteMaxGasAbove(height)
+	applicationDB.DeleteBlockRewardsAbove(height)
+	applicationDB.SetLastBlockHash(appHash)
+	applicationDB.SetLastHeight(height)
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/cmd/utils"
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/snapshot"
+	"github.com/spf13/cobra"
+	bc "github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/db"
+	tmNode "github.com/tendermint/tendermint/node"
+)
+
+var Snapshot = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Manage snapshots of the state",
+}
+
+var SnapshotList = &cobra.Command{
+	Use:   "list",
+	Short: "List available snapshots",
+	RunE:  listSnapshots,
+}
+
+var SnapshotRestore = &cobra.Command{
+	Use:   "restore",
+	Short: "Bootstrap a fresh node from a snapshot",
+	RunE:  restoreSnapshot,
+}
+
+func init() {
+	Snapshot.PersistentFlags().String("from", "", "directory with snapshots (default is $(home-dir)/snapshots)")
+	SnapshotRestore.Flags().Uint64("height", 0, "height of a snapshot to restore (default is the latest one)")
+
+	Snapshot.AddCommand(SnapshotList, SnapshotRestore)
+}
+
+func snapshotsDir(cmd *cobra.Command) string {
+	if from, _ := cmd.Flags().GetString("from"); from != "" {
+		return from
+	}
+
+	return cfg.SnapshotDir()
+}
+
+func listSnapshots(cmd *cobra.Command, args []string) error {
+	dir := snapshotsDir(cmd)
+
+	heights, err := snapshot.Heights(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, height := range heights {
+		manifest, err := snapshot.LoadManifest(dir, height)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%d\t%s\t%X\t%d chunks\n", manifest.Height, manifest.CreatedAt.Format("2006-01-02 15:04:05"),
+			manifest.AppHash, len(manifest.Chunks))
+	}
+
+	return nil
+}
+
+func restoreSnapshot(cmd *cobra.Command, args []string) error {
+	height, err := cmd.Flags().GetUint64("height")
+	if err != nil {
+		return err
+	}
+
+	if err := common.EnsureDir(utils.GetMinterHome()+"/tmdata", 0777); err != nil {
+		return err
+	}
+
+	applicationDB := appdb.NewAppDB(cfg)
+	defer applicationDB.Close()
+
+	tmConfig := config.GetTmConfig(cfg)
+	blockStoreDB, err := tmNode.DefaultDBProvider(&tmNode.DBContext{ID: "blockstore", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer blockStoreDB.Close()
+
+	if applicationDB.GetLastHeight() != 0 || bc.LoadBlockStoreStateJSON(blockStoreDB).Height != 0 {
+		return errors.New("home directory already contains blockchain data, restore requires a fresh one")
+	}
+
+	tmStateDB, err := tmNode.DefaultDBProvider(&tmNode.DBContext{ID: "state", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer tmStateDB.Close()
+
+	stateDB := db.NewDB("state", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data")
+	defer stateDB.Close()
+
+	manifest, err := snapshot.Restore(snapshotsDir(cmd), height, snapshot.Target{
+		StateDB:      stateDB,
+		AppDB:        applicationDB,
+		TmStateDB:    tmStateDB,
+		BlockStoreDB: blockStoreDB,
+		GenesisFile:  utils.GetMinterHome() + "/config/genesis.json",
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored snapshot at height %d, app hash %X\n", manifest.Height, manifest.AppHash)
+	return nil
+}
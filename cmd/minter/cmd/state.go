@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/api"
+	"github.com/MinterTeam/minter-go-node/cmd/utils"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/libs/db"
+)
+
+var State = &cobra.Command{
+	Use:               "state",
+	Short:             "Inspect state of a stopped node",
+	PersistentPreRunE: openState,
+	PersistentPostRun: closeState,
+}
+
+var StateAccount = &cobra.Command{
+	Use:   "account [address]",
+	Short: "Print balances and nonce of an address",
+	Args:  cobra.ExactArgs(1),
+	RunE:  stateAccount,
+}
+
+var StateCoin = &cobra.Command{
+	Use:   "coin [symbol]",
+	Short: "Print coin info",
+	Args:  cobra.ExactArgs(1),
+	RunE:  stateCoin,
+}
+
+var StateCandidates = &cobra.Command{
+	Use:   "candidates",
+	Short: "Print all candidates",
+	RunE:  stateCandidates,
+}
+
+var StateFrozen = &cobra.Command{
+	Use:   "frozen",
+	Short: "Print frozen funds",
+	RunE:  stateFrozen,
+}
+
+var StateDump = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the whole state in genesis format",
+	RunE:  stateDump,
+}
+
+var StateDiff = &cobra.Command{
+	Use:   "diff [from height] [to height]",
+	Short: "Print balance, stake and coin changes between two heights",
+	Args:  cobra.ExactArgs(2),
+	RunE:  stateDiff,
+}
+
+var (
+	stateDB     db.DB
+	stateHeight int
+)
+
+func init() {
+	State.PersistentFlags().IntVar(&stateHeight, "height", 0, "height of the state (default is the latest one)")
+	StateCandidates.Flags().Bool("include-stakes", false, "include stakes of candidates")
+	StateFrozen.Flags().String("address", "", "show only funds of given address")
+
+	State.AddCommand(StateAccount, StateCoin, StateCandidates, StateFrozen, StateDump, StateDiff)
+}
+
+func openState(cmd *cobra.Command, args []string) error {
+	applicationDB := appdb.NewAppDB(cfg)
+	lastHeight := applicationDB.GetLastHeight()
+	applicationDB.Close()
+
+	if lastHeight == 0 {
+		return errors.New("state is empty")
+	}
+
+	stateDB = db.NewDB("state", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data")
+	api.UseStateDB(stateDB, lastHeight)
+
+	return nil
+}
+
+func closeState(cmd *cobra.Command, args []string) {
+	stateDB.Close()
+}
+
+func parseAddress(hex string) (types.Address, error) {
+	if !types.IsHexAddress(hex) {
+		return types.Address{}, fmt.Errorf("invalid address %s", hex)
+	}
+
+	return types.HexToAddress(hex), nil
+}
+
+func printJSON(value interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+
+	data, err := api.MarshalJSON(value)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+func stateAccount(cmd *cobra.Command, args []string) error {
+	address, err := parseAddress(args[0])
+	if err != nil {
+		return err
+	}
+
+	return printJSON(api.Address(address, stateHeight))
+}
+
+func stateCoin(cmd *cobra.Command, args []string) error {
+	return printJSON(api.CoinInfo(args[0], stateHeight))
+}
+
+func stateCandidates(cmd *cobra.Command, args []string) error {
+	includeStakes, err := cmd.Flags().GetBool("include-stakes")
+	if err != nil {
+		return err
+	}
+
+	return printJSON(api.Candidates(stateHeight, includeStakes))
+}
+
+func stateFrozen(cmd *cobra.Command, args []string) error {
+	var address *types.Address
+	if hex, _ := cmd.Flags().GetString("address"); hex != "" {
+		addr, err := parseAddress(hex)
+		if err != nil {
+			return err
+		}
+		address = &addr
+	}
+
+	return printJSON(api.FrozenFunds(address, stateHeight))
+}
+
+func stateDump(cmd *cobra.Command, args []string) error {
+	cState, err := api.GetStateForHeight(stateHeight)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(cState.Export(cState.Height()), nil)
+}
+
+func stateDiff(cmd *cobra.Command, args []string) error {
+	var heights [2]int
+	for i, arg := range args {
+		if _, err := fmt.Sscan(arg, &heights[i]); err != nil {
+			return fmt.Errorf("invalid height %s", arg)
+		}
+	}
+
+	return printJSON(api.StateDiff(heights[0], heights[1]))
+}
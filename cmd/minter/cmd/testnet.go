@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/genesis"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/version"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/privval"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	testnetP2PPort = 26656
+	testnetRPCPort = 26657
+	testnetAPIPort = 8841
+	testnetGUIPort = 3000
+
+	// each node gets its own range of ports, so all nodes can run on one host
+	testnetPortStep = 10
+)
+
+var Testnet = &cobra.Command{
+	Use:   "testnet",
+	Short: "Generate home directories, keys and a shared genesis for a local multi-node network",
+	RunE:  testnet,
+}
+
+func init() {
+	Testnet.Flags().Int("validators", 4, "number of validator nodes")
+	Testnet.Flags().String("output", "./net", "directory to write node home directories to")
+	Testnet.Flags().String("balance", "1000000", "BIP balance of each validator owner account")
+	Testnet.Flags().String("stake", "100000", "BIP stake of each validator")
+	Testnet.Flags().String("host", "127.0.0.1", "host nodes are reachable at")
+	Testnet.Flags().String("db-backend", "goleveldb", "database backend of nodes")
+	Testnet.Flags().String("docker-image", "minterteam/minter:"+version.Version, "image used in docker-compose.yml")
+	addGenesisDocFlags(Testnet, "minter-testnet-local")
+}
+
+type testnetAccount struct {
+	Node       string        `json:"node"`
+	Address    types.Address `json:"address"`
+	PrivateKey string        `json:"private_key"`
+}
+
+type testnetNode struct {
+	Name   string
+	Home   string
+	Config *config.Config
+}
+
+func testnet(cmd *cobra.Command, args []string) error {
+	validators, _ := cmd.Flags().GetInt("validators")
+	output, _ := cmd.Flags().GetString("output")
+	balance, _ := cmd.Flags().GetString("balance")
+	stake, _ := cmd.Flags().GetString("stake")
+	host, _ := cmd.Flags().GetString("host")
+	dbBackend, _ := cmd.Flags().GetString("db-backend")
+	dockerImage, _ := cmd.Flags().GetString("docker-image")
+	chainID, _ := cmd.Flags().GetString("chain-id")
+
+	if validators < 1 {
+		return errors.New("at least one validator is required")
+	}
+
+	output, err := filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+
+	if common.FileExists(output) {
+		return fmt.Errorf("%s already exists", output)
+	}
+
+	var nodes []testnetNode
+	var accounts []testnetAccount
+	var peers []string
+	var input genesis.Input
+
+	for i := 0; i < validators; i++ {
+		name := fmt.Sprintf("node%d", i)
+		home := filepath.Join(output, name)
+		offset := i * testnetPortStep
+
+		config.EnsureRoot(home)
+
+		nodeCfg := config.DefaultConfig()
+		nodeCfg.SetRoot(home)
+		nodeCfg.Moniker = name
+		nodeCfg.DBBackend = dbBackend
+		nodeCfg.APIListenAddress = fmt.Sprintf("tcp://0.0.0.0:%d", testnetAPIPort+offset)
+		nodeCfg.GUIListenAddress = fmt.Sprintf(":%d", testnetGUIPort+offset)
+		nodeCfg.RPC.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", testnetRPCPort+offset)
+		nodeCfg.P2P.ListenAddress = fmt.Sprintf("tcp://0.0.0.0:%d", testnetP2PPort+offset)
+		nodeCfg.P2P.Seeds = ""
+		nodeCfg.P2P.AddrBookStrict = false
+		nodeCfg.P2P.AllowDuplicateIP = true
+
+		nodeKey, err := p2p.LoadOrGenNodeKey(nodeCfg.NodeKeyFile())
+		if err != nil {
+			return err
+		}
+		peers = append(peers, p2p.IDAddressString(nodeKey.ID(), fmt.Sprintf("%s:%d", host, testnetP2PPort+offset)))
+
+		pv := privval.GenFilePV(nodeCfg.PrivValidatorKeyFile(), nodeCfg.PrivValidatorStateFile())
+		pv.Save()
+		pubKey := pv.GetPubKey().(ed25519.PubKeyEd25519)
+
+		privateKey, err := crypto.GenerateKey()
+		if err != nil {
+			return err
+		}
+		address := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+		accounts = append(accounts, testnetAccount{
+			Node:       name,
+			Address:    address,
+			PrivateKey: hex.EncodeToString(crypto.FromECDSA(privateKey)),
+		})
+
+		input.Accounts = append(input.Accounts, genesis.Account{
+			Address: address.String(),
+			Balance: []genesis.Balance{{Coin: types.GetBaseCoin().String(), Value: balance}},
+		})
+
+		input.Candidates = append(input.Candidates, genesis.Candidate{
+			PubKey:       types.Pubkey(pubKey[:]).String(),
+			OwnerAddress: address.String(),
+			Commission:   10,
+			Stakes:       []genesis.Stake{{Owner: address.String(), Coin: types.GetBaseCoin().String(), Value: stake}},
+		})
+
+		nodes = append(nodes, testnetNode{Name: name, Home: home, Config: nodeCfg})
+	}
+
+	appState, err := genesis.Build(input)
+	if err != nil {
+		return err
+	}
+
+	for i, node := range nodes {
+		var nodePeers []string
+		for j, peer := range peers {
+			if i != j {
+				nodePeers = append(nodePeers, peer)
+			}
+		}
+		node.Config.P2P.PersistentPeers = strings.Join(nodePeers, ",")
+
+		config.WriteConfigFile(filepath.Join(node.Home, "config", "config.toml"), node.Config)
+
+		if err := saveGenesisDoc(cmd, appState, node.Config.GenesisFile()); err != nil {
+			return err
+		}
+	}
+
+	accountsJSON, err := json.MarshalIndent(accounts, "", "	")
+	if err != nil {
+		return err
+	}
+
+	if err := common.WriteFile(filepath.Join(output, "accounts.json"), accountsJSON, 0600); err != nil {
+		return err
+	}
+
+	var compose bytes.Buffer
+	if err := dockerComposeTemplate.Execute(&compose, map[string]interface{}{
+		"Image":   dockerImage,
+		"ChainID": chainID,
+		"Nodes":   nodes,
+	}); err != nil {
+		return err
+	}
+
+	if err := common.WriteFile(filepath.Join(output, "docker-compose.yml"), compose.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Generated %d nodes of %s network in %s\n", validators, chainID, output)
+	for _, node := range nodes {
+		fmt.Printf("%s: minter node --home-dir %s --network-id %s, API at %s\n", node.Name, node.Home, chainID,
+			node.Config.APIListenAddress)
+	}
+	fmt.Printf("Keys of funded accounts are saved to %s\n", filepath.Join(output, "accounts.json"))
+
+	return nil
+}
+
+// nodes use host network, so ports and persistent peers are the same as in a local run
+var dockerComposeTemplate = template.Must(template.New("docker-compose").Parse(`version: "3.4"
+services:
+{{- range .Nodes }}
+  {{ .Name }}:
+    image: {{ $.Image }}
+    command: node --network-id={{ $.ChainID }}
+    network_mode: host
+    volumes:
+      - ./{{ .Name }}:/minter
+    restart: always
+{{- end }}
+`))
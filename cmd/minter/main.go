@@ -12,7 +12,16 @@ func main() {
 	rootCmd.AddCommand(
 		cmd.RunNode,
 		cmd.ShowNodeId,
-		cmd.ShowValidator)
+		cmd.ShowValidator,
+		cmd.Snapshot,
+		cmd.Rollback,
+		cmd.State,
+		cmd.CheckInvariants,
+		cmd.Db,
+		cmd.Export,
+		cmd.Genesis,
+		cmd.Testnet,
+		cmd.Replay)
 
 	rootCmd.PersistentFlags().StringVar(&utils.MinterHome, "home-dir", "", "base dir (default is $HOME/.minter)")
 	rootCmd.PersistentFlags().StringVar(&utils.MinterConfig, "config", "", "path to config (default is $(home-dir)/config/config.toml)")
@@ -54,7 +54,9 @@ func DefaultConfig() *Config {
 	cfg.DBPath = "tmdata"
 
 	cfg.Mempool.CacheSize = 100000
-	cfg.Mempool.Recheck = false
+	// txs which became invalid or were evicted by txs with higher gas price are dropped by recheck after every block,
+	// gas price priority of full mempool relies on it
+	cfg.Mempool.Recheck = true
 	cfg.Mempool.Size = 10000
 
 	cfg.Consensus.WalPath = "tmdata/cs.wal/wal"
@@ -88,8 +90,6 @@ func GetConfig() *Config {
 		cfg.RPC.GRPCListenAddress = ""
 	}
 
-	cfg.Mempool.Recheck = false
-
 	cfg.P2P.AddrBook = "config/addrbook-" + NetworkId + ".json"
 
 	cfg.SetRoot(utils.GetMinterHome())
@@ -239,6 +239,35 @@ type BaseConfig struct {
 	APISimultaneousRequests int `mapstructure:"api_simultaneous_requests"`
 
 	LogPath string `mapstructure:"log_path"`
+
+	// Take a state snapshot every N blocks, 0 disables snapshots
+	SnapshotInterval uint64 `mapstructure:"snapshot_interval"`
+
+	// Number of recent snapshots to keep on disk
+	SnapshotKeepRecent int `mapstructure:"snapshot_keep_recent"`
+
+	// Directory to store snapshots in
+	SnapshotPath string `mapstructure:"snapshot_dir"`
+
+	// Stop the node if periodical invariants check finds a violation
+	HaltOnInvariantsViolation bool `mapstructure:"halt_on_invariants_violation"`
+
+	// Stop the node after committing a block at this height, 0 disables the halt
+	HaltHeight uint64 `mapstructure:"halt_height"`
+
+	// Replay delivered txs on check states and log divergences in codes, gas and balances. Debugging only, slows
+	// down block processing
+	DebugCheckDivergence bool `mapstructure:"debug_check_divergence"`
+
+	// Minimal gas price of txs accepted to mempool regardless of its size
+	MinGasPrice uint32 `mapstructure:"min_gas_price"`
+
+	// Comma separated "mempool_size:gas_price" pairs, minimal gas price grows to gas_price when mempool holds more
+	// than mempool_size txs
+	MinGasPriceThresholds string `mapstructure:"min_gas_price_thresholds"`
+
+	// Number of recent blocks which reward distribution is kept for, 0 keeps all blocks
+	BlockRewardsKeepRecent uint64 `mapstructure:"block_rewards_keep_recent"`
 }
 
 // DefaultBaseConfig returns a default base configuration for a Tendermint node
@@ -262,6 +291,12 @@ func DefaultBaseConfig() BaseConfig {
 		APISimultaneousRequests: 100,
 		LogPath:                 "stdout",
 		LogFormat:               LogFormatPlain,
+		SnapshotInterval:        0,
+		SnapshotKeepRecent:      2,
+		SnapshotPath:            "snapshots",
+		MinGasPrice:             1,
+		MinGasPriceThresholds:   "100:2,500:5,1000:10,5000:50",
+		BlockRewardsKeepRecent:  518400,
 	}
 }
 
@@ -293,6 +328,11 @@ func (cfg BaseConfig) DBDir() string {
 	return rootify(cfg.DBPath, cfg.RootDir)
 }
 
+// SnapshotDir returns the full path to the snapshots directory
+func (cfg BaseConfig) SnapshotDir() string {
+	return rootify(cfg.SnapshotPath, cfg.RootDir)
+}
+
 // DefaultLogLevel returns a default log level of "error"
 func DefaultLogLevel() string {
 	return "error"
@@ -102,6 +102,34 @@ log_format = "{{ .BaseConfig.LogFormat }}"
 # Path to file for logs, "stdout" by default
 log_path = "{{ .BaseConfig.LogPath }}"
 
+# Take a snapshot of the application state every N blocks, 0 disables snapshots
+snapshot_interval = {{ .BaseConfig.SnapshotInterval }}
+
+# Number of recent snapshots to keep on disk
+snapshot_keep_recent = {{ .BaseConfig.SnapshotKeepRecent }}
+
+# Directory to store snapshots in
+snapshot_dir = "{{ js .BaseConfig.SnapshotPath }}"
+
+# Stop the node if periodical invariants check finds a violation
+halt_on_invariants_violation = {{ .BaseConfig.HaltOnInvariantsViolation }}
+
+# Stop the node after committing a block at this height, 0 disables the halt
+halt_height = {{ .BaseConfig.HaltHeight }}
+
+# Replay delivered txs on check states and log divergences in codes, gas and balances (debugging only)
+debug_check_divergence = {{ .BaseConfig.DebugCheckDivergence }}
+
+# Minimal gas price of txs accepted to mempool regardless of its size
+min_gas_price = {{ .BaseConfig.MinGasPrice }}
+
+# Comma separated "mempool_size:gas_price" pairs, minimal gas price grows to gas_price when mempool holds more
+# than mempool_size txs
+min_gas_price_thresholds = "{{ .BaseConfig.MinGasPriceThresholds }}"
+
+# Number of recent blocks which reward distribution is kept for, 0 keeps all blocks
+block_rewards_keep_recent = {{ .BaseConfig.BlockRewardsKeepRecent }}
+
 ##### additional base config options #####
 
 # Path to the JSON file containing the private key to use as a validator in the consensus protocol
@@ -209,6 +237,10 @@ size = {{ .Mempool.Size }}
 # size of the cache (used to filter transactions we saw earlier)
 cache_size = {{ .Mempool.CacheSize }}
 
+# Recheck txs of mempool after every block. Txs which became invalid or were evicted from full mempool by txs with
+# higher gas price are dropped by recheck, gas price priority of full mempool is disabled without it.
+recheck = {{ .Mempool.Recheck }}
+
 ##### instrumentation configuration options #####
 [instrumentation]
 
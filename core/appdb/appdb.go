@@ -5,8 +5,9 @@ import (
 	"errors"
 	"github.com/MinterTeam/minter-go-node/cmd/utils"
 	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/tendermint/go-amino"
-	"github.com/tendermint/tendermint/abci/types"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/libs/db"
 )
 
@@ -20,6 +21,8 @@ const (
 	startHeightPath    = "startHeight"
 	blockTimeDeltaPath = "blockDelta"
 	validatorsPath     = "validators"
+	maxGasPrefix       = "maxGas/"
+	blockRewardsPrefix = "blockRewards/"
 
 	dbName = "app"
 )
@@ -79,14 +82,14 @@ func (appDB *AppDB) GetStartHeight() uint64 {
 	return height
 }
 
-func (appDB *AppDB) GetValidators() types.ValidatorUpdates {
+func (appDB *AppDB) GetValidators() abciTypes.ValidatorUpdates {
 	result := appDB.db.Get([]byte(validatorsPath))
 
 	if len(result) == 0 {
-		return types.ValidatorUpdates{}
+		return abciTypes.ValidatorUpdates{}
 	}
 
-	var vals types.ValidatorUpdates
+	var vals abciTypes.ValidatorUpdates
 
 	err := cdc.UnmarshalBinaryBare(result, &vals)
 
@@ -97,7 +100,7 @@ func (appDB *AppDB) GetValidators() types.ValidatorUpdates {
 	return vals
 }
 
-func (appDB *AppDB) SaveValidators(vals types.ValidatorUpdates) {
+func (appDB *AppDB) SaveValidators(vals abciTypes.ValidatorUpdates) {
 	data, err := cdc.MarshalBinaryBare(vals)
 
 	if err != nil {
@@ -144,8 +147,136 @@ func (appDB *AppDB) SetLastBlocksTimeDelta(height uint64, delta int) {
 	appDB.db.Set([]byte(blockTimeDeltaPath), data)
 }
 
+// MaxGasRecord is max gas of a block and time in seconds taken by blocks which it was computed from
+type MaxGasRecord struct {
+	Height          uint64 `json:"height"`
+	MaxGas          uint64 `json:"max_gas"`
+	BlocksTimeDelta int    `json:"blocks_time_delta"`
+}
+
+func (appDB *AppDB) SetMaxGas(record MaxGasRecord) {
+	data, err := cdc.MarshalBinaryBare(record)
+
+	if err != nil {
+		panic(err)
+	}
+
+	appDB.db.Set(heightKey(maxGasPrefix, record.Height), data)
+}
+
+// GetMaxGasHistory returns max gas records of blocks from height from to height to inclusive
+func (appDB *AppDB) GetMaxGasHistory(from, to uint64) []MaxGasRecord {
+	var records []MaxGasRecord
+	if from > to {
+		return records
+	}
+
+	it := appDB.db.Iterator(heightKey(maxGasPrefix, from), heightKey(maxGasPrefix, to+1))
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		var record MaxGasRecord
+		if err := cdc.UnmarshalBinaryBare(it.Value(), &record); err != nil {
+			panic(err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// DeleteMaxGasAbove deletes max gas records of blocks above given height, it's used on rollback
+func (appDB *AppDB) DeleteMaxGasAbove(height uint64) {
+	appDB.deleteAbove(maxGasPrefix, height)
+}
+
+// BlockRewards is reward of a block and its distribution between validators, amounts are in PIP
+type BlockRewards struct {
+	Height uint64 `json:"height"`
+	// Reward is a block reward together with commissions of txs of the block
+	Reward string `json:"reward"`
+	// Remainder is a part of reward which is not distributed due to rounding or absent validators, it's added to
+	// total slashed
+	Remainder  string            `json:"remainder"`
+	Validators []ValidatorReward `json:"validators"`
+}
+
+// ValidatorReward is a share of block reward accrued to a validator, it's paid to validator and its delegators later
+type ValidatorReward struct {
+	PubKey        types.Pubkey  `json:"pub_key"`
+	RewardAddress types.Address `json:"reward_address"`
+	Status        string        `json:"status"`
+	Stake         string        `json:"stake"`
+	Reward        string        `json:"reward"`
+}
+
+func (appDB *AppDB) SetBlockRewards(rewards BlockRewards) {
+	data, err := cdc.MarshalBinaryBare(rewards)
+
+	if err != nil {
+		panic(err)
+	}
+
+	appDB.db.Set(heightKey(blockRewardsPrefix, rewards.Height), data)
+}
+
+// GetBlockRewards returns rewards of a block at given height or nil if they are not stored
+func (appDB *AppDB) GetBlockRewards(height uint64) *BlockRewards {
+	result := appDB.db.Get(heightKey(blockRewardsPrefix, height))
+	if len(result) == 0 {
+		return nil
+	}
+
+	var rewards BlockRewards
+	if err := cdc.UnmarshalBinaryBare(result, &rewards); err != nil {
+		panic(err)
+	}
+
+	return &rewards
+}
+
+func (appDB *AppDB) DeleteBlockRewards(height uint64) {
+	appDB.db.Delete(heightKey(blockRewardsPrefix, height))
+}
+
+// DeleteBlockRewardsAbove deletes rewards of blocks above given height, it's used on rollback
+func (appDB *AppDB) DeleteBlockRewardsAbove(height uint64) {
+	appDB.deleteAbove(blockRewardsPrefix, height)
+}
+
+// deleteAbove deletes records stored under height keys with given prefix above given height
+func (appDB *AppDB) deleteAbove(prefix string, height uint64) {
+	end := []byte(prefix)
+	end[len(end)-1]++
+
+	var keys [][]byte
+	it := appDB.db.Iterator(heightKey(prefix, height+1), end)
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, append([]byte{}, it.Key()...))
+	}
+	it.Close()
+
+	for _, key := range keys {
+		appDB.db.Delete(key)
+	}
+}
+
+func heightKey(prefix string, height uint64) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], height)
+
+	return key
+}
+
 func NewAppDB(cfg *config.Config) *AppDB {
+	return NewAppDBWithDB(db.NewDB(dbName, db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data"))
+}
+
+// NewAppDBWithDB creates AppDB on top of given db, e.g. in-memory one
+func NewAppDBWithDB(db db.DB) *AppDB {
 	return &AppDB{
-		db: db.NewDB(dbName, db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data"),
+		db: db,
 	}
 }
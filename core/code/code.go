@@ -16,6 +16,9 @@ const (
 	TxFromSenderAlreadyInMempool uint32 = 113
 	TooLowGasPrice               uint32 = 114
 	WrongChainID                 uint32 = 115
+	TxTypeNotActive              uint32 = 116
+	TxLowPriority                uint32 = 117
+	TxEvicted                    uint32 = 118
 
 	// coin creation
 	CoinAlreadyExists uint32 = 201
@@ -39,6 +42,9 @@ const (
 	IncorrectPubKey       uint32 = 407
 	StakeShouldBePositive uint32 = 408
 	TooLowStake           uint32 = 409
+	CandidateJailed       uint32 = 410
+	CandidateNotJailed    uint32 = 411
+	JailNotExpired        uint32 = 412
 
 	// check
 	CheckInvalidLock uint32 = 501
@@ -54,4 +60,11 @@ const (
 	MultisigNotExists       uint32 = 603
 	IncorrectMultiSignature uint32 = 604
 	TooLargeOwnersList      uint32 = 605
+
+	// governance
+	ProposalNotFound      uint32 = 701
+	InvalidProposal       uint32 = 702
+	ProposalVotingClosed  uint32 = 703
+	IsNotOwnerOfValidator uint32 = 704
+	InvalidVoteOption     uint32 = 705
 )
@@ -2,7 +2,8 @@ package dao
 
 import "github.com/MinterTeam/minter-go-node/core/types"
 
+// Address and Commission of DAO in mainnet, chains may override them with chain params
 var (
-	Address    = types.HexToAddress("Mx18467bbb64a8edf890201d526c35957d82be3d95")
-	Commission = 10
+	Address    = types.DefaultChainParams().DAOAddress
+	Commission = int(types.DefaultChainParams().DAOCommission)
 )
@@ -2,7 +2,8 @@ package developers
 
 import "github.com/MinterTeam/minter-go-node/core/types"
 
+// Address and Commission of developers in mainnet, chains may override them with chain params
 var (
-	Address    = types.HexToAddress("Mx04bea23efb744dc93b4fda4c20bf4a21c6e195f1")
-	Commission = 10
+	Address    = types.DefaultChainParams().DevelopersAddress
+	Commission = int(types.DefaultChainParams().DevelopersCommission)
 )
@@ -0,0 +1,599 @@
+package genesis
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/core/validators"
+	"github.com/MinterTeam/minter-go-node/formula"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	allowedCoinSymbols = "^[A-Z0-9]{3,10}$"
+	maxCoinNameBytes   = 64
+)
+
+// Input is a declarative description of a genesis app state. All amounts are in BIP (or in units of custom
+// coins) and may have up to 18 decimal places.
+type Input struct {
+	Note        string       `json:"note" yaml:"note"`
+	MaxGas      uint64       `json:"max_gas" yaml:"max_gas"`
+	Accounts    []Account    `json:"accounts" yaml:"accounts"`
+	Coins       []Coin       `json:"coins" yaml:"coins"`
+	Candidates  []Candidate  `json:"candidates" yaml:"candidates"`
+	FrozenFunds []FrozenFund `json:"frozen_funds" yaml:"frozen_funds"`
+
+	// ChainParams may be omitted, then mainnet parameters are used
+	ChainParams *types.ChainParams `json:"chain_params" yaml:"chain_params"`
+}
+
+type Account struct {
+	Address  string    `json:"address" yaml:"address"`
+	Balance  []Balance `json:"balance" yaml:"balance"`
+	Multisig *Multisig `json:"multisig" yaml:"multisig"`
+}
+
+type Balance struct {
+	Coin  string `json:"coin" yaml:"coin"`
+	Value string `json:"value" yaml:"value"`
+}
+
+type Multisig struct {
+	Threshold uint     `json:"threshold" yaml:"threshold"`
+	Weights   []uint   `json:"weights" yaml:"weights"`
+	Addresses []string `json:"addresses" yaml:"addresses"`
+}
+
+// Coin describes a custom coin. Volume may be omitted, then it is computed from balances, stakes and frozen funds.
+type Coin struct {
+	Symbol  string `json:"symbol" yaml:"symbol"`
+	Name    string `json:"name" yaml:"name"`
+	Crr     uint   `json:"crr" yaml:"crr"`
+	Reserve string `json:"reserve" yaml:"reserve"`
+	Volume  string `json:"volume" yaml:"volume"`
+}
+
+// Candidate describes a candidate. Public key is either Mp-prefixed hex or base64, as in priv_validator_key.json.
+type Candidate struct {
+	PubKey        string  `json:"pub_key" yaml:"pub_key"`
+	OwnerAddress  string  `json:"owner_address" yaml:"owner_address"`
+	RewardAddress string  `json:"reward_address" yaml:"reward_address"`
+	Commission    uint    `json:"commission" yaml:"commission"`
+	Stakes        []Stake `json:"stakes" yaml:"stakes"`
+}
+
+type Stake struct {
+	Owner string `json:"owner" yaml:"owner"`
+	Coin  string `json:"coin" yaml:"coin"`
+	Value string `json:"value" yaml:"value"`
+}
+
+type FrozenFund struct {
+	Height       uint64 `json:"height" yaml:"height"`
+	Address      string `json:"address" yaml:"address"`
+	CandidateKey string `json:"candidate_key" yaml:"candidate_key"`
+	Coin         string `json:"coin" yaml:"coin"`
+	Value        string `json:"value" yaml:"value"`
+}
+
+// Merge appends accounts, coins, candidates and frozen funds of another input
+func (i *Input) Merge(input Input) {
+	if input.Note != "" {
+		i.Note = input.Note
+	}
+
+	if input.MaxGas != 0 {
+		i.MaxGas = input.MaxGas
+	}
+
+	if input.ChainParams != nil {
+		i.ChainParams = input.ChainParams
+	}
+
+	i.Accounts = append(i.Accounts, input.Accounts...)
+	i.Coins = append(i.Coins, input.Coins...)
+	i.Candidates = append(i.Candidates, input.Candidates...)
+	i.FrozenFunds = append(i.FrozenFunds, input.FrozenFunds...)
+}
+
+// Build converts input into a genesis app state. Volumes and reserves of coins are checked against amounts owned
+// in the app state, top candidates become validators with power computed the same way as in InitChain.
+func Build(input Input) (types.AppState, error) {
+	appState := types.AppState{
+		Note:         input.Note,
+		MaxGas:       input.MaxGas,
+		TotalSlashed: big.NewInt(0),
+		ChainParams:  input.ChainParams,
+	}
+
+	params := types.DefaultChainParams()
+	if input.ChainParams != nil {
+		if err := input.ChainParams.Validate(); err != nil {
+			return appState, err
+		}
+
+		params = *input.ChainParams
+	}
+
+	if appState.MaxGas == 0 {
+		appState.MaxGas = params.MaxMaxGas
+	}
+
+	coins, err := buildCoins(input.Coins)
+	if err != nil {
+		return appState, err
+	}
+
+	owned := map[types.CoinSymbol]*big.Int{}
+	addOwned := func(coin types.CoinSymbol, value *big.Int) error {
+		if coin.IsBaseCoin() {
+			return nil
+		}
+
+		if _, exists := coins[coin]; !exists {
+			return fmt.Errorf("coin %s is not declared", coin)
+		}
+
+		if owned[coin] == nil {
+			owned[coin] = big.NewInt(0)
+		}
+		owned[coin].Add(owned[coin], value)
+
+		return nil
+	}
+
+	appState.Accounts, err = buildAccounts(input.Accounts, addOwned)
+	if err != nil {
+		return appState, err
+	}
+
+	appState.FrozenFunds, err = buildFrozenFunds(input.FrozenFunds, addOwned)
+	if err != nil {
+		return appState, err
+	}
+
+	// stakes are converted to BIP with final coin volumes, so volumes are computed first
+	var stakes []Stake
+	for _, candidate := range input.Candidates {
+		stakes = append(stakes, candidate.Stakes...)
+	}
+	for _, stake := range stakes {
+		value, err := parseAmount(stake.Value)
+		if err != nil {
+			return appState, fmt.Errorf("stake of %s: %s", stake.Owner, err)
+		}
+
+		if err := addOwned(types.StrToCoinSymbol(stake.Coin), value); err != nil {
+			return appState, err
+		}
+	}
+
+	for _, c := range input.Coins {
+		coin := coins[types.StrToCoinSymbol(c.Symbol)]
+		total := owned[coin.Symbol]
+		if total == nil {
+			total = big.NewInt(0)
+		}
+
+		if coin.Volume == nil {
+			coin.Volume = total
+		}
+
+		if coin.Volume.Cmp(total) != 0 {
+			return appState, fmt.Errorf("volume of %s coin (%s) does not match total owned (%s)", coin.Symbol,
+				coin.Volume, total)
+		}
+
+		if coin.Volume.Sign() != 1 {
+			return appState, fmt.Errorf("volume of %s coin should be positive", coin.Symbol)
+		}
+
+		appState.Coins = append(appState.Coins, *coin)
+	}
+
+	appState.Candidates, err = buildCandidates(input.Candidates, coins, params)
+	if err != nil {
+		return appState, err
+	}
+
+	appState.Validators, err = buildValidators(appState.Candidates, params)
+	if err != nil {
+		return appState, err
+	}
+
+	report, err := state.CheckAppStateInvariants(appState)
+	if err != nil {
+		return appState, err
+	}
+
+	if !report.IsValid() {
+		return appState, errors.New(strings.Join(report.Violations, "; "))
+	}
+
+	return appState, nil
+}
+
+// GetValidatorsPower returns voting power of genesis validators, as it is computed in InitChain
+func GetValidatorsPower(vals []types.Validator) []int64 {
+	totalPower := big.NewInt(0)
+	for _, val := range vals {
+		totalPower.Add(totalPower, val.TotalBipStake)
+	}
+
+	powers := make([]int64, len(vals))
+	for i, val := range vals {
+		powers[i] = validators.GetValidatorPower(val.TotalBipStake, totalPower)
+	}
+
+	return powers
+}
+
+func buildCoins(input []Coin) (map[types.CoinSymbol]*types.Coin, error) {
+	coins := map[types.CoinSymbol]*types.Coin{}
+	for _, c := range input {
+		if match, _ := regexp.MatchString(allowedCoinSymbols, c.Symbol); !match {
+			return nil, fmt.Errorf("invalid coin symbol %s, should be %s", c.Symbol, allowedCoinSymbols)
+		}
+
+		symbol := types.StrToCoinSymbol(c.Symbol)
+		if symbol.IsBaseCoin() {
+			return nil, fmt.Errorf("base coin %s cannot be declared", c.Symbol)
+		}
+
+		if _, exists := coins[symbol]; exists {
+			return nil, fmt.Errorf("coin %s is declared twice", c.Symbol)
+		}
+
+		if len(c.Name) > maxCoinNameBytes {
+			return nil, fmt.Errorf("name of %s coin is too long, allowed up to %d bytes", c.Symbol, maxCoinNameBytes)
+		}
+
+		if c.Crr < 10 || c.Crr > 100 {
+			return nil, fmt.Errorf("crr of %s coin should be between 10 and 100", c.Symbol)
+		}
+
+		reserve, err := parseAmount(c.Reserve)
+		if err != nil {
+			return nil, fmt.Errorf("reserve of %s coin: %s", c.Symbol, err)
+		}
+
+		if reserve.Sign() != 1 {
+			return nil, fmt.Errorf("reserve of %s coin should be positive", c.Symbol)
+		}
+
+		coin := &types.Coin{
+			Name:           c.Name,
+			Symbol:         symbol,
+			Crr:            c.Crr,
+			ReserveBalance: reserve,
+		}
+
+		if c.Volume != "" {
+			coin.Volume, err = parseAmount(c.Volume)
+			if err != nil {
+				return nil, fmt.Errorf("volume of %s coin: %s", c.Symbol, err)
+			}
+		}
+
+		coins[symbol] = coin
+	}
+
+	return coins, nil
+}
+
+func buildAccounts(input []Account, addOwned func(types.CoinSymbol, *big.Int) error) ([]types.Account, error) {
+	accounts := map[types.Address]*types.Account{}
+	balances := map[types.Address]map[types.CoinSymbol]*big.Int{}
+
+	for _, a := range input {
+		address, err := parseAddress(a.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		account, exists := accounts[address]
+		if !exists {
+			account = &types.Account{Address: address}
+			accounts[address] = account
+			balances[address] = map[types.CoinSymbol]*big.Int{}
+		}
+
+		for _, b := range a.Balance {
+			value, err := parseAmount(b.Value)
+			if err != nil {
+				return nil, fmt.Errorf("balance of %s: %s", a.Address, err)
+			}
+
+			coin := types.StrToCoinSymbol(b.Coin)
+			if err := addOwned(coin, value); err != nil {
+				return nil, err
+			}
+
+			if balances[address][coin] == nil {
+				balances[address][coin] = big.NewInt(0)
+			}
+			balances[address][coin].Add(balances[address][coin], value)
+		}
+
+		if a.Multisig != nil {
+			multisig, err := buildMultisig(a.Multisig)
+			if err != nil {
+				return nil, fmt.Errorf("multisig %s: %s", a.Address, err)
+			}
+			account.MultisigData = multisig
+		}
+	}
+
+	result := make([]types.Account, 0, len(accounts))
+	for address, account := range accounts {
+		for coin, value := range balances[address] {
+			account.Balance = append(account.Balance, types.Balance{Coin: coin, Value: value})
+		}
+
+		sort.Slice(account.Balance, func(i, j int) bool {
+			return account.Balance[i].Coin.Compare(account.Balance[j].Coin) == -1
+		})
+
+		result = append(result, *account)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Address.Compare(result[j].Address) == -1
+	})
+
+	return result, nil
+}
+
+func buildMultisig(input *Multisig) (*types.Multisig, error) {
+	if len(input.Addresses) == 0 || len(input.Addresses) != len(input.Weights) {
+		return nil, errors.New("addresses and weights should be non-empty and of the same length")
+	}
+
+	multisig := &types.Multisig{
+		Weights:   input.Weights,
+		Threshold: input.Threshold,
+	}
+
+	for _, a := range input.Addresses {
+		address, err := parseAddress(a)
+		if err != nil {
+			return nil, err
+		}
+		multisig.Addresses = append(multisig.Addresses, address)
+	}
+
+	return multisig, nil
+}
+
+func buildFrozenFunds(input []FrozenFund, addOwned func(types.CoinSymbol, *big.Int) error) ([]types.FrozenFund,
+	error) {
+	var frozenFunds []types.FrozenFund
+	for _, ff := range input {
+		address, err := parseAddress(ff.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := parseAmount(ff.Value)
+		if err != nil {
+			return nil, fmt.Errorf("frozen funds of %s: %s", ff.Address, err)
+		}
+
+		if ff.Height == 0 {
+			return nil, fmt.Errorf("height of frozen funds of %s should be positive", ff.Address)
+		}
+
+		// frozen funds which are not related to any candidate are marked with a zero key
+		candidateKey := types.Pubkey{0}
+		if ff.CandidateKey != "" {
+			candidateKey, err = parsePubKey(ff.CandidateKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		coin := types.StrToCoinSymbol(ff.Coin)
+		if err := addOwned(coin, value); err != nil {
+			return nil, err
+		}
+
+		frozenFunds = append(frozenFunds, types.FrozenFund{
+			Height:       ff.Height,
+			Address:      address,
+			CandidateKey: candidateKey,
+			Coin:         coin,
+			Value:        value,
+		})
+	}
+
+	sort.SliceStable(frozenFunds, func(i, j int) bool {
+		if frozenFunds[i].Height != frozenFunds[j].Height {
+			return frozenFunds[i].Height < frozenFunds[j].Height
+		}
+
+		return frozenFunds[i].Address.Compare(frozenFunds[j].Address) == -1
+	})
+
+	return frozenFunds, nil
+}
+
+func buildCandidates(input []Candidate, coins map[types.CoinSymbol]*types.Coin,
+	params types.ChainParams) ([]types.Candidate, error) {
+	var candidates []types.Candidate
+	pubKeys := map[string]struct{}{}
+
+	for _, c := range input {
+		pubKey, err := parsePubKey(c.PubKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := pubKeys[string(pubKey)]; exists {
+			return nil, fmt.Errorf("candidate %s is declared twice", pubKey)
+		}
+		pubKeys[string(pubKey)] = struct{}{}
+
+		ownerAddress, err := parseAddress(c.OwnerAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		rewardAddress := ownerAddress
+		if c.RewardAddress != "" {
+			rewardAddress, err = parseAddress(c.RewardAddress)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if c.Commission > 100 {
+			return nil, fmt.Errorf("commission of candidate %s should be between 0 and 100", pubKey)
+		}
+
+		candidate := types.Candidate{
+			RewardAddress:  rewardAddress,
+			OwnerAddress:   ownerAddress,
+			TotalBipStake:  big.NewInt(0),
+			PubKey:         pubKey,
+			Commission:     c.Commission,
+			CreatedAtBlock: 1,
+			Status:         state.CandidateStatusOnline,
+		}
+
+		for _, s := range c.Stakes {
+			owner, err := parseAddress(s.Owner)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := parseAmount(s.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			coin := types.StrToCoinSymbol(s.Coin)
+			bipValue := value
+			if !coin.IsBaseCoin() {
+				stakeCoin := coins[coin]
+				bipValue = formula.CalculateSaleReturn(stakeCoin.Volume, stakeCoin.ReserveBalance, stakeCoin.Crr,
+					value)
+			}
+
+			candidate.TotalBipStake.Add(candidate.TotalBipStake, bipValue)
+			candidate.Stakes = append(candidate.Stakes, types.Stake{
+				Owner:    owner,
+				Coin:     coin,
+				Value:    value,
+				BipValue: bipValue,
+			})
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	if maxCandidates := validators.CandidatesCountForBlock(params, 1); len(candidates) > maxCandidates {
+		return nil, fmt.Errorf("too many candidates, allowed up to %d", maxCandidates)
+	}
+
+	return candidates, nil
+}
+
+// buildValidators selects candidates with the biggest stakes as validators
+func buildValidators(candidates []types.Candidate, params types.ChainParams) ([]types.Validator, error) {
+	sorted := make([]types.Candidate, len(candidates))
+	copy(sorted, candidates)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].TotalBipStake.Cmp(sorted[j].TotalBipStake) == 1
+	})
+
+	count := validators.ValidatorsCountForBlock(params, 1)
+	if len(sorted) < count {
+		count = len(sorted)
+	}
+
+	vals := make([]types.Validator, 0, count)
+	for _, candidate := range sorted[:count] {
+		if candidate.TotalBipStake.Sign() != 1 {
+			break
+		}
+
+		vals = append(vals, types.Validator{
+			RewardAddress: candidate.RewardAddress,
+			TotalBipStake: candidate.TotalBipStake,
+			PubKey:        candidate.PubKey,
+			Commission:    candidate.Commission,
+			AccumReward:   big.NewInt(0),
+			AbsentTimes:   types.NewBitArray(int(params.ValidatorMaxAbsentWindow)),
+		})
+	}
+
+	if len(vals) == 0 {
+		return nil, errors.New("at least one candidate with a stake is required")
+	}
+
+	for i, power := range GetValidatorsPower(vals) {
+		if power == 0 {
+			return nil, fmt.Errorf("stake of validator %s is too small, its voting power is 0", vals[i].PubKey)
+		}
+	}
+
+	return vals, nil
+}
+
+func parseAddress(address string) (types.Address, error) {
+	if !types.IsHexAddress(address) {
+		return types.Address{}, fmt.Errorf("invalid address %s", address)
+	}
+
+	return types.HexToAddress(address), nil
+}
+
+func parsePubKey(pubKey string) (types.Pubkey, error) {
+	var key []byte
+	var err error
+	if strings.HasPrefix(pubKey, "Mp") {
+		key, err = hex.DecodeString(pubKey[2:])
+	} else {
+		key, err = base64.StdEncoding.DecodeString(pubKey)
+	}
+
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("invalid public key %s", pubKey)
+	}
+
+	return key, nil
+}
+
+// parseAmount converts decimal amount with up to 18 decimal places to pip
+func parseAmount(amount string) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return nil, errors.New("amount is not specified")
+	}
+
+	parts := strings.SplitN(amount, ".", 2)
+
+	fraction := ""
+	if len(parts) == 2 {
+		fraction = parts[1]
+	}
+
+	if len(fraction) > 18 {
+		return nil, fmt.Errorf("invalid amount %s, up to 18 decimal places are allowed", amount)
+	}
+
+	value, ok := big.NewInt(0).SetString(parts[0]+fraction+strings.Repeat("0", 18-len(fraction)), 10)
+	if !ok || value.Sign() == -1 {
+		return nil, fmt.Errorf("invalid amount %s", amount)
+	}
+
+	return value, nil
+}
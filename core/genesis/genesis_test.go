@@ -0,0 +1,121 @@
+package genesis
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const (
+	testAddress = "Mx02003587993aba5276925c058ba082d209e61cbb"
+	testPubKey  = "Mp0000000000000000000000000000000000000000000000000000000000000001"
+)
+
+var baseCoin = types.GetBaseCoin().String()
+
+func testInput() Input {
+	return Input{
+		Accounts: []Account{
+			{Address: testAddress, Balance: []Balance{{Coin: baseCoin, Value: "1000.5"}, {Coin: "TEST", Value: "100"}}},
+		},
+		Coins: []Coin{
+			{Symbol: "TEST", Name: "Test coin", Crr: 50, Reserve: "10000"},
+		},
+		Candidates: []Candidate{{
+			PubKey:       testPubKey,
+			OwnerAddress: testAddress,
+			Commission:   10,
+			Stakes:       []Stake{{Owner: testAddress, Coin: baseCoin, Value: "100"}},
+		}},
+		FrozenFunds: []FrozenFund{
+			{Height: 100, Address: testAddress, Coin: "TEST", Value: "50"},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	appState, err := Build(testInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(appState.Coins) != 1 || appState.Coins[0].Volume.Cmp(helpers.BipToPip(big.NewInt(150))) != 0 {
+		t.Fatalf("Volume of TEST coin should be computed from balances and frozen funds")
+	}
+
+	expectedBalance, _ := big.NewInt(0).SetString("1000500000000000000000", 10)
+	if appState.Accounts[0].Balance[0].Value.Cmp(expectedBalance) != 0 {
+		t.Fatalf("Balance should be %s, got %s", expectedBalance, appState.Accounts[0].Balance[0].Value)
+	}
+
+	if len(appState.Validators) != 1 {
+		t.Fatalf("Candidate should become a validator")
+	}
+
+	if powers := GetValidatorsPower(appState.Validators); powers[0] != 100000000 {
+		t.Fatalf("Power of the only validator should be 100000000, got %d", powers[0])
+	}
+
+	if appState.FrozenFunds[0].CandidateKey.Compare(types.Pubkey{0}) != 0 {
+		t.Fatalf("Frozen funds should not be bound to a candidate")
+	}
+}
+
+func TestBuildWithWrongVolume(t *testing.T) {
+	input := testInput()
+	input.Coins[0].Volume = "100"
+
+	if _, err := Build(input); err == nil || !strings.Contains(err.Error(), "does not match total owned") {
+		t.Fatalf("Wrong volume should be reported, got %v", err)
+	}
+}
+
+func TestBuildWithUndeclaredCoin(t *testing.T) {
+	input := testInput()
+	input.Accounts[0].Balance = append(input.Accounts[0].Balance, Balance{Coin: "ABC", Value: "1"})
+
+	if _, err := Build(input); err == nil || !strings.Contains(err.Error(), "is not declared") {
+		t.Fatalf("Undeclared coin should be reported, got %v", err)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	input, err := ReadCSV(strings.NewReader("address,coin,value\n" + testAddress + "," + baseCoin + ",10\n" +
+		"100," + testAddress + "," + baseCoin + ",5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(input.Accounts) != 1 || len(input.FrozenFunds) != 1 {
+		t.Fatalf("Expected 1 account and 1 frozen fund, got %d and %d", len(input.Accounts), len(input.FrozenFunds))
+	}
+
+	if input.FrozenFunds[0].Height != 100 {
+		t.Fatalf("Height of frozen funds should be 100, got %d", input.FrozenFunds[0].Height)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for amount, expected := range map[string]string{
+		"1":     "1000000000000000000",
+		"0.5":   "500000000000000000",
+		"10.01": "10010000000000000000",
+	} {
+		value, err := parseAmount(amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value.String() != expected {
+			t.Errorf("Amount %s should be parsed as %s, got %s", amount, expected, value)
+		}
+	}
+
+	for _, amount := range []string{"", "-1", "abc", "0.0000000000000000001"} {
+		if _, err := parseAmount(amount); err == nil {
+			t.Errorf("Amount %q should be invalid", amount)
+		}
+	}
+}
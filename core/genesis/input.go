@@ -0,0 +1,100 @@
+package genesis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LoadInput reads input from a file. Format is chosen by extension: .yaml, .yml, .json or .csv. CSV files contain
+// either balances (address,coin,value) or frozen funds (height,address,coin,value[,candidate_key]).
+func LoadInput(path string) (Input, error) {
+	var input Input
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return input, err
+		}
+
+		err = yaml.UnmarshalStrict(data, &input)
+		return input, err
+	case ".json":
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return input, err
+		}
+
+		err = json.Unmarshal(data, &input)
+		return input, err
+	case ".csv":
+		file, err := os.Open(path)
+		if err != nil {
+			return input, err
+		}
+		defer file.Close()
+
+		return ReadCSV(file)
+	}
+
+	return input, fmt.Errorf("unknown format of %s, expected yaml, json or csv", path)
+}
+
+// ReadCSV reads balances or frozen funds from CSV. Rows which start with a header name are skipped.
+func ReadCSV(r io.Reader) (Input, error) {
+	var input Input
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return input, err
+	}
+
+	for i, record := range records {
+		if len(record) == 0 || record[0] == "address" || record[0] == "height" {
+			continue
+		}
+
+		switch len(record) {
+		case 3:
+			input.Accounts = append(input.Accounts, Account{
+				Address: record[0],
+				Balance: []Balance{{Coin: record[1], Value: record[2]}},
+			})
+		case 4, 5:
+			height, err := strconv.ParseUint(record[0], 10, 64)
+			if err != nil {
+				return input, fmt.Errorf("line %d: invalid height %s", i+1, record[0])
+			}
+
+			frozenFund := FrozenFund{
+				Height:  height,
+				Address: record[1],
+				Coin:    record[2],
+				Value:   record[3],
+			}
+
+			if len(record) == 5 {
+				frozenFund.CandidateKey = record[4]
+			}
+
+			input.FrozenFunds = append(input.FrozenFunds, frozenFund)
+		default:
+			return input, fmt.Errorf("line %d: expected 3 columns for balances or 4-5 columns for frozen funds",
+				i+1)
+		}
+	}
+
+	return input, nil
+}
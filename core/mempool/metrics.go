@@ -0,0 +1,77 @@
+package mempool
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsSubsystem is a subsystem shared by all metrics exposed by this package
+const MetricsSubsystem = "mempool_priority"
+
+// Metrics contains metrics exposed by Pool
+type Metrics struct {
+	// Number of txs evicted from full mempool
+	EvictedTxs metrics.Counter
+	// Highest gas price of txs evicted by the last eviction
+	EvictedMaxGasPrice metrics.Gauge
+	// Number of txs rejected by full mempool because of their low gas price
+	RejectedTxs metrics.Counter
+	// Number of txs kept in mempool
+	KeptTxs metrics.Gauge
+}
+
+// PrometheusMetrics returns Metrics built using Prometheus client library
+func PrometheusMetrics(namespace string) *Metrics {
+	return &Metrics{
+		EvictedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "evicted_txs",
+			Help:      "Number of txs evicted from full mempool.",
+		}, []string{}),
+		EvictedMaxGasPrice: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "evicted_max_gas_price",
+			Help:      "Highest gas price of txs evicted by the last eviction.",
+		}, []string{}),
+		RejectedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rejected_txs",
+			Help:      "Number of txs rejected by full mempool because of their low gas price.",
+		}, []string{}),
+		KeptTxs: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "kept_txs",
+			Help:      "Number of txs kept in mempool.",
+		}, []string{}),
+	}
+}
+
+// NopMetrics returns no-op Metrics
+func NopMetrics() *Metrics {
+	return &Metrics{
+		EvictedTxs:         discard.NewCounter(),
+		EvictedMaxGasPrice: discard.NewGauge(),
+		RejectedTxs:        discard.NewCounter(),
+		KeptTxs:            discard.NewGauge(),
+	}
+}
+
+// Record updates metrics with results of an eviction
+func (m *Metrics) Record(kept []Tx, evicted []Tx) {
+	m.KeptTxs.Set(float64(len(kept)))
+	m.EvictedTxs.Add(float64(len(evicted)))
+
+	maxGasPrice := uint32(0)
+	for _, tx := range evicted {
+		if tx.GasPrice > maxGasPrice {
+			maxGasPrice = tx.GasPrice
+		}
+	}
+	m.EvictedMaxGasPrice.Set(float64(maxGasPrice))
+}
@@ -0,0 +1,210 @@
+package mempool
+
+import (
+	"errors"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"sync"
+)
+
+// reserveDivisor sets share of mempool which is reserved for evicted txs waiting for recheck: 1/10 of its size
+const reserveDivisor = 10
+
+var (
+	// ErrLowPriority is returned for txs which don't fit into full mempool because of their low gas price
+	ErrLowPriority = errors.New("mempool is full, gas price of tx is too low")
+	// ErrEvicted is returned on recheck of txs which are evicted from mempool by txs with higher priority
+	ErrEvicted = errors.New("tx is evicted from mempool by txs with higher gas price")
+)
+
+// Pool keeps priorities of txs admitted to Tendermint's mempool. When the mempool is full, a tx is admitted only if
+// its priority is higher than priority of some kept tx, and the lowest priority txs are evicted. Tendermint's mempool
+// can't drop a tx on request, so evicted txs are rejected when the mempool rechecks them after the next block. Pool
+// relies on the recheck to find out which txs are still in the mempool too, so it must not be used if recheck is
+// disabled. Nil Pool admits all txs.
+type Pool struct {
+	capacity int
+	metrics  *Metrics
+
+	lock sync.Mutex
+	txs  map[string]*poolTx
+	// kept is a number of admitted txs which are not evicted
+	kept int
+	// arrivals counts admitted txs, earlier txs win ties
+	arrivals int
+	// generation is increased by every commit, txs which are neither admitted nor rechecked during a generation are
+	// no longer in mempool
+	generation uint64
+}
+
+type poolTx struct {
+	tx         Tx
+	evicted    bool
+	generation uint64
+}
+
+// NewPool returns Pool for mempool of given size. Part of the mempool is reserved for evicted txs, so its capacity is
+// less than the size.
+func NewPool(mempoolSize int, metrics *Metrics) *Pool {
+	return &Pool{
+		capacity: mempoolSize - mempoolSize/reserveDivisor,
+		metrics:  metrics,
+		txs:      map[string]*poolTx{},
+	}
+}
+
+// Check reports whether tx can be admitted to mempool. Txs which are already kept are reported as still present in
+// the mempool, evicted ones are rejected with ErrEvicted and forgotten.
+func (p *Pool) Check(raw []byte) error {
+	if p == nil {
+		return nil
+	}
+
+	key := txKey(raw)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if ptx, ok := p.txs[key]; ok {
+		if ptx.evicted {
+			delete(p.txs, key)
+			return ErrEvicted
+		}
+
+		ptx.generation = p.generation
+		return nil
+	}
+
+	if p.kept < p.capacity {
+		return nil
+	}
+
+	tx, err := decodeTx(raw)
+	if err != nil {
+		// such tx is rejected by CheckTx anyway
+		return nil
+	}
+	tx.index = p.arrivals
+
+	_, evicted := evict(prioritize(append(p.keptTxs(), tx)), p.capacity)
+	for _, evictedTx := range evicted {
+		if evictedTx.index == tx.index {
+			p.metrics.RejectedTxs.Add(1)
+			return ErrLowPriority
+		}
+	}
+
+	return nil
+}
+
+// Add admits checked tx, the lowest priority txs are evicted if the pool is over its capacity
+func (p *Pool) Add(raw []byte) {
+	if p == nil {
+		return
+	}
+
+	key := txKey(raw)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.txs[key]; ok {
+		return
+	}
+
+	tx, err := decodeTx(raw)
+	if err != nil {
+		return
+	}
+	tx.index = p.arrivals
+	p.arrivals++
+
+	p.txs[key] = &poolTx{tx: tx, generation: p.generation}
+	p.kept++
+
+	if p.kept <= p.capacity {
+		p.metrics.KeptTxs.Set(float64(p.kept))
+		return
+	}
+
+	kept, evicted := evict(prioritize(p.keptTxs()), p.capacity)
+	for _, evictedTx := range evicted {
+		p.txs[txKey(evictedTx.Raw)].evicted = true
+	}
+	p.kept = len(kept)
+
+	p.metrics.Record(kept, evicted)
+}
+
+// Remove forgets tx. It's called for txs which are included into a block or rejected by recheck.
+func (p *Pool) Remove(raw []byte) {
+	if p == nil {
+		return
+	}
+
+	key := txKey(raw)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.remove(key)
+}
+
+// Commit forgets txs which were neither admitted nor rechecked since the previous commit, they are no longer in
+// mempool. It should be called before mempool rechecks its txs after a block.
+func (p *Pool) Commit() {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for key, ptx := range p.txs {
+		if ptx.generation != p.generation {
+			p.remove(key)
+		}
+	}
+
+	p.generation++
+	p.metrics.KeptTxs.Set(float64(p.kept))
+}
+
+// Size returns a number of kept txs
+func (p *Pool) Size() int {
+	if p == nil {
+		return 0
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.kept
+}
+
+func (p *Pool) remove(key string) {
+	ptx, ok := p.txs[key]
+	if !ok {
+		return
+	}
+
+	if !ptx.evicted {
+		p.kept--
+	}
+
+	delete(p.txs, key)
+}
+
+func (p *Pool) keptTxs() []Tx {
+	txs := make([]Tx, 0, p.kept+1)
+	for _, ptx := range p.txs {
+		if !ptx.evicted {
+			txs = append(txs, ptx.tx)
+		}
+	}
+
+	return txs
+}
+
+func txKey(raw []byte) string {
+	return string(tmhash.Sum(raw))
+}
@@ -0,0 +1,71 @@
+package mempool
+
+import (
+	"crypto/ecdsa"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	pool := NewPool(10, NopMetrics())
+
+	var keys []*ecdsa.PrivateKey
+	var txs [][]byte
+	for i := 0; i < 9; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+
+		tx := makeTestTx(t, key, 1, uint32(i+2))
+		if err := pool.Check(tx); err != nil {
+			t.Fatalf("Tx should be admitted to mempool which is not full, got %s", err)
+		}
+
+		pool.Add(tx)
+		txs = append(txs, tx)
+	}
+
+	if pool.Size() != 9 {
+		t.Fatalf("Pool should keep 9 txs, got %d", pool.Size())
+	}
+
+	keyA, _ := crypto.GenerateKey()
+	if err := pool.Check(makeTestTx(t, keyA, 1, 1)); err != ErrLowPriority {
+		t.Fatalf("Tx with the lowest gas price should be rejected by full mempool, got %v", err)
+	}
+
+	highPriorityTx := makeTestTx(t, keyA, 1, 20)
+	if err := pool.Check(highPriorityTx); err != nil {
+		t.Fatalf("Tx with high gas price should be admitted to full mempool, got %s", err)
+	}
+	pool.Add(highPriorityTx)
+
+	if pool.Size() != 9 {
+		t.Fatalf("The lowest priority tx should be evicted, pool keeps %d txs", pool.Size())
+	}
+
+	if err := pool.Check(txs[0]); err != ErrEvicted {
+		t.Fatalf("Evicted tx should be rejected by recheck, got %v", err)
+	}
+
+	if err := pool.Check(txs[0]); err != ErrLowPriority {
+		t.Fatalf("Evicted tx should not be admitted again, got %v", err)
+	}
+
+	// txs which are not rechecked after the block are no longer in mempool
+	pool.Commit()
+	for _, tx := range txs[1:5] {
+		if err := pool.Check(tx); err != nil {
+			t.Fatalf("Kept tx should pass recheck, got %s", err)
+		}
+	}
+	pool.Remove(txs[1])
+	pool.Commit()
+
+	if pool.Size() != 3 {
+		t.Fatalf("Pool should keep 3 rechecked txs, got %d", pool.Size())
+	}
+
+	if err := pool.Check(makeTestTx(t, keys[0], 2, 1)); err != nil {
+		t.Fatalf("Tx should be admitted to mempool which is not full, got %s", err)
+	}
+}
@@ -0,0 +1,108 @@
+package mempool
+
+import (
+	"container/heap"
+	"github.com/MinterTeam/minter-go-node/core/transaction"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"sort"
+)
+
+// Tx is a mempool tx with fields which define its priority
+type Tx struct {
+	Raw      []byte
+	Sender   types.Address
+	Nonce    uint64
+	GasPrice uint32
+
+	// index is an arrival number of tx, earlier txs win ties
+	index int
+}
+
+// prioritize orders txs by gas price from the highest to the lowest, txs of the same sender keep their nonce order.
+// Ties are won by txs with lower index.
+func prioritize(txs []Tx) []Tx {
+	senders := map[types.Address][]Tx{}
+	for _, tx := range txs {
+		senders[tx.Sender] = append(senders[tx.Sender], tx)
+	}
+
+	// queue holds the next tx of each sender
+	queue := &txQueue{}
+	for _, txs := range senders {
+		sort.SliceStable(txs, func(i, j int) bool {
+			return txs[i].Nonce < txs[j].Nonce
+		})
+
+		queue.push(txs)
+	}
+	heap.Init(queue)
+
+	prioritized := make([]Tx, 0, len(txs))
+	for queue.Len() > 0 {
+		txs := heap.Pop(queue).([]Tx)
+		prioritized = append(prioritized, txs[0])
+
+		if len(txs) > 1 {
+			heap.Push(queue, txs[1:])
+		}
+	}
+
+	return prioritized
+}
+
+// evict splits prioritized txs into txs which should be kept in mempool of given capacity and evicted ones. The
+// lowest priority txs are evicted, so nonce order of kept txs is not broken.
+func evict(prioritized []Tx, capacity int) (kept []Tx, evicted []Tx) {
+	if len(prioritized) <= capacity {
+		return prioritized, nil
+	}
+
+	return prioritized[:capacity], prioritized[capacity:]
+}
+
+func decodeTx(raw []byte) (Tx, error) {
+	tx, err := transaction.TxDecoder.DecodeFromBytes(raw)
+	if err != nil {
+		return Tx{}, err
+	}
+
+	sender, err := tx.Sender()
+	if err != nil {
+		return Tx{}, err
+	}
+
+	return Tx{
+		Raw:      raw,
+		Sender:   sender,
+		Nonce:    tx.Nonce,
+		GasPrice: tx.GasPrice,
+	}, nil
+}
+
+// txQueue is a heap of nonce ordered txs of senders, ordered by gas price of their first txs
+type txQueue [][]Tx
+
+func (q txQueue) Len() int { return len(q) }
+
+func (q txQueue) Less(i, j int) bool {
+	if q[i][0].GasPrice != q[j][0].GasPrice {
+		return q[i][0].GasPrice > q[j][0].GasPrice
+	}
+
+	return q[i][0].index < q[j][0].index
+}
+
+func (q txQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *txQueue) Push(x interface{}) { q.push(x.([]Tx)) }
+
+func (q *txQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	txs := old[n-1]
+	*q = old[:n-1]
+
+	return txs
+}
+
+func (q *txQueue) push(txs []Tx) { *q = append(*q, txs) }
@@ -0,0 +1,93 @@
+package mempool
+
+import (
+	"crypto/ecdsa"
+	"github.com/MinterTeam/minter-go-node/core/transaction"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/rlp"
+	"math/big"
+	"testing"
+)
+
+func makeTestTx(t *testing.T, privateKey *ecdsa.PrivateKey, nonce uint64, gasPrice uint32) []byte {
+	data, err := rlp.EncodeToBytes(transaction.SendData{
+		Coin:  types.GetBaseCoin(),
+		To:    types.Address{1},
+		Value: big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := transaction.Transaction{
+		Nonce:         nonce,
+		GasPrice:      gasPrice,
+		ChainID:       types.CurrentChainID,
+		GasCoin:       types.GetBaseCoin(),
+		Type:          transaction.TypeSend,
+		Data:          data,
+		SignatureType: transaction.SigTypeSingle,
+	}
+
+	if err := tx.Sign(privateKey); err != nil {
+		t.Fatal(err)
+	}
+
+	encodedTx, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return encodedTx
+}
+
+func TestPrioritize(t *testing.T) {
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	keyC, _ := crypto.GenerateKey()
+
+	a2 := makeTestTx(t, keyA, 2, 10)
+	a1 := makeTestTx(t, keyA, 1, 1)
+	b1 := makeTestTx(t, keyB, 1, 5)
+	c1 := makeTestTx(t, keyC, 1, 5)
+
+	var txs []Tx
+	for i, raw := range [][]byte{a2, a1, b1, c1} {
+		tx, err := decodeTx(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tx.index = i
+		txs = append(txs, tx)
+	}
+
+	prioritized := prioritize(txs)
+
+	// txs of A keep nonce order even though the second one has higher gas price, B wins tie with C by arrival
+	expected := [][]byte{b1, c1, a1, a2}
+	if len(prioritized) != len(expected) {
+		t.Fatalf("Expected %d txs, got %d", len(expected), len(prioritized))
+	}
+
+	for i := range expected {
+		if string(prioritized[i].Raw) != string(expected[i]) {
+			t.Fatalf("Tx %d is out of order, got nonce %d with gas price %d", i, prioritized[i].Nonce,
+				prioritized[i].GasPrice)
+		}
+	}
+
+	kept, evicted := evict(prioritized, 3)
+	if len(kept) != 3 || len(evicted) != 1 {
+		t.Fatalf("Expected 3 kept and 1 evicted txs, got %d and %d", len(kept), len(evicted))
+	}
+
+	if string(evicted[0].Raw) != string(a2) {
+		t.Fatalf("Lowest priority txs should be evicted")
+	}
+
+	if kept, evicted := evict(prioritized, 10); len(kept) != 4 || len(evicted) != 0 {
+		t.Fatalf("Nothing should be evicted from mempool which is not full")
+	}
+}
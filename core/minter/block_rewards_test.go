@@ -0,0 +1,51 @@
+package minter
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/rewards"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"testing"
+)
+
+func TestBlockRewards(t *testing.T) {
+	app := newInMemoryBlockchain()
+	app.InitChain(abciTypes.RequestInitChain{AppStateBytes: newTestAppState(t)})
+	app.blockRewardsKeepRecent = 2
+
+	address := app.stateDeliver.GetStateValidators().Data()[0].GetAddress()
+
+	for height := int64(1); height <= 3; height++ {
+		// the validator misses the first block
+		var votes []abciTypes.VoteInfo
+		if height > 1 {
+			votes = append(votes, abciTypes.VoteInfo{
+				Validator:       abciTypes.Validator{Address: address[:]},
+				SignedLastBlock: true,
+			})
+		}
+
+		app.BeginBlock(abciTypes.RequestBeginBlock{
+			Header:         abciTypes.Header{Height: height},
+			LastCommitInfo: abciTypes.LastCommitInfo{Votes: votes},
+		})
+		app.EndBlock(abciTypes.RequestEndBlock{Height: height})
+		app.Commit()
+	}
+
+	if app.GetBlockRewards(1) != nil {
+		t.Fatalf("Rewards of block 1 should be deleted, only 2 recent blocks are kept")
+	}
+
+	blockRewards := app.GetBlockRewards(3)
+	if blockRewards == nil || len(blockRewards.Validators) != 1 {
+		t.Fatalf("Rewards of block 3 should be distributed to a single validator, got %+v", blockRewards)
+	}
+
+	reward := rewards.GetRewardForBlock(app.ChainParams(), 3).String()
+	if blockRewards.Reward != reward || blockRewards.Validators[0].Reward != reward || blockRewards.Remainder != "0" {
+		t.Fatalf("Present validator should get the whole reward %s, got %+v", reward, blockRewards)
+	}
+
+	if status := blockRewards.Validators[0].Status; status != "present" {
+		t.Fatalf("Validator status should be present, got %s", status)
+	}
+}
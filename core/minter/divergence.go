@@ -0,0 +1,105 @@
+package minter
+
+import (
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/transaction"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/log"
+	types2 "github.com/tendermint/tendermint/types"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// divergenceDetector is a debugging tool which explains why txs pass CheckTx but fail in blocks. Every delivered tx
+// is replayed on check states created from the last committed state and results are compared with the delivered one.
+type divergenceDetector struct {
+	height uint64
+
+	// committed is a check state as it is seen by CheckTx, txs of the block are not applied to it
+	committed *state.StateDB
+
+	// replay is a check state to which txs of the block are applied in the same order as to deliver state
+	replay *state.StateDB
+}
+
+func newDivergenceDetector() *divergenceDetector {
+	return &divergenceDetector{}
+}
+
+// beginBlock resets check states, should be called before txs of the block are delivered
+func (d *divergenceDetector) beginBlock(height uint64, stateDeliver *state.StateDB) {
+	d.height = height
+	d.committed = state.NewForCheckFromDeliver(stateDeliver)
+	d.replay = state.NewForCheckFromDeliver(stateDeliver)
+}
+
+// deliverTx replays delivered tx, logs and returns found divergences
+func (d *divergenceDetector) deliverTx(rawTx []byte, delivered transaction.Response,
+	stateDeliver *state.StateDB) []string {
+	var divergences []string
+
+	mempool := transaction.RunTx(d.committed, true, rawTx, nil, d.height, sync.Map{}, 0)
+	if mempool.Code != delivered.Code {
+		divergences = append(divergences, fmt.Sprintf("code against committed state is %d (%s), delivered %d (%s)",
+			mempool.Code, mempool.Log, delivered.Code, delivered.Log))
+	}
+
+	checked := transaction.RunTx(d.replay, true, rawTx, nil, d.height, sync.Map{}, 0)
+	if checked.Code != delivered.Code || checked.GasUsed != delivered.GasUsed {
+		divergences = append(divergences, fmt.Sprintf("check mode gives code %d, gas %d (%s), delivered code %d, "+
+			"gas %d", checked.Code, checked.GasUsed, checked.Log, delivered.Code, delivered.GasUsed))
+	}
+
+	replayed := transaction.RunTx(d.replay, false, rawTx, big.NewInt(0), d.height, sync.Map{}, 0)
+	if replayed.Code != delivered.Code || replayed.GasUsed != delivered.GasUsed {
+		divergences = append(divergences, fmt.Sprintf("replay on check state gives code %d, gas %d (%s), delivered "+
+			"code %d, gas %d", replayed.Code, replayed.GasUsed, replayed.Log, delivered.Code, delivered.GasUsed))
+	}
+
+	if tx, err := transaction.TxDecoder.DecodeFromBytes(rawTx); err == nil {
+		if sender, err := tx.Sender(); err == nil {
+			divergences = append(divergences, compareBalances(sender, d.replay, stateDeliver)...)
+		}
+	}
+
+	logger := log.With("module", "divergence")
+	for _, divergence := range divergences {
+		logger.Error("Check and deliver states diverged", "height", d.height,
+			"tx", fmt.Sprintf("%X", types2.Tx(rawTx).Hash()), "msg", divergence)
+	}
+
+	return divergences
+}
+
+// compareBalances compares all balances of address in check and deliver states
+func compareBalances(address types.Address, stateCheck, stateDeliver *state.StateDB) []string {
+	var coins []types.CoinSymbol
+	seen := map[types.CoinSymbol]bool{}
+	for _, balances := range []state.Balances{stateCheck.GetBalances(address), stateDeliver.GetBalances(address)} {
+		for coin := range balances.Data {
+			if !seen[coin] {
+				seen[coin] = true
+				coins = append(coins, coin)
+			}
+		}
+	}
+
+	sort.Slice(coins, func(i, j int) bool {
+		return coins[i].String() < coins[j].String()
+	})
+
+	var divergences []string
+	for _, coin := range coins {
+		checkBalance := stateCheck.GetBalance(address, coin)
+		deliverBalance := stateDeliver.GetBalance(address, coin)
+
+		if checkBalance.Cmp(deliverBalance) != 0 {
+			divergences = append(divergences, fmt.Sprintf("balance of %s in %s is %s in check state, %s in deliver "+
+				"state", address, coin, checkBalance, deliverBalance))
+		}
+	}
+
+	return divergences
+}
@@ -0,0 +1,91 @@
+package minter
+
+import (
+	"crypto/ecdsa"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/transaction"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/rlp"
+	"github.com/tendermint/tendermint/libs/db"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func makeSendTx(t *testing.T, privateKey *ecdsa.PrivateKey, nonce uint64, to types.Address, value *big.Int) []byte {
+	data, err := rlp.EncodeToBytes(transaction.SendData{
+		Coin:  types.GetBaseCoin(),
+		To:    to,
+		Value: value,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := transaction.Transaction{
+		Nonce:         nonce,
+		GasPrice:      1,
+		ChainID:       types.CurrentChainID,
+		GasCoin:       types.GetBaseCoin(),
+		Type:          transaction.TypeSend,
+		Data:          data,
+		SignatureType: transaction.SigTypeSingle,
+	}
+
+	if err := tx.Sign(privateKey); err != nil {
+		t.Fatal(err)
+	}
+
+	rawTx, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rawTx
+}
+
+func TestDivergenceDetector(t *testing.T) {
+	stateDeliver, err := state.New(0, db.NewMemDB(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funded, _ := crypto.GenerateKey()
+	unfunded, _ := crypto.GenerateKey()
+	to := types.Address{1}
+
+	stateDeliver.AddBalance(crypto.PubkeyToAddress(funded.PublicKey), types.GetBaseCoin(),
+		helpers.BipToPip(big.NewInt(1000)))
+	if _, _, err := stateDeliver.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDivergenceDetector()
+	d.beginBlock(2, stateDeliver)
+
+	// funds released at the beginning of a block are not visible to CheckTx
+	stateDeliver.AddBalance(crypto.PubkeyToAddress(unfunded.PublicKey), types.GetBaseCoin(),
+		helpers.BipToPip(big.NewInt(1000)))
+
+	rawTx := makeSendTx(t, funded, 1, to, helpers.BipToPip(big.NewInt(10)))
+	response := transaction.RunTx(stateDeliver, false, rawTx, big.NewInt(0), 2, sync.Map{}, 0)
+	if response.Code != 0 {
+		t.Fatalf("Tx should be delivered, got code %d: %s", response.Code, response.Log)
+	}
+
+	if divergences := d.deliverTx(rawTx, response, stateDeliver); len(divergences) != 0 {
+		t.Fatalf("Tx of funded account should not diverge, got %v", divergences)
+	}
+
+	rawTx = makeSendTx(t, unfunded, 1, to, helpers.BipToPip(big.NewInt(10)))
+	response = transaction.RunTx(stateDeliver, false, rawTx, big.NewInt(0), 2, sync.Map{}, 0)
+	if response.Code != 0 {
+		t.Fatalf("Tx should be delivered, got code %d: %s", response.Code, response.Log)
+	}
+
+	if divergences := d.deliverTx(rawTx, response, stateDeliver); len(divergences) == 0 {
+		t.Fatalf("Tx of account funded in the current block should diverge")
+	}
+}
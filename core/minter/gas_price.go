@@ -0,0 +1,171 @@
+package minter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// recentBlocksCount is a number of recent blocks which gas usage is used to recommend gas price
+const recentBlocksCount = 100
+
+// GasPriceThreshold sets minimal gas price of txs accepted to mempool when it holds more than MempoolSize txs
+type GasPriceThreshold struct {
+	MempoolSize int    `json:"mempool_size"`
+	GasPrice    uint32 `json:"gas_price"`
+}
+
+// ParseGasPriceThresholds parses comma separated list of "mempool_size:gas_price" pairs, pairs are sorted by mempool
+// size and gas price should not decrease with growth of mempool
+func ParseGasPriceThresholds(s string) ([]GasPriceThreshold, error) {
+	var thresholds []GasPriceThreshold
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid gas price threshold %q, should be mempool_size:gas_price", pair)
+		}
+
+		mempoolSize, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || mempoolSize < 0 {
+			return nil, fmt.Errorf("invalid mempool size in gas price threshold %q", pair)
+		}
+
+		gasPrice, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gas price in gas price threshold %q", pair)
+		}
+
+		thresholds = append(thresholds, GasPriceThreshold{MempoolSize: mempoolSize, GasPrice: uint32(gasPrice)})
+	}
+
+	sort.Slice(thresholds, func(i, j int) bool {
+		return thresholds[i].MempoolSize < thresholds[j].MempoolSize
+	})
+
+	for i := 1; i < len(thresholds); i++ {
+		if thresholds[i].MempoolSize == thresholds[i-1].MempoolSize {
+			return nil, fmt.Errorf("duplicate gas price threshold for mempool size %d", thresholds[i].MempoolSize)
+		}
+
+		if thresholds[i].GasPrice < thresholds[i-1].GasPrice {
+			return nil, fmt.Errorf("gas price should not decrease with mempool size, got %d after %d",
+				thresholds[i].GasPrice, thresholds[i-1].GasPrice)
+		}
+	}
+
+	return thresholds, nil
+}
+
+// gasPriceCurve computes minimal gas price of txs accepted to mempool from its size
+type gasPriceCurve struct {
+	// staticMin is a minimal gas price of this node regardless of mempool size
+	staticMin  uint32
+	thresholds []GasPriceThreshold
+}
+
+func (c gasPriceCurve) minGasPrice(mempoolSize int) uint32 {
+	price := c.staticMin
+	for _, threshold := range c.thresholds {
+		if mempoolSize > threshold.MempoolSize && threshold.GasPrice > price {
+			price = threshold.GasPrice
+		}
+	}
+
+	return price
+}
+
+// blockGasUsage describes how full a block was and the lowest gas price included into it
+type blockGasUsage struct {
+	gasUsed     uint64
+	maxGas      uint64
+	txsCount    int
+	minGasPrice uint32
+}
+
+// isCongested reports whether the block had no room for more txs
+func (b blockGasUsage) isCongested() bool {
+	return b.gasUsed*10 >= b.maxGas*9
+}
+
+// gasUsageHistory keeps gas usage of recent blocks, it is written by consensus and read by API
+type gasUsageHistory struct {
+	lock    sync.RWMutex
+	blocks  []blockGasUsage
+	current blockGasUsage
+}
+
+func newGasUsageHistory() *gasUsageHistory {
+	return &gasUsageHistory{}
+}
+
+func (h *gasUsageHistory) beginBlock(maxGas uint64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.current = blockGasUsage{maxGas: maxGas}
+}
+
+func (h *gasUsageHistory) deliverTx(gasUsed uint64, gasPrice uint32) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.current.gasUsed += gasUsed
+	if h.current.txsCount == 0 || gasPrice < h.current.minGasPrice {
+		h.current.minGasPrice = gasPrice
+	}
+	h.current.txsCount++
+}
+
+func (h *gasUsageHistory) endBlock() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.blocks = append(h.blocks, h.current)
+	if len(h.blocks) > recentBlocksCount {
+		h.blocks = h.blocks[len(h.blocks)-recentBlocksCount:]
+	}
+}
+
+// recommendedGasPrice estimates gas price which is enough for a tx to be included within n blocks. If txs of mempool
+// fit into n blocks by average gas of recent txs, minGasPrice is enough. Otherwise it's the highest of the lowest gas
+// prices included into recent congested blocks.
+func (h *gasUsageHistory) recommendedGasPrice(n int, mempoolSize int, minGasPrice uint32) uint32 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.blocks) == 0 || n <= 0 {
+		return minGasPrice
+	}
+
+	var gasUsed, txsCount uint64
+	for _, block := range h.blocks {
+		gasUsed += block.gasUsed
+		txsCount += uint64(block.txsCount)
+	}
+
+	if txsCount == 0 {
+		return minGasPrice
+	}
+
+	maxGas := h.blocks[len(h.blocks)-1].maxGas
+	if uint64(mempoolSize)*gasUsed/txsCount < uint64(n)*maxGas {
+		return minGasPrice
+	}
+
+	price := minGasPrice
+	for _, block := range h.blocks {
+		if block.isCongested() && block.minGasPrice > price {
+			price = block.minGasPrice
+		}
+	}
+
+	return price
+}
@@ -0,0 +1,92 @@
+package minter
+
+import (
+	"testing"
+)
+
+func TestParseGasPriceThresholds(t *testing.T) {
+	thresholds, err := ParseGasPriceThresholds("500:5, 100:2,1000:10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []GasPriceThreshold{{100, 2}, {500, 5}, {1000, 10}}
+	if len(thresholds) != len(expected) {
+		t.Fatalf("Expected %d thresholds, got %d", len(expected), len(thresholds))
+	}
+
+	for i := range expected {
+		if thresholds[i] != expected[i] {
+			t.Fatalf("Threshold %d should be %+v, got %+v", i, expected[i], thresholds[i])
+		}
+	}
+
+	if thresholds, err := ParseGasPriceThresholds(""); err != nil || len(thresholds) != 0 {
+		t.Fatalf("Empty thresholds should be allowed, got %+v, %v", thresholds, err)
+	}
+
+	for _, s := range []string{"100", "a:1", "100:b", "-1:1", "100:2,100:3", "100:5,500:2"} {
+		if _, err := ParseGasPriceThresholds(s); err == nil {
+			t.Fatalf("Thresholds %q should be invalid", s)
+		}
+	}
+}
+
+func TestGasPriceCurve(t *testing.T) {
+	thresholds, err := ParseGasPriceThresholds("100:2,500:5,1000:10,5000:50")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	curve := gasPriceCurve{staticMin: 1, thresholds: thresholds}
+	for mempoolSize, price := range map[int]uint32{0: 1, 100: 1, 101: 2, 501: 5, 1001: 10, 5001: 50} {
+		if got := curve.minGasPrice(mempoolSize); got != price {
+			t.Fatalf("Min gas price for mempool size %d should be %d, got %d", mempoolSize, price, got)
+		}
+	}
+
+	curve.staticMin = 7
+	if got := curve.minGasPrice(501); got != 7 {
+		t.Fatalf("Static min gas price should not be lowered by thresholds, got %d", got)
+	}
+}
+
+func TestRecommendedGasPrice(t *testing.T) {
+	history := newGasUsageHistory()
+
+	if price := history.recommendedGasPrice(1, 1000, 1); price != 1 {
+		t.Fatalf("Min gas price should be recommended without history, got %d", price)
+	}
+
+	// congested block with txs of 3 and 4 gas price and a half empty block
+	history.beginBlock(100)
+	history.deliverTx(50, 4)
+	history.deliverTx(50, 3)
+	history.endBlock()
+
+	history.beginBlock(100)
+	history.deliverTx(50, 1)
+	history.endBlock()
+
+	// average tx takes 50 gas, so 3 txs fit into 2 blocks
+	if price := history.recommendedGasPrice(2, 3, 1); price != 1 {
+		t.Fatalf("Min gas price should be recommended if mempool fits into blocks, got %d", price)
+	}
+
+	if price := history.recommendedGasPrice(1, 3, 1); price != 3 {
+		t.Fatalf("Lowest gas price of congested block should be recommended, got %d", price)
+	}
+
+	if price := history.recommendedGasPrice(1, 3, 5); price != 5 {
+		t.Fatalf("Recommended gas price should not be lower than min gas price, got %d", price)
+	}
+
+	for i := 0; i < recentBlocksCount; i++ {
+		history.beginBlock(100)
+		history.endBlock()
+	}
+
+	if len(history.blocks) != recentBlocksCount {
+		t.Fatalf("Only %d recent blocks should be kept, got %d", recentBlocksCount, len(history.blocks))
+	}
+}
@@ -0,0 +1,98 @@
+package minter
+
+import (
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/eventsdb"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/db"
+	"testing"
+)
+
+func TestHaltHeight(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.HaltHeight = 2
+
+	app := NewMinterBlockchainWithDB(cfg, db.NewMemDB(), appdb.NewAppDBWithDB(db.NewMemDB()),
+		eventsdb.NewEventsDB(db.NewMemDB()))
+	app.InitChain(abciTypes.RequestInitChain{AppStateBytes: newTestAppState(t)})
+
+	if height, reason := app.PendingHalt(); height != 2 || reason == "" {
+		t.Fatalf("Halt should be pending at height 2, got %d (%s)", height, reason)
+	}
+
+	for height := int64(1); height <= 2; height++ {
+		select {
+		case <-app.Halted():
+			t.Fatalf("Node should not be halted before height %d is committed", height)
+		default:
+		}
+
+		app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: height}})
+		app.EndBlock(abciTypes.RequestEndBlock{Height: height})
+		app.Commit()
+	}
+
+	select {
+	case <-app.Halted():
+	default:
+		t.Fatalf("Node should be halted after committing halt height")
+	}
+
+	if lastHeight := app.appDB.GetLastHeight(); lastHeight != 2 {
+		t.Fatalf("State at halt height should be persisted, got last height %d", lastHeight)
+	}
+
+	if height, _ := app.PendingHalt(); height != 0 {
+		t.Fatalf("Halt should not be pending after the node is halted, got %d", height)
+	}
+}
+
+func TestHaltAtUpgradeHeight(t *testing.T) {
+	params := types.DefaultChainParams()
+	params.ProposalVotingPeriod = 1
+
+	app := newInMemoryBlockchain()
+	app.InitChain(abciTypes.RequestInitChain{AppStateBytes: newTestAppStateWithParams(t, &params)})
+
+	owner := types.HexToAddress("Mx02003587993aba5276925c058ba082d209e61cbb")
+	upgradeHeight := uint64(3)
+
+	for height := int64(1); height <= 4; height++ {
+		app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: height}})
+		if height == 1 {
+			id := app.stateDeliver.CreateProposal(owner, state.ProposalTypeUpgrade, "unknown", 0, upgradeHeight)
+			app.stateDeliver.SetProposalVote(id, owner, state.VoteYes)
+		}
+		app.EndBlock(abciTypes.RequestEndBlock{Height: height})
+		app.Commit()
+
+		if height == 2 {
+			if haltHeight, _ := app.PendingHalt(); haltHeight != upgradeHeight {
+				t.Fatalf("Halt should be pending at upgrade height %d, got %d", upgradeHeight, haltHeight)
+			}
+
+			if upgrade := app.UnsupportedUpgrade(); upgrade != nil {
+				t.Fatalf("Upgrade should not be applied before its height, got %+v", upgrade)
+			}
+		}
+
+		select {
+		case <-app.Halted():
+			if uint64(height) < upgradeHeight {
+				t.Fatalf("Node should not be halted before upgrade height, halted at %d", height)
+			}
+		default:
+			if uint64(height) >= upgradeHeight {
+				t.Fatalf("Node should be halted after committing upgrade height, not halted at %d", height)
+			}
+		}
+	}
+
+	// a restarted node checks applied upgrades of the committed state
+	if upgrade := app.UnsupportedUpgrade(); upgrade == nil || upgrade.Height != upgradeHeight {
+		t.Fatalf("Applied upgrade should be reported as unsupported, got %+v", upgrade)
+	}
+}
@@ -0,0 +1,410 @@
+// Package harness runs several Blockchain instances in one process and feeds them identical blocks without
+// Tendermint and networking, so consensus-critical behaviour (rewards, slashing, validator set changes) can be
+// reproduced in unit tests.
+package harness
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/go-amino"
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/genesis"
+	"github.com/MinterTeam/minter-go-node/core/minter"
+	"github.com/MinterTeam/minter-go-node/core/transaction"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/eventsdb"
+	"github.com/MinterTeam/minter-go-node/log"
+	"github.com/MinterTeam/minter-go-node/rlp"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/db"
+	tmTypes "github.com/tendermint/tendermint/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	chainID       = "minter-harness"
+	blockInterval = 5 * time.Second
+)
+
+// Validator holds keys of a validator node and of its owner account
+type Validator struct {
+	PrivKey ed25519.PrivKeyEd25519
+	Owner   *ecdsa.PrivateKey
+	Nonce   uint64
+}
+
+// PubKey returns candidate public key of the validator
+func (v *Validator) PubKey() types.Pubkey {
+	pubKey := v.PrivKey.PubKey().(ed25519.PubKeyEd25519)
+	return pubKey[:]
+}
+
+// Address returns Tendermint address of the validator
+func (v *Validator) Address() []byte {
+	return v.PrivKey.PubKey().Address()
+}
+
+// OwnerAddress returns address of the validator owner account
+func (v *Validator) OwnerAddress() types.Address {
+	return crypto.PubkeyToAddress(v.Owner.PublicKey)
+}
+
+// SignTx builds a transaction of given type from owner account and returns its encoded form
+func (v *Validator) SignTx(txType transaction.TxType, data interface{}) ([]byte, error) {
+	encodedData, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Nonce++
+	tx := transaction.Transaction{
+		Nonce:         v.Nonce,
+		ChainID:       types.CurrentChainID,
+		GasPrice:      1,
+		GasCoin:       types.GetBaseCoin(),
+		Type:          txType,
+		Data:          encodedData,
+		SignatureType: transaction.SigTypeSingle,
+	}
+
+	if err := tx.Sign(v.Owner); err != nil {
+		return nil, err
+	}
+
+	return tx.Serialize()
+}
+
+// Block describes a block which is applied to all instances. Absent and Byzantine hold indexes in
+// Network.Validators of validators which did not sign the previous block and which are accused with evidence.
+type Block struct {
+	Txs       [][]byte
+	Absent    []int
+	Byzantine []int
+}
+
+// BlockResult is a result of a block, identical on all instances
+type BlockResult struct {
+	Height           int64
+	AppHash          []byte
+	DeliverTxs       []abciTypes.ResponseDeliverTx
+	ValidatorUpdates []abciTypes.ValidatorUpdate
+}
+
+// Network is a set of independent Blockchain instances with in-memory databases
+type Network struct {
+	Apps       []*minter.Blockchain
+	Validators []*Validator
+
+	// validator sets by height, updates returned by EndBlock at height H are applied at H+2
+	validatorSets map[int64][]abciTypes.Validator
+
+	height int64
+	time   time.Time
+	home   string
+}
+
+// NewNetwork creates instances and initializes them with a genesis where each of validators has an account with
+// balance and a stake in base coin. Amounts are in BIP.
+func NewNetwork(instances int, validators int, balance string, stake string) (*Network, error) {
+	if instances < 1 || validators < 1 {
+		return nil, errors.New("at least one instance and one validator are required")
+	}
+
+	home, err := ioutil.TempDir("", "minter-harness")
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Network{
+		validatorSets: map[int64][]abciTypes.Validator{},
+		time:          time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+		home:          home,
+	}
+
+	var input genesis.Input
+	for i := 0; i < validators; i++ {
+		validator, err := n.NewValidator()
+		if err != nil {
+			return nil, err
+		}
+
+		input.Accounts = append(input.Accounts, genesis.Account{
+			Address: validator.OwnerAddress().String(),
+			Balance: []genesis.Balance{{Coin: types.GetBaseCoin().String(), Value: balance}},
+		})
+
+		input.Candidates = append(input.Candidates, genesis.Candidate{
+			PubKey:       validator.PubKey().String(),
+			OwnerAddress: validator.OwnerAddress().String(),
+			Commission:   10,
+			Stakes: []genesis.Stake{{
+				Owner: validator.OwnerAddress().String(),
+				Coin:  types.GetBaseCoin().String(),
+				Value: stake,
+			}},
+		})
+	}
+
+	appState, err := genesis.Build(input)
+	if err != nil {
+		return nil, err
+	}
+
+	appStateBytes, err := amino.MarshalJSON(appState)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.SetRoot(home)
+	cfg.LogLevel = "*:error"
+	log.InitLog(cfg)
+
+	// genesis file is read by periodical invariants check
+	genesisDoc := tmTypes.GenesisDoc{
+		GenesisTime: n.time,
+		ChainID:     chainID,
+		AppState:    appStateBytes,
+	}
+	if err := genesisDoc.ValidateAndComplete(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.GenesisFile()), 0700); err != nil {
+		return nil, err
+	}
+	if err := genesisDoc.SaveAs(cfg.GenesisFile()); err != nil {
+		return nil, err
+	}
+
+	var initialSet []abciTypes.Validator
+	for i := 0; i < instances; i++ {
+		app := minter.NewMinterBlockchainWithDB(cfg, db.NewMemDB(), appdb.NewAppDBWithDB(db.NewMemDB()),
+			eventsdb.NewEventsDB(db.NewMemDB()))
+
+		response := app.InitChain(abciTypes.RequestInitChain{
+			Time:          n.time,
+			ChainId:       chainID,
+			AppStateBytes: appStateBytes,
+		})
+
+		set := n.applyUpdates(nil, response.Validators)
+		if i > 0 && !equalValidatorSets(initialSet, set) {
+			return nil, errors.New("instances returned different genesis validators")
+		}
+
+		initialSet = set
+		n.Apps = append(n.Apps, app)
+	}
+
+	n.validatorSets[1] = initialSet
+	n.validatorSets[2] = initialSet
+
+	return n, nil
+}
+
+// NewValidator generates keys of a new validator, which can be declared as a candidate with a transaction
+func (n *Network) NewValidator() (*Validator, error) {
+	owner, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	validator := &Validator{
+		PrivKey: ed25519.GenPrivKey(),
+		Owner:   owner,
+	}
+	n.Validators = append(n.Validators, validator)
+
+	return validator, nil
+}
+
+// Height returns height of the last applied block
+func (n *Network) Height() int64 {
+	return n.height
+}
+
+// ValidatorSet returns validators which sign block at given height
+func (n *Network) ValidatorSet(height int64) []abciTypes.Validator {
+	return n.validatorSets[height]
+}
+
+// Close removes temporary files of the network
+func (n *Network) Close() error {
+	return os.RemoveAll(n.home)
+}
+
+// NextBlock applies a block to all instances and checks that they produce identical results
+func (n *Network) NextBlock(block Block) (*BlockResult, error) {
+	height := n.height + 1
+	n.time = n.time.Add(blockInterval)
+
+	absent := map[string]bool{}
+	for _, i := range block.Absent {
+		absent[string(n.Validators[i].Address())] = true
+	}
+
+	var votes []abciTypes.VoteInfo
+	for _, validator := range n.validatorSets[height-1] {
+		votes = append(votes, abciTypes.VoteInfo{
+			Validator:       validator,
+			SignedLastBlock: !absent[string(validator.Address)],
+		})
+	}
+
+	var totalPower int64
+	for _, validator := range n.validatorSets[height] {
+		totalPower += validator.Power
+	}
+
+	var evidence []abciTypes.Evidence
+	for _, i := range block.Byzantine {
+		evidence = append(evidence, abciTypes.Evidence{
+			Type:             tmTypes.ABCIEvidenceTypeDuplicateVote,
+			Validator:        abciTypes.Validator{Address: n.Validators[i].Address(), Power: n.power(height, i)},
+			Height:           height - 1,
+			Time:             n.time.Add(-blockInterval),
+			TotalVotingPower: totalPower,
+		})
+	}
+
+	beginBlock := abciTypes.RequestBeginBlock{
+		Header: abciTypes.Header{
+			ChainID: chainID,
+			Height:  height,
+			Time:    n.time,
+			NumTxs:  int64(len(block.Txs)),
+		},
+		LastCommitInfo:      abciTypes.LastCommitInfo{Votes: votes},
+		ByzantineValidators: evidence,
+	}
+
+	var result *BlockResult
+	for i, app := range n.Apps {
+		appResult := &BlockResult{Height: height}
+
+		app.BeginBlock(beginBlock)
+		for _, tx := range block.Txs {
+			appResult.DeliverTxs = append(appResult.DeliverTxs, app.DeliverTx(tx))
+		}
+		appResult.ValidatorUpdates = app.EndBlock(abciTypes.RequestEndBlock{Height: height}).ValidatorUpdates
+		appResult.AppHash = app.Commit().Data
+
+		if i == 0 {
+			result = appResult
+			continue
+		}
+
+		if err := compareResults(result, appResult); err != nil {
+			return result, fmt.Errorf("instance %d diverged at height %d: %s", i, height, err)
+		}
+	}
+
+	n.height = height
+	n.validatorSets[height+2] = n.applyUpdates(n.validatorSets[height+1], result.ValidatorUpdates)
+	delete(n.validatorSets, height-2)
+
+	return result, nil
+}
+
+// NextBlocks applies given number of empty blocks, all validators sign them
+func (n *Network) NextBlocks(count int) error {
+	for i := 0; i < count; i++ {
+		if _, err := n.NextBlock(Block{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *Network) power(height int64, validator int) int64 {
+	for _, v := range n.validatorSets[height] {
+		if bytes.Equal(v.Address, n.Validators[validator].Address()) {
+			return v.Power
+		}
+	}
+
+	return 0
+}
+
+func (n *Network) applyUpdates(set []abciTypes.Validator, updates []abciTypes.ValidatorUpdate) []abciTypes.Validator {
+	powers := map[string]int64{}
+	var order []string
+
+	for _, v := range set {
+		powers[string(v.Address)] = v.Power
+		order = append(order, string(v.Address))
+	}
+
+	for _, update := range updates {
+		var pubKey ed25519.PubKeyEd25519
+		copy(pubKey[:], update.PubKey.Data)
+		address := string(pubKey.Address())
+
+		if _, exists := powers[address]; !exists {
+			order = append(order, address)
+		}
+		powers[address] = update.Power
+	}
+
+	var result []abciTypes.Validator
+	for _, address := range order {
+		if powers[address] == 0 {
+			continue
+		}
+
+		result = append(result, abciTypes.Validator{Address: []byte(address), Power: powers[address]})
+	}
+
+	return result
+}
+
+func compareResults(expected *BlockResult, actual *BlockResult) error {
+	if len(expected.DeliverTxs) != len(actual.DeliverTxs) {
+		return errors.New("different number of delivered txs")
+	}
+
+	for i := range expected.DeliverTxs {
+		if expected.DeliverTxs[i].Code != actual.DeliverTxs[i].Code {
+			return fmt.Errorf("tx %d: code %d, expected %d", i, actual.DeliverTxs[i].Code, expected.DeliverTxs[i].Code)
+		}
+	}
+
+	if len(expected.ValidatorUpdates) != len(actual.ValidatorUpdates) {
+		return errors.New("different validator updates")
+	}
+
+	for i := range expected.ValidatorUpdates {
+		if !expected.ValidatorUpdates[i].Equal(actual.ValidatorUpdates[i]) {
+			return errors.New("different validator updates")
+		}
+	}
+
+	if !bytes.Equal(expected.AppHash, actual.AppHash) {
+		return fmt.Errorf("app hash %X, expected %X", actual.AppHash, expected.AppHash)
+	}
+
+	return nil
+}
+
+func equalValidatorSets(a []abciTypes.Validator, b []abciTypes.Validator) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !bytes.Equal(a[i].Address, b[i].Address) || a[i].Power != b[i].Power {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,173 @@
+package harness
+
+import (
+	"bytes"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/transaction"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"math/big"
+	"testing"
+)
+
+func newNetwork(t *testing.T) *Network {
+	n, err := NewNetwork(3, 4, "1000000", "100000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
+
+func findUpdate(updates []abciTypes.ValidatorUpdate, validator *Validator) *abciTypes.ValidatorUpdate {
+	for i := range updates {
+		if bytes.Equal(updates[i].PubKey.Data, validator.PubKey()) {
+			return &updates[i]
+		}
+	}
+
+	return nil
+}
+
+func TestIdenticalAppHashes(t *testing.T) {
+	n := newNetwork(t)
+	defer n.Close()
+
+	sender := n.Validators[0]
+	tx, err := sender.SignTx(transaction.TypeSend, transaction.SendData{
+		Coin:  types.GetBaseCoin(),
+		To:    n.Validators[1].OwnerAddress(),
+		Value: helpers.BipToPip(big.NewInt(10)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := n.NextBlock(Block{Txs: [][]byte{tx}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.DeliverTxs[0].Code != 0 {
+		t.Fatalf("Tx should be delivered, got code %d: %s", result.DeliverTxs[0].Code, result.DeliverTxs[0].Log)
+	}
+
+	// rewards are paid every 12 blocks
+	if err := n.NextBlocks(24); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAbsentValidator(t *testing.T) {
+	n := newNetwork(t)
+	defer n.Close()
+
+	if err := n.NextBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := uint64(0); i <= n.Apps[0].CurrentState().GetChainParams().ValidatorMaxAbsentTimes; i++ {
+		result, err := n.NextBlock(Block{Absent: []int{0}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if update := findUpdate(result.ValidatorUpdates, n.Validators[0]); update != nil && update.Power == 0 {
+			candidate := n.Apps[0].CurrentState().GetStateCandidate(n.Validators[0].PubKey())
+			if candidate.Status != state.CandidateStatusOffline {
+				t.Fatalf("Absent candidate should be offline")
+			}
+
+			return
+		}
+	}
+
+	t.Fatalf("Absent validator should be removed from validators set")
+}
+
+func TestByzantineValidator(t *testing.T) {
+	n := newNetwork(t)
+	defer n.Close()
+
+	if err := n.NextBlocks(1); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := n.NextBlock(Block{Byzantine: []int{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if update := findUpdate(result.ValidatorUpdates, n.Validators[1]); update == nil || update.Power != 0 {
+		t.Fatalf("Byzantine validator should be removed from validators set")
+	}
+
+	if err := n.NextBlocks(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(n.ValidatorSet(n.Height())) != 3 {
+		t.Fatalf("Validators set should contain 3 validators, got %d", len(n.ValidatorSet(n.Height())))
+	}
+}
+
+func TestValidatorSetChange(t *testing.T) {
+	n := newNetwork(t)
+	defer n.Close()
+
+	owner := n.Validators[0]
+	newValidator, err := n.NewValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	declare, err := owner.SignTx(transaction.TypeDeclareCandidacy, transaction.DeclareCandidacyData{
+		Address:    owner.OwnerAddress(),
+		PubKey:     newValidator.PubKey(),
+		Commission: 10,
+		Coin:       types.GetBaseCoin(),
+		Stake:      helpers.BipToPip(big.NewInt(1000)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setOnline, err := owner.SignTx(transaction.TypeSetCandidateOnline, transaction.SetCandidateOnData{
+		PubKey: newValidator.PubKey(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := n.NextBlock(Block{Txs: [][]byte{declare, setOnline}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, deliverTx := range result.DeliverTxs {
+		if deliverTx.Code != 0 {
+			t.Fatalf("Tx %d should be delivered, got code %d: %s", i, deliverTx.Code, deliverTx.Log)
+		}
+	}
+
+	// validators are updated every 120 blocks
+	for n.Height() < 120 {
+		result, err = n.NextBlock(Block{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if update := findUpdate(result.ValidatorUpdates, newValidator); update == nil || update.Power == 0 {
+		t.Fatalf("New validator should be added to validators set")
+	}
+
+	if err := n.NextBlocks(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(n.ValidatorSet(n.Height())) != 5 {
+		t.Fatalf("Validators set should contain 5 validators, got %d", len(n.ValidatorSet(n.Height())))
+	}
+}
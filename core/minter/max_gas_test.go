@@ -0,0 +1,48 @@
+package minter
+
+import (
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"testing"
+)
+
+func TestMaxGasHistory(t *testing.T) {
+	app := newInMemoryBlockchain()
+	app.InitChain(abciTypes.RequestInitChain{AppStateBytes: newTestAppState(t)})
+
+	params := app.ChainParams()
+	params.MaxGasTargetBlockTime = 5
+	params.MaxGasWindow = 2
+	params.MaxGasDecreasePercent = 50
+	params.MaxGasIncreasePercent = 10
+	params.MinMaxGas = 1000
+	params.MaxMaxGas = 20000
+	app.setChainParams(params)
+
+	for height := int64(1); height <= 23; height++ {
+		// blocks before height 22 took 11 seconds while target is 10 seconds for 2 blocks
+		if height == 22 {
+			app.SetBlocksTimeDelta(uint64(height), 11)
+		}
+
+		app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: height}})
+		app.EndBlock(abciTypes.RequestEndBlock{Height: height})
+		app.Commit()
+	}
+
+	history := app.GetMaxGasHistory(21, 30)
+	expected := []uint64{20000, 10000, 11000}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(history))
+	}
+
+	for i, record := range history {
+		if record.Height != uint64(21+i) || record.MaxGas != expected[i] {
+			t.Fatalf("Max gas at height %d should be %d, got %d at height %d", 21+i, expected[i], record.MaxGas,
+				record.Height)
+		}
+	}
+
+	if history[1].BlocksTimeDelta != 11 {
+		t.Fatalf("Blocks time delta at height 22 should be 11, got %d", history[1].BlocksTimeDelta)
+	}
+}
@@ -0,0 +1,94 @@
+package minter
+
+import (
+	"bytes"
+	"github.com/MinterTeam/go-amino"
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/eventsdb"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/db"
+	"math/big"
+	"testing"
+)
+
+func newInMemoryBlockchain() *Blockchain {
+	return NewMinterBlockchainWithDB(config.DefaultConfig(), db.NewMemDB(), appdb.NewAppDBWithDB(db.NewMemDB()),
+		eventsdb.NewEventsDB(db.NewMemDB()))
+}
+
+// newTestAppState returns genesis app state with a single validator
+func newTestAppState(t *testing.T) []byte {
+	return newTestAppStateWithParams(t, nil)
+}
+
+// newTestAppStateWithParams returns genesis app state with a single validator and given chain params
+func newTestAppStateWithParams(t *testing.T, params *types.ChainParams) []byte {
+	address := types.HexToAddress("Mx02003587993aba5276925c058ba082d209e61cbb")
+	pubkey := make([]byte, 32)
+	stake := helpers.BipToPip(big.NewInt(1000))
+
+	appState, err := amino.MarshalJSON(types.AppState{
+		Validators: []types.Validator{{
+			RewardAddress: address,
+			TotalBipStake: stake,
+			PubKey:        pubkey,
+			Commission:    10,
+			AccumReward:   big.NewInt(0),
+			AbsentTimes:   types.NewBitArray(int(types.DefaultChainParams().ValidatorMaxAbsentWindow)),
+		}},
+		Candidates: []types.Candidate{{
+			RewardAddress: address,
+			OwnerAddress:  address,
+			TotalBipStake: stake,
+			PubKey:        pubkey,
+			Commission:    10,
+			Stakes: []types.Stake{{
+				Owner:    address,
+				Coin:     types.GetBaseCoin(),
+				Value:    stake,
+				BipValue: stake,
+			}},
+			Status: state.CandidateStatusOnline,
+		}},
+		MaxGas:       types.DefaultChainParams().MaxMaxGas,
+		TotalSlashed: big.NewInt(0),
+		ChainParams:  params,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return appState
+}
+
+func TestInMemoryBlockchains(t *testing.T) {
+	appState := newTestAppState(t)
+
+	apps := []*Blockchain{newInMemoryBlockchain(), newInMemoryBlockchain()}
+	for _, app := range apps {
+		app.InitChain(abciTypes.RequestInitChain{AppStateBytes: appState})
+	}
+
+	for height := int64(1); height <= 3; height++ {
+		var hashes [][]byte
+		for _, app := range apps {
+			app.BeginBlock(abciTypes.RequestBeginBlock{Header: abciTypes.Header{Height: height}})
+			app.EndBlock(abciTypes.RequestEndBlock{Height: height})
+			hashes = append(hashes, app.Commit().Data)
+		}
+
+		if !bytes.Equal(hashes[0], hashes[1]) {
+			t.Fatalf("App hashes at height %d differ: %X and %X", height, hashes[0], hashes[1])
+		}
+	}
+
+	for _, app := range apps {
+		if app.appDB.GetLastHeight() != 3 {
+			t.Fatalf("Last height should be 3, got %d", app.appDB.GetLastHeight())
+		}
+	}
+}
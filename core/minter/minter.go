@@ -2,11 +2,15 @@ package minter
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/MinterTeam/go-amino"
 	"github.com/MinterTeam/minter-go-node/cmd/utils"
 	"github.com/MinterTeam/minter-go-node/config"
 	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/mempool"
 	"github.com/MinterTeam/minter-go-node/core/rewards"
+	"github.com/MinterTeam/minter-go-node/core/snapshot"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/transaction"
 	"github.com/MinterTeam/minter-go-node/core/types"
@@ -14,6 +18,7 @@ import (
 	"github.com/MinterTeam/minter-go-node/eventsdb"
 	"github.com/MinterTeam/minter-go-node/eventsdb/events"
 	"github.com/MinterTeam/minter-go-node/log"
+	"github.com/MinterTeam/minter-go-node/upgrades"
 	"github.com/MinterTeam/minter-go-node/version"
 	"github.com/danil-lashin/tendermint/rpc/lib/types"
 	abciTypes "github.com/tendermint/tendermint/abci/types"
@@ -21,6 +26,7 @@ import (
 	"github.com/tendermint/tendermint/crypto/encoding/amino"
 	"github.com/tendermint/tendermint/libs/db"
 	tmNode "github.com/tendermint/tendermint/node"
+	sm "github.com/tendermint/tendermint/state"
 	types2 "github.com/tendermint/tendermint/types"
 	"math/big"
 	"sync"
@@ -33,9 +39,6 @@ const (
 	ValidatorAbsent  = 2
 
 	BlockMaxBytes = 10000000
-
-	DefaultMaxGas = 100000
-	MinMaxGas     = 5000
 )
 
 var (
@@ -48,6 +51,7 @@ type Blockchain struct {
 
 	stateDB             db.DB
 	appDB               *appdb.AppDB
+	eventsDB            eventsdb.IEventsDB
 	stateDeliver        *state.StateDB
 	stateCheck          *state.StateDB
 	height              uint64 // current Blockchain height
@@ -58,9 +62,44 @@ type Blockchain struct {
 
 	// local rpc client for Tendermint
 	tmNode *tmNode.Node
+	// tmStateDB is Tendermint's state db, Tendermint's state is read from it to take snapshots
+	tmStateDB db.DB
+
+	// snapshots is responsible for periodical snapshots of the state
+	snapshots *snapshot.Manager
+	// pendingSnapshot is a snapshot of the last committed block, it's taken as soon as Tendermint saves its state
+	pendingSnapshot *snapshot.Source
+	// genesisFile is a path to genesis of the chain, it's included into snapshots and used by invariants check
+	genesisFile string
+
+	haltOnInvariantsViolation bool
+
+	// haltHeight is set by halt_height config option, the node stops after committing a block at this height
+	haltHeight uint64
+	// haltReason is set if the node should stop after committing the current block
+	haltReason string
+	// halted is closed when the node is halted
+	halted chan struct{}
+
+	// chainParams are set in genesis and stored in state, they are changed only by governance proposals
+	chainParams types.ChainParams
+
+	// blockRewardsKeepRecent is a number of recent blocks which reward distribution is kept for
+	blockRewardsKeepRecent uint64
+
+	// gasPriceCurve sets minimal gas price of txs accepted to mempool
+	gasPriceCurve gasPriceCurve
+	// gasUsage keeps gas usage of recent blocks to recommend gas price
+	gasUsage *gasUsageHistory
+
+	// divergence replays delivered txs on check states if debug_check_divergence is enabled
+	divergence *divergenceDetector
 
 	// currentMempool is responsive for prevent sending multiple transactions from one address in one block
 	currentMempool sync.Map
+	// priorities admits txs to full mempool by their gas price and evicts the lowest priority ones. Evicted txs are
+	// dropped by mempool recheck, so it's nil if recheck is disabled.
+	priorities *mempool.Pool
 
 	lock    sync.RWMutex
 	wg      sync.WaitGroup // wg is used for graceful node shutdown
@@ -75,28 +114,71 @@ func NewMinterBlockchain(cfg *config.Config) *Blockchain {
 	// Initiate Application DB. Used for persisting data like current block, validators, etc.
 	applicationDB := appdb.NewAppDB(cfg)
 
-	blockchain = &Blockchain{
-		stateDB:             ldb,
+	blockchain = NewMinterBlockchainWithDB(cfg, ldb, applicationDB, eventsdb.Open(cfg))
+
+	return blockchain
+}
+
+// Creates Minter Blockchain instance on top of given databases. Does not touch Minter home directory,
+// so several independent instances with in-memory databases can run in one process.
+func NewMinterBlockchainWithDB(cfg *config.Config, stateDB db.DB, applicationDB *appdb.AppDB,
+	eventsDB eventsdb.IEventsDB) *Blockchain {
+	thresholds, err := ParseGasPriceThresholds(cfg.MinGasPriceThresholds)
+	if err != nil {
+		panic(err)
+	}
+
+	app := &Blockchain{
+		stateDB:             stateDB,
 		appDB:               applicationDB,
+		eventsDB:            eventsDB,
 		height:              applicationDB.GetLastHeight(),
 		lastCommittedHeight: applicationDB.GetLastHeight(),
 		currentMempool:      sync.Map{},
+		snapshots:           snapshot.NewManager(cfg.SnapshotDir(), cfg.SnapshotInterval, cfg.SnapshotKeepRecent),
+		genesisFile:         cfg.GenesisFile(),
+
+		haltOnInvariantsViolation: cfg.HaltOnInvariantsViolation,
+		haltHeight:                cfg.HaltHeight,
+		halted:                    make(chan struct{}),
+
+		blockRewardsKeepRecent: cfg.BlockRewardsKeepRecent,
+
+		gasPriceCurve: gasPriceCurve{staticMin: cfg.MinGasPrice, thresholds: thresholds},
+		gasUsage:      newGasUsageHistory(),
+	}
+
+	if cfg.Mempool.Recheck {
+		metrics := mempool.NopMetrics()
+		if cfg.Instrumentation.Prometheus {
+			metrics = mempool.PrometheusMetrics(cfg.Instrumentation.Namespace)
+		}
+
+		app.priorities = mempool.NewPool(cfg.Mempool.Size, metrics)
+	} else {
+		log.With("module", "mempool").Info("Mempool recheck is disabled, txs are not prioritized by gas price")
 	}
 
 	// Set stateDeliver and stateCheck
-	var err error
-	blockchain.stateDeliver, err = state.New(blockchain.height, blockchain.stateDB, cfg.KeepStateHistory)
+	app.stateDeliver, err = state.New(app.height, app.stateDB, cfg.KeepStateHistory)
 	if err != nil {
 		panic(err)
 	}
+	app.stateDeliver.SetEventsDB(app.eventsDB)
+
+	app.stateCheck = state.NewForCheckFromDeliver(app.stateDeliver)
+
+	if cfg.DebugCheckDivergence {
+		app.divergence = newDivergenceDetector()
+	}
 
-	blockchain.stateCheck = state.NewForCheckFromDeliver(blockchain.stateDeliver)
+	app.setChainParams(app.stateDeliver.GetChainParams())
 
 	// Set start height for rewards and validators
 	rewards.SetStartHeight(applicationDB.GetStartHeight())
 	validators.SetStartHeight(applicationDB.GetStartHeight())
 
-	return blockchain
+	return app
 }
 
 // Initialize blockchain with validators and other info. Only called once.
@@ -106,7 +188,14 @@ func (app *Blockchain) InitChain(req abciTypes.RequestInitChain) abciTypes.Respo
 		panic(err)
 	}
 
+	if genesisState.ChainParams != nil {
+		if err := genesisState.ChainParams.Validate(); err != nil {
+			panic(err)
+		}
+	}
+
 	app.stateDeliver.Import(genesisState)
+	app.setChainParams(app.stateDeliver.GetChainParams())
 
 	totalPower := big.NewInt(0)
 	for _, val := range genesisState.Validators {
@@ -124,8 +213,7 @@ func (app *Blockchain) InitChain(req abciTypes.RequestInitChain) abciTypes.Respo
 
 		vals[i] = abciTypes.ValidatorUpdate{
 			PubKey: types2.TM2PB.PubKey(pkey),
-			Power: big.NewInt(0).Div(big.NewInt(0).Mul(val.TotalBipStake,
-				big.NewInt(100000000)), totalPower).Int64(),
+			Power:  validators.GetValidatorPower(val.TotalBipStake, totalPower),
 		}
 	}
 
@@ -146,18 +234,23 @@ func (app *Blockchain) BeginBlock(req abciTypes.RequestBeginBlock) abciTypes.Res
 		panic("Application stopped")
 	}
 
+	// Tendermint's state of the previous block is saved by now, so its snapshot can be taken
+	if app.pendingSnapshot != nil {
+		app.takePendingSnapshot()
+	}
+
 	height := uint64(req.Header.Height)
 	// Check invariants
-	if height%720 == 0 {
-		if err := state.NewForCheckFromDeliver(app.stateCheck).CheckForInvariants(); err != nil {
-			log.With("module", "invariants").Error("Invariants error", "msg", err.Error(), "height", app.height)
-		}
+	if height%app.chainParams.CheckInvariantsInterval == 0 {
+		app.checkInvariants()
 	}
 
 	// compute max gas
-	app.updateBlocksTimeDelta(height, 3)
+	app.updateBlocksTimeDelta(height, int64(app.chainParams.MaxGasWindow))
 	maxGas := app.calcMaxGas(height)
 	app.stateDeliver.SetMaxGas(maxGas)
+	app.saveMaxGas(height, maxGas)
+	app.gasUsage.beginBlock(maxGas)
 
 	atomic.StoreUint64(&app.height, height)
 	app.rewards = big.NewInt(0)
@@ -190,7 +283,7 @@ func (app *Blockchain) BeginBlock(req abciTypes.RequestBeginBlock) abciTypes.Res
 			continue
 		}
 
-		app.stateDeliver.PunishFrozenFundsWithAddress(height, height+state.UnbondPeriod, address)
+		app.stateDeliver.PunishFrozenFundsWithAddress(height, height+app.chainParams.UnbondPeriod, address)
 		app.stateDeliver.PunishByzantineValidator(address)
 	}
 
@@ -198,7 +291,7 @@ func (app *Blockchain) BeginBlock(req abciTypes.RequestBeginBlock) abciTypes.Res
 	frozenFunds := app.stateDeliver.GetStateFrozenFunds(uint64(req.Header.Height))
 	if frozenFunds != nil {
 		for _, item := range frozenFunds.List() {
-			eventsdb.GetCurrent().AddEvent(uint64(req.Header.Height), events.UnbondEvent{
+			app.eventsDB.AddEvent(uint64(req.Header.Height), events.UnbondEvent{
 				Address:         item.Address,
 				Amount:          item.Value.Bytes(),
 				Coin:            item.Coin,
@@ -211,6 +304,10 @@ func (app *Blockchain) BeginBlock(req abciTypes.RequestBeginBlock) abciTypes.Res
 		frozenFunds.Delete()
 	}
 
+	if app.divergence != nil {
+		app.divergence.beginBlock(height, app.stateDeliver)
+	}
+
 	return abciTypes.ResponseBeginBlock{}
 }
 
@@ -218,6 +315,8 @@ func (app *Blockchain) BeginBlock(req abciTypes.RequestBeginBlock) abciTypes.Res
 func (app *Blockchain) EndBlock(req abciTypes.RequestEndBlock) abciTypes.ResponseEndBlock {
 	height := uint64(req.Height)
 
+	app.gasUsage.endBlock()
+
 	var updates []abciTypes.ValidatorUpdate
 
 	stateValidators := app.stateDeliver.GetStateValidators()
@@ -251,15 +350,29 @@ func (app *Blockchain) EndBlock(req abciTypes.RequestEndBlock) abciTypes.Respons
 	}
 
 	// accumulate rewards
-	reward := rewards.GetRewardForBlock(height)
+	reward := rewards.GetRewardForBlock(app.chainParams, height)
 	reward.Add(reward, app.rewards)
 
 	// compute remainder to keep total emission consist
 	remainder := big.NewInt(0).Set(reward)
 
+	blockRewards := appdb.BlockRewards{
+		Height: height,
+		Reward: reward.String(),
+	}
+
 	for i, val := range vals {
+		validatorReward := appdb.ValidatorReward{
+			PubKey:        val.PubKey,
+			RewardAddress: val.RewardAddress,
+			Status:        app.validatorRewardStatus(val),
+			Stake:         val.TotalBipStake.String(),
+			Reward:        "0",
+		}
+
 		// skip if candidate is not present
 		if val.IsToDrop() || app.validatorsStatuses[val.GetAddress()] != ValidatorPresent {
+			blockRewards.Validators = append(blockRewards.Validators, validatorReward)
 			continue
 		}
 
@@ -269,27 +382,50 @@ func (app *Blockchain) EndBlock(req abciTypes.RequestEndBlock) abciTypes.Respons
 
 		remainder.Sub(remainder, r)
 		vals[i].AccumReward.Add(vals[i].AccumReward, r)
+
+		validatorReward.Reward = r.String()
+		blockRewards.Validators = append(blockRewards.Validators, validatorReward)
 	}
 
 	// add remainder to total slashed
 	app.stateDeliver.AddTotalSlashed(remainder)
 
+	blockRewards.Remainder = remainder.String()
+	app.saveBlockRewards(blockRewards)
+
 	stateValidators.SetData(vals)
 	app.stateDeliver.SetStateValidators(stateValidators)
 
 	// pay rewards
-	if req.Height%12 == 0 {
+	if height%app.chainParams.PayRewardsInterval == 0 {
 		app.stateDeliver.PayRewards()
 	}
 
+	// finish voting for governance proposals and apply passed ones
+	app.stateDeliver.TallyProposals()
+	for _, proposal := range app.stateDeliver.ApplyProposals() {
+		switch proposal.Type {
+		case state.ProposalTypeParamChange:
+			app.setChainParams(app.stateDeliver.GetChainParams())
+		case state.ProposalTypeUpgrade:
+			if !upgrades.IsUpgradeSupported(proposal.Param) {
+				app.haltReason = fmt.Sprintf("upgrade %s approved by proposal %d", proposal.Param, proposal.ID)
+			}
+		}
+	}
+
+	if app.haltHeight != 0 && height >= app.haltHeight {
+		app.haltReason = "halt_height config option"
+	}
+
 	// update validators
-	if req.Height%120 == 0 || hasDroppedValidators {
+	if height%app.chainParams.UpdateValidatorsInterval == 0 || hasDroppedValidators {
 		app.stateDeliver.RecalculateTotalStakeValues()
 
 		app.stateDeliver.ClearCandidates()
 		app.stateDeliver.ClearStakes()
 
-		valsCount := validators.GetValidatorsCountForBlock(height)
+		valsCount := validators.ValidatorsCountForBlock(app.chainParams, height)
 
 		newCandidates := app.stateDeliver.GetCandidates(valsCount, req.Height)
 
@@ -317,8 +453,7 @@ func (app *Blockchain) EndBlock(req abciTypes.RequestEndBlock) abciTypes.Respons
 		}
 
 		for i := range newCandidates {
-			power := big.NewInt(0).Div(big.NewInt(0).Mul(newCandidates[i].TotalBipStake,
-				big.NewInt(100000000)), totalPower).Int64()
+			power := validators.GetValidatorPower(newCandidates[i].TotalBipStake, totalPower)
 
 			if power == 0 {
 				power = 1
@@ -379,6 +514,15 @@ func (app *Blockchain) Info(req abciTypes.RequestInfo) (resInfo abciTypes.Respon
 // Deliver a tx for full processing
 func (app *Blockchain) DeliverTx(rawTx []byte) abciTypes.ResponseDeliverTx {
 	response := transaction.RunTx(app.stateDeliver, false, rawTx, app.rewards, app.height, sync.Map{}, 0)
+	app.priorities.Remove(rawTx)
+
+	if app.divergence != nil {
+		app.divergence.deliverTx(rawTx, response, app.stateDeliver)
+	}
+
+	if response.Code == code.OK {
+		app.gasUsage.deliverTx(uint64(response.GasUsed), response.GasPrice)
+	}
 
 	return abciTypes.ResponseDeliverTx{
 		Code:      response.Code,
@@ -393,8 +537,27 @@ func (app *Blockchain) DeliverTx(rawTx []byte) abciTypes.ResponseDeliverTx {
 
 // Validate a tx for the mempool
 func (app *Blockchain) CheckTx(rawTx []byte) abciTypes.ResponseCheckTx {
+	if err := app.priorities.Check(rawTx); err != nil {
+		responseCode := code.TxLowPriority
+		if err == mempool.ErrEvicted {
+			responseCode = code.TxEvicted
+		}
+
+		return abciTypes.ResponseCheckTx{
+			Code: responseCode,
+			Log:  err.Error(),
+		}
+	}
+
 	response := transaction.RunTx(app.stateCheck, true, rawTx, nil, app.height, app.currentMempool, app.MinGasPrice())
 
+	// txs rejected by recheck are dropped from mempool
+	if response.Code == code.OK {
+		app.priorities.Add(rawTx)
+	} else {
+		app.priorities.Remove(rawTx)
+	}
+
 	return abciTypes.ResponseCheckTx{
 		Code:      response.Code,
 		Data:      response.Data,
@@ -415,12 +578,17 @@ func (app *Blockchain) Commit() abciTypes.ResponseCommit {
 	}
 
 	// Flush events db
-	_ = eventsdb.GetCurrent().FlushEvents()
+	_ = app.eventsDB.FlushEvents()
 
 	// Persist application hash and height
 	app.appDB.SetLastBlockHash(hash)
 	app.appDB.SetLastHeight(app.height)
 
+	// Prepare a snapshot of the state if needed
+	if app.snapshots.ShouldTake(app.height) {
+		app.prepareSnapshot(hash)
+	}
+
 	// Resetting check state to be consistent with current height
 	app.resetCheckState()
 
@@ -429,10 +597,16 @@ func (app *Blockchain) Commit() abciTypes.ResponseCommit {
 
 	// Clear mempool
 	app.currentMempool = sync.Map{}
+	app.priorities.Commit()
 
 	// Releasing wg
 	app.wg.Done()
 
+	// Halt the node if needed, committed state is persisted by now
+	if app.haltReason != "" {
+		app.halt()
+	}
+
 	return abciTypes.ResponseCommit{
 		Data: hash,
 	}
@@ -448,15 +622,91 @@ func (app *Blockchain) SetOption(req abciTypes.RequestSetOption) abciTypes.Respo
 	return abciTypes.ResponseSetOption{}
 }
 
+// halt signals that the node should be stopped. The app keeps processing blocks until Stop is called after
+// Tendermint node is stopped, otherwise blocks which Tendermint delivers meanwhile would panic.
+func (app *Blockchain) halt() {
+	select {
+	case <-app.halted:
+		return
+	default:
+	}
+
+	log.Info("Halting node", "height", app.Height(), "reason", app.haltReason)
+	close(app.halted)
+}
+
+// Halted returns a channel which is closed when the node is halted after committing a block at halt height. The
+// node should be stopped with Stop afterwards.
+func (app *Blockchain) Halted() <-chan struct{} {
+	return app.halted
+}
+
+// PendingHalt returns the nearest height at which the node is going to be halted and a reason of the halt. Height
+// is 0 if no halt is scheduled.
+func (app *Blockchain) PendingHalt() (uint64, string) {
+	var height uint64
+	var reason string
+
+	if app.haltHeight > app.Height() {
+		height, reason = app.haltHeight, "halt_height config option"
+	}
+
+	committed, err := app.committedState()
+	if err != nil {
+		return height, reason
+	}
+
+	upgrade := committed.GetPendingUpgrade()
+	if upgrade != nil && !upgrades.IsUpgradeSupported(upgrade.Param) && (height == 0 || upgrade.Height < height) {
+		height = upgrade.Height
+		reason = fmt.Sprintf("upgrade %s approved by proposal %d", upgrade.Param, upgrade.ID)
+	}
+
+	return height, reason
+}
+
+// UnsupportedUpgrade returns an upgrade which is applied to the committed state, but is not supported by this
+// version of the node. Such node should not process blocks after the upgrade height.
+func (app *Blockchain) UnsupportedUpgrade() *state.Proposal {
+	committed, err := app.committedState()
+	if err != nil {
+		return nil
+	}
+
+	for _, proposal := range committed.GetProposals() {
+		if proposal.Type == state.ProposalTypeUpgrade && proposal.Status == state.ProposalStatusApplied &&
+			!upgrades.IsUpgradeSupported(proposal.Param) {
+			return &proposal
+		}
+	}
+
+	return nil
+}
+
+// committedState returns immutable state at the last committed height, unlike CurrentState it is not affected by
+// txs checked for mempool
+func (app *Blockchain) committedState() (*state.StateDB, error) {
+	app.lock.RLock()
+	defer app.lock.RUnlock()
+
+	return state.NewForCheck(app.LastCommittedHeight(), app.stateDB)
+}
+
 // Gracefully stopping Minter Blockchain instance
 func (app *Blockchain) Stop() {
 	atomic.StoreUint32(&app.stopped, 1)
 	app.wg.Wait()
+	app.snapshots.Wait()
 
 	app.appDB.Close()
 	app.stateDB.Close()
 }
 
+// Get events db of Minter Blockchain
+func (app *Blockchain) EventsDB() eventsdb.IEventsDB {
+	return app.eventsDB
+}
+
 // Get immutable state of Minter Blockchain
 func (app *Blockchain) CurrentState() *state.StateDB {
 	app.lock.RLock()
@@ -493,27 +743,40 @@ func (app *Blockchain) SetTmNode(node *tmNode.Node) {
 	app.tmNode = node
 }
 
+// Set Tendermint's state db
+func (app *Blockchain) SetTmStateDB(tmStateDB db.DB) {
+	app.tmStateDB = tmStateDB
+}
+
 // Get minimal acceptable gas price
 func (app *Blockchain) MinGasPrice() uint32 {
-	mempoolSize := app.tmNode.MempoolReactor().Mempool.Size()
+	return app.gasPriceCurve.minGasPrice(app.MempoolSize())
+}
 
-	if mempoolSize > 5000 {
-		return 50
-	}
+// Get static minimal gas price of this node which doesn't depend on mempool size
+func (app *Blockchain) StaticMinGasPrice() uint32 {
+	return app.gasPriceCurve.staticMin
+}
 
-	if mempoolSize > 1000 {
-		return 10
-	}
+// Get thresholds of mempool size at which minimal gas price grows
+func (app *Blockchain) GasPriceThresholds() []GasPriceThreshold {
+	return app.gasPriceCurve.thresholds
+}
 
-	if mempoolSize > 500 {
-		return 5
-	}
+// Get gas price which is enough for a tx to be included within given number of blocks
+func (app *Blockchain) RecommendedGasPrice(blocks int) uint32 {
+	mempoolSize := app.MempoolSize()
+	return app.gasUsage.recommendedGasPrice(blocks, mempoolSize, app.gasPriceCurve.minGasPrice(mempoolSize))
+}
 
-	if mempoolSize > 100 {
-		return 2
+// Get number of txs in mempool
+func (app *Blockchain) MempoolSize() int {
+	// tmNode is unavailable during Tendermint's replay mode
+	if app.tmNode == nil {
+		return 0
 	}
 
-	return 1
+	return app.tmNode.MempoolReactor().Mempool.Size()
 }
 
 func (app *Blockchain) resetCheckState() {
@@ -550,6 +813,130 @@ func (app *Blockchain) updateBlocksTimeDelta(height uint64, count int64) {
 	app.appDB.SetLastBlocksTimeDelta(height, delta)
 }
 
+// prepareSnapshot captures the committed block's part of a snapshot. Tendermint saves its state of the block only
+// after Commit returns, so the snapshot is taken at the beginning of the next block. The committed version of the
+// state is retained until the snapshot is written, so the next commits don't prune it.
+func (app *Blockchain) prepareSnapshot(appHash []byte) {
+	// should do this because tmNode is unavailable during Tendermint's replay mode
+	if app.tmNode == nil || app.tmStateDB == nil {
+		return
+	}
+
+	height := app.Height()
+	delta, _ := app.appDB.GetLastBlocksTimeDelta(height)
+
+	stateDeliver := app.stateDeliver
+	stateDeliver.RetainVersion(int64(height))
+
+	app.pendingSnapshot = &snapshot.Source{
+		Height:          height,
+		AppHash:         appHash,
+		StartHeight:     app.appDB.GetStartHeight(),
+		BlocksTimeDelta: delta,
+		Validators:      app.appDB.GetValidators(),
+		StateDB:         app.stateDB,
+		BlockStore:      app.tmNode.BlockStore(),
+		GenesisFile:     app.genesisFile,
+		Release: func() {
+			stateDeliver.ReleaseVersion(int64(height))
+		},
+	}
+}
+
+func (app *Blockchain) takePendingSnapshot() {
+	source := *app.pendingSnapshot
+	app.pendingSnapshot = nil
+
+	source.TmState = sm.LoadState(app.tmStateDB)
+	if source.TmState.LastBlockHeight != int64(source.Height) {
+		log.With("module", "snapshot").Error("Tendermint state does not match snapshot height, skipping snapshot",
+			"height", source.Height, "tmHeight", source.TmState.LastBlockHeight)
+		source.Release()
+		return
+	}
+
+	app.snapshots.Take(source)
+}
+
+// checkInvariants checks the latest committed state against invariants and stops the node on critical failure
+func (app *Blockchain) checkInvariants() {
+	if app.Height() <= 1 {
+		return
+	}
+
+	logger := log.With("module", "invariants")
+
+	genesisState, err := state.LoadGenesisAppState(app.genesisFile)
+	if err != nil {
+		logger.Error("Failed to load genesis, invariants are not checked", "err", err)
+		return
+	}
+
+	report := app.CurrentState().CheckInvariants(genesisState)
+
+	for _, violation := range report.Violations {
+		logger.Error("Invariants error", "msg", violation, "height", report.Height)
+	}
+
+	// the node is halted after the current block is committed
+	if report.IsCritical() {
+		logger.Error("Critical invariants failure, halting node", "height", report.Height)
+		app.haltReason = fmt.Sprintf("critical invariants failure at height %d", report.Height)
+		return
+	}
+
+	if !report.IsValid() && app.haltOnInvariantsViolation {
+		logger.Error("Invariants violation, halting node", "height", report.Height)
+		app.haltReason = fmt.Sprintf("invariants violation at height %d", report.Height)
+	}
+}
+
+// setChainParams caches chain params of the current block, packages which have no access to state receive them
+// explicitly
+func (app *Blockchain) setChainParams(params types.ChainParams) {
+	app.chainParams = params
+}
+
+// ChainParams returns chain params of the current block
+func (app *Blockchain) ChainParams() types.ChainParams {
+	return app.chainParams
+}
+
+// GetBlockRewards returns distribution of reward of a block between validators or nil if it's not kept
+func (app *Blockchain) GetBlockRewards(height uint64) *appdb.BlockRewards {
+	return app.appDB.GetBlockRewards(height)
+}
+
+func (app *Blockchain) saveBlockRewards(rewards appdb.BlockRewards) {
+	app.appDB.SetBlockRewards(rewards)
+
+	if app.blockRewardsKeepRecent != 0 && rewards.Height > app.blockRewardsKeepRecent {
+		app.appDB.DeleteBlockRewards(rewards.Height - app.blockRewardsKeepRecent)
+	}
+}
+
+func (app *Blockchain) validatorRewardStatus(val state.Validator) string {
+	if val.IsToDrop() {
+		return "dropped"
+	}
+
+	if app.validatorsStatuses[val.GetAddress()] != ValidatorPresent {
+		return "absent"
+	}
+
+	return "present"
+}
+
+func (app *Blockchain) saveMaxGas(height uint64, maxGas uint64) {
+	delta, _ := app.appDB.GetLastBlocksTimeDelta(height)
+
+	app.appDB.SetMaxGas(appdb.MaxGasRecord{
+		Height:          height,
+		MaxGas:          maxGas,
+		BlocksTimeDelta: delta,
+	})
+}
+
 func (app *Blockchain) SetBlocksTimeDelta(height uint64, value int) {
 	app.appDB.SetLastBlocksTimeDelta(height, value)
 }
@@ -559,33 +946,38 @@ func (app *Blockchain) GetBlocksTimeDelta(height, count uint64) (int, error) {
 }
 
 func (app *Blockchain) calcMaxGas(height uint64) uint64 {
-	const targetTime = 7
-	const blockDelta = 3
+	params := app.chainParams
 
 	// skip first 20 blocks
 	if height <= 20 {
-		return DefaultMaxGas
+		return params.MaxMaxGas
 	}
 
 	// get current max gas
 	newMaxGas := app.stateCheck.GetCurrentMaxGas()
 
 	// check if blocks are created in time
-	if delta, _ := app.GetBlocksTimeDelta(height, blockDelta); delta > targetTime*blockDelta {
-		newMaxGas = newMaxGas * 7 / 10 // decrease by 30%
+	delta, _ := app.GetBlocksTimeDelta(height, params.MaxGasWindow)
+	if delta > 0 && uint64(delta) > params.MaxGasTargetBlockTime*params.MaxGasWindow {
+		newMaxGas = newMaxGas * (100 - params.MaxGasDecreasePercent) / 100
 	} else {
-		newMaxGas = newMaxGas * 105 / 100 // increase by 5%
+		newMaxGas = newMaxGas * (100 + params.MaxGasIncreasePercent) / 100
 	}
 
 	// check if max gas is too high
-	if newMaxGas > DefaultMaxGas {
-		newMaxGas = DefaultMaxGas
+	if newMaxGas > params.MaxMaxGas {
+		newMaxGas = params.MaxMaxGas
 	}
 
 	// check if max gas is too low
-	if newMaxGas < MinMaxGas {
-		newMaxGas = MinMaxGas
+	if newMaxGas < params.MinMaxGas {
+		newMaxGas = params.MinMaxGas
 	}
 
 	return newMaxGas
 }
+
+// GetMaxGasHistory returns max gas of blocks in given range of heights
+func (app *Blockchain) GetMaxGasHistory(from, to uint64) []appdb.MaxGasRecord {
+	return app.appDB.GetMaxGasHistory(from, to)
+}
@@ -6,38 +6,40 @@ import (
 	"math/big"
 )
 
-const lastBlock = 43702611
-const firstReward = 333
-const lastReward = 68
-
 var startHeight uint64 = 0
-var BeforeGenesis = big.NewInt(0)
 
-func GetRewardForBlock(blockHeight uint64) *big.Int {
-	blockHeight += startHeight
+// GetRewardForBlock returns reward for a block of a chain with given params
+func GetRewardForBlock(params types.ChainParams, blockHeight uint64) *big.Int {
+	return getReward(params, blockHeight+startHeight)
+}
 
-	if blockHeight > lastBlock {
-		return big.NewInt(0)
+// GetRewardsBeforeGenesis returns total reward of blocks before start height of a chain with given params
+func GetRewardsBeforeGenesis(params types.ChainParams) *big.Int {
+	total := big.NewInt(0)
+	for i := uint64(1); i <= startHeight; i++ {
+		total.Add(total, getReward(params, i))
 	}
 
-	if blockHeight == lastBlock {
-		return helpers.BipToPip(big.NewInt(lastReward))
-	}
+	return total
+}
 
-	reward := big.NewInt(firstReward)
-	reward.Sub(reward, big.NewInt(int64(blockHeight/200000)))
+func SetStartHeight(sHeight uint64) {
+	startHeight = sHeight
+}
 
-	if reward.Cmp(types.Big0) < 1 {
-		return helpers.BipToPip(big.NewInt(1))
+func getReward(params types.ChainParams, blockHeight uint64) *big.Int {
+	if blockHeight > params.RewardsLastBlock {
+		return big.NewInt(0)
 	}
 
-	return helpers.BipToPip(reward)
-}
+	if blockHeight == params.RewardsLastBlock {
+		return helpers.BipToPip(big.NewInt(int64(params.LastReward)))
+	}
 
-func SetStartHeight(sHeight uint64) {
-	for i := uint64(1); i <= sHeight; i++ {
-		BeforeGenesis.Add(BeforeGenesis, GetRewardForBlock(i))
+	decrease := blockHeight / params.RewardDecreaseInterval
+	if decrease+params.MinReward > params.FirstReward {
+		return helpers.BipToPip(big.NewInt(int64(params.MinReward)))
 	}
 
-	startHeight = sHeight
+	return helpers.BipToPip(big.NewInt(int64(params.FirstReward - decrease)))
 }
@@ -1,6 +1,7 @@
 package rewards
 
 import (
+	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/helpers"
 	"math/big"
 	"testing"
@@ -27,8 +28,9 @@ func TestGetRewardForBlock(t *testing.T) {
 		},
 	}
 
+	params := types.DefaultChainParams()
 	for _, item := range data {
-		result := GetRewardForBlock(item.Block)
+		result := GetRewardForBlock(params, item.Block)
 
 		if result.Cmp(item.Result) != 0 {
 			t.Errorf("GetRewardForBlock result is not correct. Expected %s, got %s", item.Result.String(), result.String())
@@ -40,8 +42,9 @@ func TestTotalRewardsCount(t *testing.T) {
 	total := big.NewInt(0)
 	target := helpers.BipToPip(big.NewInt(9800000000))
 
+	params := types.DefaultChainParams()
 	for i := uint64(1); i <= 43703000; i++ {
-		total.Add(total, GetRewardForBlock(i))
+		total.Add(total, GetRewardForBlock(params, i))
 	}
 
 	if total.Cmp(target) != 0 {
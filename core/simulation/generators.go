@@ -0,0 +1,320 @@
+package simulation
+
+import (
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/check"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/transaction"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/crypto/sha3"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/rlp"
+	"math/big"
+)
+
+// generator returns random data of a transaction from sender. It may also change other fields of tx if
+// transaction type requires it.
+type generator func(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error)
+
+// generators should cover all types registered in transaction.TxDecoder, simulation refuses to start otherwise
+var generators = map[transaction.TxType]generator{
+	transaction.TypeSend:                generateSend,
+	transaction.TypeSellCoin:            generateSellCoin,
+	transaction.TypeSellAllCoin:         generateSellAllCoin,
+	transaction.TypeBuyCoin:             generateBuyCoin,
+	transaction.TypeCreateCoin:          generateCreateCoin,
+	transaction.TypeDeclareCandidacy:    generateDeclareCandidacy,
+	transaction.TypeDelegate:            generateDelegate,
+	transaction.TypeUnbond:              generateUnbond,
+	transaction.TypeRedeemCheck:         generateRedeemCheck,
+	transaction.TypeSetCandidateOnline:  generateSetCandidateOnline,
+	transaction.TypeSetCandidateOffline: generateSetCandidateOffline,
+	transaction.TypeCreateMultisig:      generateCreateMultisig,
+	transaction.TypeMultisend:           generateMultisend,
+	transaction.TypeEditCandidate:       generateEditCandidate,
+	transaction.TypeSubmitProposal:      generateSubmitProposal,
+	transaction.TypeVote:                generateVote,
+	transaction.TypeUnjail:              generateUnjail,
+	transaction.TypeSetAutoCompound:     generateSetAutoCompound,
+}
+
+func generateSend(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	coin := s.randomCoin()
+
+	return transaction.SendData{
+		Coin:  coin,
+		To:    s.randomAccount().address,
+		Value: s.randomAmount(s.state.GetBalance(sender.address, coin)),
+	}, nil
+}
+
+func generateSellCoin(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	coin := s.randomCoin()
+
+	return transaction.SellCoinData{
+		CoinToSell:        coin,
+		ValueToSell:       s.randomAmount(s.state.GetBalance(sender.address, coin)),
+		CoinToBuy:         s.randomCoin(),
+		MinimumValueToBuy: s.randomLimit(),
+	}, nil
+}
+
+func generateSellAllCoin(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	return transaction.SellAllCoinData{
+		CoinToSell:        s.randomCoin(),
+		CoinToBuy:         s.randomCoin(),
+		MinimumValueToBuy: s.randomLimit(),
+	}, nil
+}
+
+func generateBuyCoin(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	maximumValueToSell := helpers.BipToPip(big.NewInt(1e9))
+	if s.rand.Intn(10) == 0 {
+		maximumValueToSell = big.NewInt(1)
+	}
+
+	return transaction.BuyCoinData{
+		CoinToBuy:          s.randomCoin(),
+		ValueToBuy:         s.randomAmount(helpers.BipToPip(big.NewInt(1000))),
+		CoinToSell:         s.randomCoin(),
+		MaximumValueToSell: maximumValueToSell,
+	}, nil
+}
+
+func generateCreateCoin(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	// symbols are drawn from a small set, so attempts to create an existing coin are made as well
+	symbol := types.StrToCoinSymbol(fmt.Sprintf("SIM%d", s.rand.Intn(100)))
+
+	return transaction.CreateCoinData{
+		Name:                 fmt.Sprintf("Simulated %s", symbol),
+		Symbol:               symbol,
+		InitialAmount:        s.randomAmount(helpers.BipToPip(big.NewInt(100000))),
+		InitialReserve:       s.randomAmount(s.state.GetBalance(sender.address, types.GetBaseCoin())),
+		ConstantReserveRatio: uint(5 + s.rand.Intn(100)),
+	}, nil
+}
+
+func generateDeclareCandidacy(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	pubKey := s.newPubKey()
+	if s.rand.Intn(10) == 0 {
+		pubKey = s.randomCandidate()
+	}
+
+	coin := s.randomCoin()
+
+	return transaction.DeclareCandidacyData{
+		Address:    s.randomAccount().address,
+		PubKey:     pubKey,
+		Commission: uint(s.rand.Intn(110)),
+		Coin:       coin,
+		Stake:      s.randomAmount(s.state.GetBalance(sender.address, coin)),
+	}, nil
+}
+
+func generateDelegate(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	coin := s.randomCoin()
+
+	return transaction.DelegateData{
+		PubKey: s.randomCandidate(),
+		Coin:   coin,
+		Value:  s.randomAmount(s.state.GetBalance(sender.address, coin)),
+	}, nil
+}
+
+func generateUnbond(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	pubKey := s.randomCandidate()
+	coin := s.randomCoin()
+
+	return transaction.UnbondData{
+		PubKey: pubKey,
+		Coin:   coin,
+		Value:  s.randomAmount(s.ownStake(pubKey, sender.address, coin)),
+	}, nil
+}
+
+// generateRedeemCheck issues a check from a random account and redeems it by sender
+func generateRedeemCheck(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	issuer := s.randomAccount()
+	coin := s.randomCoin()
+
+	passphrase, err := s.newAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 1+s.rand.Intn(20))
+	s.rand.Read(nonce)
+
+	c := check.Check{
+		Nonce:    nonce,
+		ChainID:  types.CurrentChainID,
+		DueBlock: s.height + uint64(s.rand.Intn(10)) - 1,
+		Coin:     coin,
+		Value:    s.randomAmount(s.state.GetBalance(issuer.address, coin)),
+	}
+
+	lock, err := crypto.Sign(c.HashWithoutLock().Bytes(), passphrase.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	c.Lock = big.NewInt(0).SetBytes(lock)
+
+	if err := c.Sign(issuer.privateKey); err != nil {
+		return nil, err
+	}
+
+	rawCheck, err := rlp.EncodeToBytes(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var senderAddressHash types.Hash
+	hw := sha3.NewKeccak256()
+	if err := rlp.Encode(hw, []interface{}{sender.address}); err != nil {
+		return nil, err
+	}
+	hw.Sum(senderAddressHash[:0])
+
+	sig, err := crypto.Sign(senderAddressHash.Bytes(), passphrase.privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := [65]byte{}
+	copy(proof[:], sig)
+
+	// commission is paid by issuer in coin of the check, so gas coin and price of tx are fixed
+	tx.GasCoin = types.GetBaseCoin()
+	tx.GasPrice = 1
+
+	return transaction.RedeemCheckData{
+		RawCheck: rawCheck,
+		Proof:    proof,
+	}, nil
+}
+
+func generateSetCandidateOnline(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	return transaction.SetCandidateOnData{
+		PubKey: s.ownCandidate(sender),
+	}, nil
+}
+
+func generateSetCandidateOffline(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	return transaction.SetCandidateOffData{
+		PubKey: s.ownCandidate(sender),
+	}, nil
+}
+
+func generateCreateMultisig(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	count := 1 + s.rand.Intn(4)
+
+	var weights []uint
+	var addresses []types.Address
+	for i := 0; i < count; i++ {
+		weights = append(weights, uint(s.rand.Intn(1100)))
+		addresses = append(addresses, s.randomAccount().address)
+	}
+
+	// lengths of weights and addresses should match
+	if s.rand.Intn(10) == 0 {
+		weights = weights[1:]
+	}
+
+	return transaction.CreateMultisigData{
+		Threshold: uint(1 + s.rand.Intn(2000)),
+		Weights:   weights,
+		Addresses: addresses,
+	}, nil
+}
+
+func generateMultisend(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	var list []transaction.MultisendDataItem
+
+	for i := s.rand.Intn(6); i > 0; i-- {
+		coin := s.randomCoin()
+		value := s.randomAmount(s.state.GetBalance(sender.address, coin))
+
+		list = append(list, transaction.MultisendDataItem{
+			Coin:  coin,
+			To:    s.randomAccount().address,
+			Value: value.Div(value, big.NewInt(5)),
+		})
+	}
+
+	return transaction.MultisendData{
+		List: list,
+	}, nil
+}
+
+func generateEditCandidate(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	return transaction.EditCandidateData{
+		PubKey:        s.ownCandidate(sender),
+		RewardAddress: s.randomAccount().address,
+		OwnerAddress:  s.randomAccount().address,
+	}, nil
+}
+
+// proposalParams are drawn by generateSubmitProposal, some of them can't be changed by governance
+var proposalParams = []string{"unbond_period", "pay_rewards_interval", "commissions.send_tx", "jail_period", "first_reward",
+	"validator_max_absent_window", "unknown"}
+
+func generateSubmitProposal(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	height := s.height + s.state.GetChainParams().ProposalVotingPeriod + uint64(s.rand.Intn(100)) - 10
+
+	if s.rand.Intn(5) == 0 {
+		return transaction.SubmitProposalData{
+			Type:   state.ProposalTypeUpgrade,
+			Param:  fmt.Sprintf("upgrade-%d", s.rand.Intn(100)),
+			Height: height,
+		}, nil
+	}
+
+	return transaction.SubmitProposalData{
+		Type:   state.ProposalTypeParamChange,
+		Param:  proposalParams[s.rand.Intn(len(proposalParams))],
+		Value:  uint64(s.rand.Intn(1000)),
+		Height: height,
+	}, nil
+}
+
+func generateVote(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	return transaction.VoteData{
+		ProposalID: uint64(s.rand.Int63n(int64(s.state.NextProposalID()) + 1)),
+		Option:     state.VoteOption(s.rand.Intn(4)),
+	}, nil
+}
+
+func generateUnjail(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	return transaction.UnjailData{
+		PubKey: s.ownCandidate(sender),
+	}, nil
+}
+
+func generateSetAutoCompound(s *Simulation, tx *transaction.Transaction, sender *account) (transaction.Data, error) {
+	return transaction.SetAutoCompoundData{
+		Enabled: s.rand.Intn(2) == 0,
+	}, nil
+}
+
+// ownCandidate mostly returns a candidate owned by sender, if there is any
+func (s *Simulation) ownCandidate(sender *account) types.Pubkey {
+	if s.rand.Intn(5) != 0 {
+		for _, pubKey := range s.candidates {
+			if s.isOwner(pubKey, sender.address) {
+				return pubKey
+			}
+		}
+	}
+
+	return s.randomCandidate()
+}
+
+// randomLimit returns a limit of a conversion which is either harmless or mostly unreachable
+func (s *Simulation) randomLimit() *big.Int {
+	if s.rand.Intn(10) == 0 {
+		return helpers.BipToPip(big.NewInt(1e9))
+	}
+
+	return big.NewInt(0)
+}
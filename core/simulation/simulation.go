@@ -0,0 +1,407 @@
+package simulation
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/genesis"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/transaction"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/rlp"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"math/big"
+	"math/rand"
+	"sync"
+)
+
+const (
+	simulationCoin = "SIMCOIN"
+
+	rewardsPeriod = 12
+)
+
+// Params describes a simulation run. Runs with the same params produce the same transactions and states.
+type Params struct {
+	Seed        int64
+	Blocks      int
+	Accounts    int
+	Candidates  int
+	TxsPerBlock int
+
+	// InvalidTxs is a share of transactions which are intentionally malformed, e.g. signed with a wrong nonce
+	InvalidTxs float64
+}
+
+// DefaultParams returns params of a short run with given seed
+func DefaultParams(seed int64) Params {
+	return Params{
+		Seed:        seed,
+		Blocks:      100,
+		Accounts:    20,
+		Candidates:  3,
+		TxsPerBlock: 20,
+		InvalidTxs:  0.1,
+	}
+}
+
+// TxStats counts results of simulated transactions of one type
+type TxStats struct {
+	Delivered int
+	Failed    int
+}
+
+type account struct {
+	privateKey *ecdsa.PrivateKey
+	address    types.Address
+}
+
+// Simulation generates random transactions of every registered type and runs them in check and deliver modes
+// against an in-memory state, checking invariants after each block.
+type Simulation struct {
+	params  Params
+	rand    *rand.Rand
+	genesis types.AppState
+
+	state    *state.StateDB
+	height   uint64
+	hash     []byte
+	txTypes  []transaction.TxType
+	accounts []*account
+
+	// coins and candidates known to exist, transactions mostly refer to them
+	coins      []types.CoinSymbol
+	candidates []types.Pubkey
+
+	Stats map[transaction.TxType]*TxStats
+}
+
+// New creates a simulation with a genesis of given number of funded accounts and candidates
+func New(params Params) (*Simulation, error) {
+	s := &Simulation{
+		params:  params,
+		rand:    rand.New(rand.NewSource(params.Seed)),
+		txTypes: transaction.TxDecoder.RegisteredTypes(),
+		coins:   []types.CoinSymbol{types.GetBaseCoin(), types.StrToCoinSymbol(simulationCoin)},
+		Stats:   map[transaction.TxType]*TxStats{},
+	}
+
+	for _, txType := range s.txTypes {
+		if generators[txType] == nil {
+			return nil, fmt.Errorf("there is no generator of tx type %x", txType)
+		}
+
+		s.Stats[txType] = &TxStats{}
+	}
+
+	var input genesis.Input
+	for i := 0; i < params.Accounts; i++ {
+		acc, err := s.newAccount()
+		if err != nil {
+			return nil, err
+		}
+		s.accounts = append(s.accounts, acc)
+
+		input.Accounts = append(input.Accounts, genesis.Account{
+			Address: acc.address.String(),
+			Balance: []genesis.Balance{
+				{Coin: types.GetBaseCoin().String(), Value: "1000000"},
+				{Coin: simulationCoin, Value: "100000"},
+			},
+		})
+	}
+
+	input.Coins = []genesis.Coin{{Symbol: simulationCoin, Name: "Simulation coin", Crr: 50, Reserve: "1000000"}}
+
+	for i := 0; i < params.Candidates; i++ {
+		owner := s.accounts[i%len(s.accounts)].address.String()
+		pubKey := s.newPubKey()
+		s.candidates = append(s.candidates, pubKey)
+
+		input.Candidates = append(input.Candidates, genesis.Candidate{
+			PubKey:       pubKey.String(),
+			OwnerAddress: owner,
+			Commission:   10,
+			Stakes:       []genesis.Stake{{Owner: owner, Coin: types.GetBaseCoin().String(), Value: "100000"}},
+		})
+	}
+
+	appState, err := genesis.Build(input)
+	if err != nil {
+		return nil, err
+	}
+	s.genesis = appState
+
+	memDB := dbm.NewMemDB()
+	s.state, err = state.New(0, memDB, false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.state.Import(appState)
+	if _, _, err := s.state.Commit(); err != nil {
+		return nil, err
+	}
+	s.height = 1
+
+	return s, nil
+}
+
+// Height returns height of the last simulated block
+func (s *Simulation) Height() uint64 {
+	return s.height
+}
+
+// Hash returns state hash after the last simulated block
+func (s *Simulation) Hash() []byte {
+	return s.hash
+}
+
+// Run simulates all blocks and returns the first failure
+func (s *Simulation) Run() error {
+	for i := 0; i < s.params.Blocks; i++ {
+		if err := s.NextBlock(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NextBlock simulates one block. It fails if check and deliver modes disagree on any transaction or if the
+// committed state violates invariants.
+func (s *Simulation) NextBlock() error {
+	s.height++
+	rewardPool := big.NewInt(0)
+
+	s.releaseFrozenFunds()
+
+	for i := 0; i < s.params.TxsPerBlock; i++ {
+		txType := s.txTypes[s.rand.Intn(len(s.txTypes))]
+		sender := s.randomAccount()
+
+		rawTx, data, err := s.newTx(txType, sender)
+		if err != nil {
+			return s.errorf("cannot create tx %d of type %x: %s", i, txType, err)
+		}
+
+		// tx is checked against the same state it is delivered to, so both modes should give the same result
+		checkResponse := transaction.RunTx(s.state, true, rawTx, big.NewInt(0), s.height, sync.Map{}, 0)
+		deliverResponse := transaction.RunTx(s.state, false, rawTx, rewardPool, s.height, sync.Map{}, 0)
+
+		if checkResponse.Code != deliverResponse.Code || checkResponse.GasUsed != deliverResponse.GasUsed {
+			return s.errorf("check and deliver disagree on tx %d of type %x: check code %d, gas %d (%s), "+
+				"deliver code %d, gas %d (%s)", i, txType, checkResponse.Code, checkResponse.GasUsed, checkResponse.Log,
+				deliverResponse.Code, deliverResponse.GasUsed, deliverResponse.Log)
+		}
+
+		if deliverResponse.Code != code.OK {
+			s.Stats[txType].Failed++
+			continue
+		}
+
+		s.Stats[txType].Delivered++
+		s.onDelivered(data)
+	}
+
+	// commissions are distributed the same way as block rewards
+	s.state.AddAccumReward(s.genesis.Validators[0].PubKey, rewardPool)
+	if s.height%rewardsPeriod == 0 {
+		s.state.PayRewards()
+	}
+
+	hash, _, err := s.state.Commit()
+	if err != nil {
+		return s.errorf("cannot commit state: %s", err)
+	}
+	s.hash = hash
+
+	return s.checkState()
+}
+
+// checkState checks committed state against invariants and against a check state created from it
+func (s *Simulation) checkState() error {
+	// there is no emission in simulation, undistributed remainders of rewards are counted as slashed
+	emission := big.NewInt(0).Neg(s.state.GetTotalSlashed())
+
+	report := s.state.CheckInvariantsWithEmission(s.genesis, emission)
+	if len(report.NegativeBalances) != 0 {
+		return s.errorf("negative balances: %v", report.NegativeBalances)
+	}
+
+	if !report.IsValid() {
+		return s.errorf("invariants are violated: %v", report.Violations)
+	}
+
+	checkState := state.NewForCheckFromDeliver(s.state)
+	for _, acc := range s.accounts {
+		if checkState.GetNonce(acc.address) != s.state.GetNonce(acc.address) {
+			return s.errorf("nonce of %s differs in check state", acc.address)
+		}
+
+		for _, coin := range s.coins {
+			if checkState.GetBalance(acc.address, coin).Cmp(s.state.GetBalance(acc.address, coin)) != 0 {
+				return s.errorf("balance of %s in %s differs in check state", acc.address, coin)
+			}
+		}
+	}
+
+	return nil
+}
+
+// releaseFrozenFunds returns unbonded stakes to owners, as it is done at the beginning of a block
+func (s *Simulation) releaseFrozenFunds() {
+	frozenFunds := s.state.GetStateFrozenFunds(s.height)
+	if frozenFunds == nil {
+		return
+	}
+
+	for _, item := range frozenFunds.List() {
+		s.state.AddBalance(item.Address, item.Coin, item.Value)
+	}
+
+	frozenFunds.Delete()
+}
+
+// newTx creates a signed transaction of given type. Some transactions are malformed on purpose.
+func (s *Simulation) newTx(txType transaction.TxType, sender *account) ([]byte, transaction.Data, error) {
+	tx := &transaction.Transaction{
+		Nonce:         s.state.GetNonce(sender.address) + 1,
+		GasPrice:      uint32(1 + s.rand.Intn(3)),
+		ChainID:       types.CurrentChainID,
+		GasCoin:       s.randomCoin(),
+		Type:          txType,
+		SignatureType: transaction.SigTypeSingle,
+	}
+
+	data, err := generators[txType](s, tx, sender)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx.Data, err = rlp.EncodeToBytes(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	invalid := s.rand.Float64() < s.params.InvalidTxs
+	if invalid {
+		s.spoilTx(tx)
+	}
+
+	if err := tx.Sign(sender.privateKey); err != nil {
+		return nil, nil, err
+	}
+
+	rawTx, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if invalid && s.rand.Intn(5) == 0 {
+		rawTx[s.rand.Intn(len(rawTx))] ^= byte(1 + s.rand.Intn(255))
+	}
+
+	return rawTx, data, nil
+}
+
+// spoilTx makes one of the fields of tx invalid
+func (s *Simulation) spoilTx(tx *transaction.Transaction) {
+	switch s.rand.Intn(5) {
+	case 0:
+		tx.Nonce += uint64(1 + s.rand.Intn(3))
+	case 1:
+		tx.Nonce--
+	case 2:
+		tx.ChainID = types.ChainID(0xFF)
+	case 3:
+		tx.GasCoin = types.StrToCoinSymbol("NOTEXISTS")
+	case 4:
+		tx.Payload = make([]byte, 1025)
+	}
+}
+
+// onDelivered remembers coins and candidates created by delivered transaction
+func (s *Simulation) onDelivered(data transaction.Data) {
+	switch data := data.(type) {
+	case transaction.CreateCoinData:
+		s.coins = append(s.coins, data.Symbol)
+	case transaction.DeclareCandidacyData:
+		s.candidates = append(s.candidates, data.PubKey)
+	}
+}
+
+func (s *Simulation) newAccount() (*account, error) {
+	seed := make([]byte, 32)
+	s.rand.Read(seed)
+
+	privateKey, err := crypto.ToECDSA(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &account{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}, nil
+}
+
+func (s *Simulation) newPubKey() types.Pubkey {
+	pubKey := make([]byte, 32)
+	s.rand.Read(pubKey)
+
+	return pubKey
+}
+
+func (s *Simulation) randomAccount() *account {
+	return s.accounts[s.rand.Intn(len(s.accounts))]
+}
+
+func (s *Simulation) randomCoin() types.CoinSymbol {
+	return s.coins[s.rand.Intn(len(s.coins))]
+}
+
+// randomCandidate mostly returns a known candidate, sometimes an unknown one
+func (s *Simulation) randomCandidate() types.Pubkey {
+	if s.rand.Intn(10) == 0 {
+		return s.newPubKey()
+	}
+
+	return s.candidates[s.rand.Intn(len(s.candidates))]
+}
+
+// randomAmount returns a random part of given value and sometimes a value exceeding it
+func (s *Simulation) randomAmount(value *big.Int) *big.Int {
+	if value.Sign() != 1 || s.rand.Intn(10) == 0 {
+		return big.NewInt(0).Add(value, big.NewInt(1+s.rand.Int63n(1e18)))
+	}
+
+	amount := big.NewInt(0).Mul(value, big.NewInt(1+s.rand.Int63n(100)))
+	return amount.Div(amount, big.NewInt(100))
+}
+
+// ownStake returns stake of owner in candidate's coin or zero
+func (s *Simulation) ownStake(pubKey types.Pubkey, owner types.Address, coin types.CoinSymbol) *big.Int {
+	candidate := s.state.GetStateCandidate(pubKey)
+	if candidate == nil {
+		return big.NewInt(0)
+	}
+
+	stake := candidate.GetStakeOfAddress(owner, coin)
+	if stake == nil {
+		return big.NewInt(0)
+	}
+
+	return stake.Value
+}
+
+func (s *Simulation) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("seed %d, block %d: %s", s.params.Seed, s.height, fmt.Sprintf(format, args...))
+}
+
+// isOwner returns true if address owns candidate, used to make candidate transactions mostly valid
+func (s *Simulation) isOwner(pubKey types.Pubkey, address types.Address) bool {
+	candidate := s.state.GetStateCandidate(pubKey)
+	return candidate != nil && candidate.OwnerAddress == address
+}
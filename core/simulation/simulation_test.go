@@ -0,0 +1,71 @@
+package simulation
+
+import (
+	"bytes"
+	"flag"
+	"github.com/MinterTeam/minter-go-node/config"
+	"github.com/MinterTeam/minter-go-node/core/transaction"
+	"github.com/MinterTeam/minter-go-node/log"
+	"testing"
+	"time"
+)
+
+var (
+	seedFlag   = flag.Int64("simulation.seed", 0, "seed of simulation, random if 0")
+	blocksFlag = flag.Int("simulation.blocks", 100, "number of simulated blocks")
+)
+
+func init() {
+	cfg := config.DefaultConfig()
+	cfg.LogLevel = "*:error"
+	log.InitLog(cfg)
+}
+
+func TestSimulation(t *testing.T) {
+	seed := *seedFlag
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	params := DefaultParams(seed)
+	params.Blocks = *blocksFlag
+
+	s, err := New(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Run(); err != nil {
+		t.Fatalf("%s\nreproduce with: go test ./core/simulation -run TestSimulation -simulation.seed=%d "+
+			"-simulation.blocks=%d", err, seed, params.Blocks)
+	}
+
+	for _, txType := range transaction.TxDecoder.RegisteredTypes() {
+		stats := s.Stats[txType]
+		t.Logf("tx type %x: delivered %d, failed %d", txType, stats.Delivered, stats.Failed)
+	}
+}
+
+func TestSimulationIsReproducible(t *testing.T) {
+	var hashes [][]byte
+
+	for i := 0; i < 2; i++ {
+		params := DefaultParams(42)
+		params.Blocks = 10
+
+		s, err := New(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		hashes = append(hashes, s.Hash())
+	}
+
+	if !bytes.Equal(hashes[0], hashes[1]) {
+		t.Fatalf("Simulations with the same seed should produce the same state, got %X and %X", hashes[0], hashes[1])
+	}
+}
@@ -0,0 +1,96 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/appdb"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/tmdb"
+	bc "github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/db"
+	sm "github.com/tendermint/tendermint/state"
+	"path/filepath"
+	"strconv"
+)
+
+// Target holds databases of a fresh node which should be bootstrapped from a snapshot
+type Target struct {
+	StateDB      db.DB
+	AppDB        *appdb.AppDB
+	TmStateDB    db.DB
+	BlockStoreDB db.DB
+	GenesisFile  string
+}
+
+// Restore writes snapshot at given height into target databases. Height 0 means the latest snapshot.
+func Restore(dir string, height uint64, target Target) (*Manifest, error) {
+	manifest, err := LoadManifest(dir, height)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotDir := filepath.Join(dir, strconv.FormatUint(manifest.Height, 10))
+
+	// restore state
+	for _, chunk := range manifest.Chunks {
+		batch := target.StateDB.NewBatch()
+		if err := readChunk(snapshotDir, chunk, batch.Set); err != nil {
+			return nil, err
+		}
+		batch.Write()
+	}
+
+	tree, err := state.NewMutableTree(target.StateDB).GetImmutableAtHeight(int64(manifest.Height))
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(tree.Hash(), manifest.AppHash) {
+		return nil, fmt.Errorf("restored state hash %X does not match snapshot app hash %X", tree.Hash(),
+			manifest.AppHash)
+	}
+
+	// restore application db
+	target.AppDB.SetStartHeight(manifest.StartHeight)
+	target.AppDB.SaveValidators(manifest.Validators)
+	target.AppDB.SetLastBlocksTimeDelta(manifest.Height, manifest.BlocksTimeDelta)
+	target.AppDB.SetLastBlockHash(manifest.AppHash)
+	target.AppDB.SetLastHeight(manifest.Height)
+
+	// restore Tendermint's state and latest blocks
+	if err := restoreTmState(target.TmStateDB, manifest); err != nil {
+		return nil, err
+	}
+
+	batch := target.BlockStoreDB.NewBatch()
+	if err := readChunk(snapshotDir, manifest.BlockStore, batch.Set); err != nil {
+		return nil, err
+	}
+	batch.Write()
+	bc.BlockStoreStateJSON{Height: int64(manifest.Height)}.Save(target.BlockStoreDB)
+
+	genesis := filepath.Join(snapshotDir, genesisFile)
+	if target.GenesisFile != "" && !common.FileExists(target.GenesisFile) && common.FileExists(genesis) {
+		if err := copyFile(genesis, target.GenesisFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return manifest, nil
+}
+
+// restoreTmState saves Tendermint's state along with validators and consensus params needed to
+// continue from the snapshot height
+func restoreTmState(tmStateDB db.DB, manifest *Manifest) error {
+	tmdb.SetStateBytes(tmStateDB, manifest.TmState)
+	tmState := sm.LoadState(tmStateDB)
+	if tmState.LastBlockHeight != int64(manifest.Height) {
+		return fmt.Errorf("tendermint state height %d does not match snapshot height %d",
+			tmState.LastBlockHeight, manifest.Height)
+	}
+
+	tmdb.SaveState(tmStateDB, tmState)
+
+	return nil
+}
@@ -0,0 +1,532 @@
+package snapshot
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/tmdb"
+	"github.com/MinterTeam/minter-go-node/log"
+	"github.com/tendermint/go-amino"
+	abciTypes "github.com/tendermint/tendermint/abci/types"
+	bc "github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/db"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	manifestFile   = "manifest.json"
+	genesisFile    = "genesis.json"
+	blockStoreFile = "blockstore.chunk"
+
+	// DefaultChunkSize is a size of a single state chunk in bytes
+	DefaultChunkSize = 16 * 1024 * 1024
+
+	// BlocksToKeep is a number of latest blocks saved with a snapshot. Minter needs a few previous
+	// block headers to compute the blocks time delta.
+	BlocksToKeep = 5
+)
+
+var (
+	cdc = amino.NewCodec()
+
+	ErrNotFound = errors.New("snapshot not found")
+)
+
+func init() {
+	types.RegisterBlockAmino(cdc)
+}
+
+// Chunk describes a single file of a snapshot
+type Chunk struct {
+	File     string `json:"file"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// Manifest describes a snapshot taken at some height
+type Manifest struct {
+	Height          uint64                     `json:"height"`
+	AppHash         common.HexBytes            `json:"app_hash"`
+	StartHeight     uint64                     `json:"start_height"`
+	BlocksTimeDelta int                        `json:"blocks_time_delta"`
+	Validators      abciTypes.ValidatorUpdates `json:"validators"`
+	TmState         []byte                     `json:"tm_state"`
+	Chunks          []Chunk                    `json:"chunks"`
+	BlockStore      Chunk                      `json:"block_store"`
+	CreatedAt       time.Time                  `json:"created_at"`
+}
+
+// Source holds everything needed to take a snapshot at given height
+type Source struct {
+	Height          uint64
+	AppHash         []byte
+	StartHeight     uint64
+	BlocksTimeDelta int
+	Validators      abciTypes.ValidatorUpdates
+
+	// TmState is Tendermint's state at the snapshot height
+	TmState sm.State
+
+	// StateDB holds the state tree, only its version at the snapshot height is written
+	StateDB     db.DB
+	BlockStore  *bc.BlockStore
+	GenesisFile string
+
+	// Release is called when the snapshot is written or skipped, the state version may be pruned after that
+	Release func()
+}
+
+func (s Source) release() {
+	if s.Release != nil {
+		s.Release()
+	}
+}
+
+// Manager periodically writes snapshots to disk and prunes the old ones
+type Manager struct {
+	dir        string
+	interval   uint64
+	keepRecent int
+	chunkSize  int
+
+	lock   sync.Mutex
+	taking bool
+	wg     sync.WaitGroup
+}
+
+func NewManager(dir string, interval uint64, keepRecent int) *Manager {
+	return &Manager{
+		dir:        dir,
+		interval:   interval,
+		keepRecent: keepRecent,
+		chunkSize:  DefaultChunkSize,
+	}
+}
+
+// ShouldTake reports whether a snapshot should be taken at given height
+func (m *Manager) ShouldTake(height uint64) bool {
+	if m.interval == 0 || height%m.interval != 0 {
+		return false
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return !m.taking
+}
+
+// Take writes a snapshot in background. Only one snapshot can be written at a time. The state version of the
+// snapshot should be kept until source is released.
+func (m *Manager) Take(source Source) {
+	m.lock.Lock()
+	if m.taking {
+		m.lock.Unlock()
+		source.release()
+		return
+	}
+	m.taking = true
+	m.lock.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer source.release()
+		defer func() {
+			m.lock.Lock()
+			m.taking = false
+			m.lock.Unlock()
+		}()
+
+		logger := log.With("module", "snapshot")
+		logger.Info("Taking snapshot", "height", source.Height)
+
+		if err := m.write(source); err != nil {
+			logger.Error("Failed to take snapshot", "height", source.Height, "err", err)
+			return
+		}
+
+		if err := m.prune(); err != nil {
+			logger.Error("Failed to prune snapshots", "err", err)
+		}
+
+		logger.Info("Snapshot is ready", "height", source.Height)
+	}()
+}
+
+// Wait blocks until current snapshot is written
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+func (m *Manager) write(source Source) error {
+	if source.TmState.LastBlockHeight != int64(source.Height) {
+		return fmt.Errorf("tendermint state height %d does not match snapshot height %d",
+			source.TmState.LastBlockHeight, source.Height)
+	}
+
+	target := filepath.Join(m.dir, strconv.FormatUint(source.Height, 10))
+	tmp := target + ".tmp"
+
+	_ = os.RemoveAll(tmp)
+	if err := common.EnsureDir(tmp, 0700); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	manifest := Manifest{
+		Height:          source.Height,
+		AppHash:         source.AppHash,
+		StartHeight:     source.StartHeight,
+		BlocksTimeDelta: source.BlocksTimeDelta,
+		Validators:      source.Validators,
+		TmState:         source.TmState.Bytes(),
+		CreatedAt:       time.Now().UTC(),
+	}
+
+	chunks, err := writeStateChunks(tmp, func(fn func(key, value []byte) error) error {
+		return state.ExportTree(source.StateDB, int64(source.Height), fn)
+	}, m.chunkSize)
+	if err != nil {
+		return err
+	}
+	manifest.Chunks = chunks
+
+	manifest.BlockStore, err = writeBlockStore(tmp, source.BlockStore, int64(source.Height))
+	if err != nil {
+		return err
+	}
+
+	if source.GenesisFile != "" && common.FileExists(source.GenesisFile) {
+		if err := copyFile(source.GenesisFile, filepath.Join(tmp, genesisFile)); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(tmp, manifestFile), data, 0600); err != nil {
+		return err
+	}
+
+	_ = os.RemoveAll(target)
+	return os.Rename(tmp, target)
+}
+
+func (m *Manager) prune() error {
+	if m.keepRecent <= 0 {
+		return nil
+	}
+
+	heights, err := Heights(m.dir)
+	if err != nil {
+		return err
+	}
+
+	if len(heights) <= m.keepRecent {
+		return nil
+	}
+
+	for _, height := range heights[:len(heights)-m.keepRecent] {
+		if err := os.RemoveAll(filepath.Join(m.dir, strconv.FormatUint(height, 10))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Heights returns sorted heights of all complete snapshots in given directory
+func Heights(dir string) ([]uint64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var heights []uint64
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+
+		height, err := strconv.ParseUint(file.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if !common.FileExists(filepath.Join(dir, file.Name(), manifestFile)) {
+			continue
+		}
+
+		heights = append(heights, height)
+	}
+
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i] < heights[j]
+	})
+
+	return heights, nil
+}
+
+// LoadManifest reads manifest of a snapshot at given height. Height 0 means the latest snapshot.
+func LoadManifest(dir string, height uint64) (*Manifest, error) {
+	if height == 0 {
+		heights, err := Heights(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(heights) == 0 {
+			return nil, ErrNotFound
+		}
+
+		height = heights[len(heights)-1]
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, strconv.FormatUint(height, 10), manifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// writeStateChunks splits key-value pairs produced by export into chunks of given size
+func writeStateChunks(dir string, export func(fn func(key, value []byte) error) error, chunkSize int) ([]Chunk, error) {
+	var chunks []Chunk
+	var w *chunkWriter
+
+	err := export(func(key, value []byte) error {
+		if w == nil {
+			var err error
+			w, err = newChunkWriter(filepath.Join(dir, fmt.Sprintf("%06d.chunk", len(chunks))))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := w.Write(key, value); err != nil {
+			return err
+		}
+
+		if w.size >= int64(chunkSize) {
+			chunk, err := w.Close()
+			w = nil
+			if err != nil {
+				return err
+			}
+			chunks = append(chunks, chunk)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if w != nil {
+			w.Close()
+		}
+		return nil, err
+	}
+
+	if w != nil {
+		chunk, err := w.Close()
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// writeBlockStore saves latest blocks in the same format Tendermint's block store does
+func writeBlockStore(dir string, blockStore *bc.BlockStore, height int64) (Chunk, error) {
+	w, err := newChunkWriter(filepath.Join(dir, blockStoreFile))
+	if err != nil {
+		return Chunk{}, err
+	}
+
+	from := height - BlocksToKeep + 1
+	if from < 1 {
+		from = 1
+	}
+
+	for h := from; h <= height; h++ {
+		meta := blockStore.LoadBlockMeta(h)
+		if meta == nil {
+			w.Close()
+			return Chunk{}, fmt.Errorf("block meta at height %d not found", h)
+		}
+
+		if err := w.WriteValue(tmdb.BlockMetaKey(h), meta); err != nil {
+			w.Close()
+			return Chunk{}, err
+		}
+
+		for i := 0; i < meta.BlockID.PartsHeader.Total; i++ {
+			part := blockStore.LoadBlockPart(h, i)
+			if err := w.WriteValue(tmdb.BlockPartKey(h, i), part); err != nil {
+				w.Close()
+				return Chunk{}, err
+			}
+		}
+
+		if commit := blockStore.LoadBlockCommit(h - 1); commit != nil {
+			if err := w.WriteValue(tmdb.BlockCommitKey(h-1), commit); err != nil {
+				w.Close()
+				return Chunk{}, err
+			}
+		}
+	}
+
+	seenCommit := blockStore.LoadSeenCommit(height)
+	if seenCommit == nil {
+		w.Close()
+		return Chunk{}, fmt.Errorf("seen commit at height %d not found", height)
+	}
+
+	if err := w.WriteValue(tmdb.SeenCommitKey(height), seenCommit); err != nil {
+		w.Close()
+		return Chunk{}, err
+	}
+
+	return w.Close()
+}
+
+type chunkWriter struct {
+	file   *os.File
+	buf    *bufio.Writer
+	hasher hash.Hash
+	name   string
+	size   int64
+}
+
+func newChunkWriter(path string) (*chunkWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+
+	return &chunkWriter{
+		file:   file,
+		buf:    bufio.NewWriter(io.MultiWriter(file, hasher)),
+		hasher: hasher,
+		name:   filepath.Base(path),
+	}, nil
+}
+
+func (w *chunkWriter) WriteValue(key []byte, value interface{}) error {
+	data, err := cdc.MarshalBinaryBare(value)
+	if err != nil {
+		return err
+	}
+
+	return w.Write(key, data)
+}
+
+// Write appends length prefixed key and value to the chunk
+func (w *chunkWriter) Write(key, value []byte) error {
+	for _, item := range [][]byte{key, value} {
+		l := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(l, uint64(len(item)))
+
+		if _, err := w.buf.Write(l[:n]); err != nil {
+			return err
+		}
+
+		if _, err := w.buf.Write(item); err != nil {
+			return err
+		}
+
+		w.size += int64(n + len(item))
+	}
+
+	return nil
+}
+
+func (w *chunkWriter) Close() (Chunk, error) {
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return Chunk{}, err
+	}
+
+	if err := w.file.Close(); err != nil {
+		return Chunk{}, err
+	}
+
+	return Chunk{
+		File:     w.name,
+		Size:     w.size,
+		Checksum: hex.EncodeToString(w.hasher.Sum(nil)),
+	}, nil
+}
+
+// readChunk verifies checksum of a chunk and calls fn for every key-value pair in it
+func readChunk(dir string, chunk Chunk, fn func(key, value []byte)) error {
+	data, err := ioutil.ReadFile(filepath.Join(dir, chunk.File))
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != chunk.Checksum {
+		return fmt.Errorf("checksum mismatch in chunk %s", chunk.File)
+	}
+
+	for len(data) > 0 {
+		var pair [2][]byte
+		for i := range pair {
+			l, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < l {
+				return fmt.Errorf("chunk %s is corrupted", chunk.File)
+			}
+
+			pair[i] = data[n : n+int(l)]
+			data = data[n+int(l):]
+		}
+
+		fn(pair[0], pair[1])
+	}
+
+	return nil
+}
+
+func copyFile(from, to string) error {
+	data, err := ioutil.ReadFile(from)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(to, data, 0644)
+}
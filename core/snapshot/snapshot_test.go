@@ -0,0 +1,215 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/tendermint/tendermint/libs/db"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateChunks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minter_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := db.NewMemDB()
+	for i := 0; i < 1000; i++ {
+		source.Set([]byte(fmt.Sprintf("key%04d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+
+	chunks, err := writeStateChunks(dir, exportDB(source), 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chunks) < 2 {
+		t.Fatalf("State should be split into several chunks, got %d", len(chunks))
+	}
+
+	target := db.NewMemDB()
+	for _, chunk := range chunks {
+		if err := readChunk(dir, chunk, target.Set); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for it := source.Iterator(nil, nil); it.Valid(); it.Next() {
+		if !bytes.Equal(target.Get(it.Key()), it.Value()) {
+			t.Fatalf("Value of %s is not restored", it.Key())
+		}
+	}
+}
+
+func TestStateChunksWhileCommitting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minter_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stateDB := db.NewMemDB()
+	stateDeliver, err := state.New(0, stateDB, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addresses := make([]types.Address, 1000)
+	commit := func() []byte {
+		for i := range addresses {
+			addresses[i] = types.Address{byte(i >> 8), byte(i)}
+			stateDeliver.AddBalance(addresses[i], types.GetBaseCoin(), big.NewInt(1))
+		}
+
+		hash, _, err := stateDeliver.Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return hash
+	}
+
+	for i := 0; i < 3; i++ {
+		commit()
+	}
+
+	version := int64(4)
+	hash := commit()
+	stateDeliver.RetainVersion(version)
+
+	// the retained version survives commits which would prune it, while it's being exported
+	commit()
+
+	done := make(chan error)
+	var chunks []Chunk
+	go func() {
+		var err error
+		chunks, err = writeStateChunks(dir, func(fn func(key, value []byte) error) error {
+			return state.ExportTree(stateDB, version, fn)
+		}, 1024)
+		done <- err
+	}()
+
+	for exported := false; !exported; {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+			exported = true
+		default:
+			commit()
+		}
+	}
+
+	stateDeliver.ReleaseVersion(version)
+	commit()
+
+	if _, err := state.NewMutableTree(stateDB).GetImmutableAtHeight(version); err == nil {
+		t.Fatal("Released version should be pruned by the next commit")
+	}
+
+	target := db.NewMemDB()
+	for _, chunk := range chunks {
+		if err := readChunk(dir, chunk, target.Set); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := state.VerifyTree(target, version); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := state.NewMutableTree(target).GetImmutableAtHeight(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(tree.Hash(), hash) {
+		t.Fatalf("Restored state hash %X does not match %X", tree.Hash(), hash)
+	}
+
+	restored, err := state.New(uint64(version), target, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, address := range addresses {
+		if balance := restored.GetBalance(address, types.GetBaseCoin()); balance.Cmp(big.NewInt(version)) != 0 {
+			t.Fatalf("Balance of %s should be %d, got %s", address.String(), version, balance)
+		}
+	}
+}
+
+func TestCorruptedChunk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minter_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := db.NewMemDB()
+	source.Set([]byte("key"), []byte("value"))
+
+	chunks, err := writeStateChunks(dir, exportDB(source), 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, chunks[0].File), []byte("corrupted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := readChunk(dir, chunks[0], db.NewMemDB().Set); err == nil {
+		t.Fatal("Corrupted chunk should not be restored")
+	}
+}
+
+func TestHeights(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minter_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"120", "1200", "240", "360.tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(dir, name, manifestFile), []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	heights, err := Heights(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(heights) != "[120 240 1200]" {
+		t.Fatalf("Unexpected heights %v", heights)
+	}
+}
+
+func exportDB(source db.DB) func(fn func(key, value []byte) error) error {
+	return func(fn func(key, value []byte) error) error {
+		it := source.Iterator(nil, nil)
+		defer it.Close()
+
+		for ; it.Valid(); it.Next() {
+			if err := fn(it.Key(), it.Value()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package state
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/eventsdb"
+	"github.com/MinterTeam/minter-go-node/eventsdb/events"
+	"math/big"
+)
+
+var autoCompoundPrefix = []byte("k")
+
+// IsAutoCompound reports whether delegator rewards of address are added to its BIP stakes instead of balance
+func (s *StateDB) IsAutoCompound(address types.Address) bool {
+	_, enc := s.iavl.Get(append(autoCompoundPrefix, address[:]...))
+	return len(enc) != 0
+}
+
+// SetAutoCompound stores the flag in the tree at once since it has no cached live object. SetAutoCompound tx calls it
+// only outside of check mode.
+func (s *StateDB) SetAutoCompound(address types.Address, enabled bool) {
+	key := append(autoCompoundPrefix, address[:]...)
+	if !enabled {
+		s.iavl.Remove(key)
+		return
+	}
+
+	s.iavl.Set(key, []byte{1})
+}
+
+// compoundedReward is a delegator reward which should be added to BIP stake of the delegator
+type compoundedReward struct {
+	owner  types.Address
+	reward *big.Int
+}
+
+// compoundReward adds delegator reward to BIP stake of owner on candidate and to total BIP stake of the candidate.
+// It returns false if owner has no BIP stake on candidate and the candidate already has max number of delegators,
+// such reward should be paid to balance.
+func (s *StateDB) compoundReward(edb eventsdb.IEventsDB, candidate *Candidate, owner types.Address,
+	reward *big.Int) bool {
+	stake := candidate.GetStakeOfAddress(owner, types.GetBaseCoin())
+	if stake == nil {
+		if len(candidate.Stakes) >= MaxDelegatorsPerCandidate {
+			return false
+		}
+
+		candidate.Stakes = append(candidate.Stakes, Stake{
+			Owner:    owner,
+			Coin:     types.GetBaseCoin(),
+			Value:    big.NewInt(0),
+			BipValue: big.NewInt(0),
+		})
+		stake = &candidate.Stakes[len(candidate.Stakes)-1]
+	}
+
+	stake.Value = big.NewInt(0).Add(stake.Value, reward)
+	stake.BipValue = big.NewInt(0).Add(stake.BipValue, reward)
+	candidate.TotalBipStake = big.NewInt(0).Add(candidate.TotalBipStake, reward)
+	s.MarkStateCandidateDirty()
+
+	edb.AddEvent(s.height, events.RewardEvent{
+		Role:            events.RoleAutoCompound,
+		Address:         owner,
+		Amount:          reward.Bytes(),
+		ValidatorPubKey: candidate.PubKey,
+	})
+
+	return true
+}
@@ -0,0 +1,210 @@
+package state
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/upgrades"
+	"math/big"
+	"testing"
+)
+
+func TestStateDB_PayRewardsAutoCompound(t *testing.T) {
+	s := getStateWithFeature(t, upgrades.FeatureAutoCompound)
+
+	owner := types.Address{1}
+	createTestValidator(s, owner, 100)
+	s.RecalculateTotalStakeValues()
+	pubKey := s.getStateValidators().data[0].PubKey
+
+	s.SetAutoCompound(owner, true)
+	if !s.IsAutoCompound(owner) {
+		t.Fatalf("Auto compound should be enabled")
+	}
+
+	s.AddAccumReward(pubKey, helpers.BipToPip(big.NewInt(100)))
+	s.PayRewards()
+
+	// 10% goes to DAO and developers each, the rest is paid to the only stake, 10% of it as validator commission
+	validatorReward := helpers.BipToPip(big.NewInt(8))
+	delegatorReward := helpers.BipToPip(big.NewInt(72))
+
+	if balance := s.GetBalance(owner, types.GetBaseCoin()); balance.Cmp(validatorReward) != 0 {
+		t.Fatalf("Only validator commission should be paid to balance, got %s", balance)
+	}
+
+	stake := s.GetStateCandidate(pubKey).GetStakeOfAddress(owner, types.GetBaseCoin()).Value
+	expectedStake := big.NewInt(0).Add(helpers.BipToPip(big.NewInt(100)), delegatorReward)
+	if stake.Cmp(expectedStake) != 0 {
+		t.Fatalf("Delegator reward should be added to stake, expected %s, got %s", expectedStake, stake)
+	}
+
+	if total := s.getStateValidators().data[0].TotalBipStake; total.Cmp(expectedStake) != 0 {
+		t.Fatalf("Delegator reward should be added to total stake of validator, expected %s, got %s",
+			expectedStake, total)
+	}
+
+	s.SetAutoCompound(owner, false)
+	if s.IsAutoCompound(owner) {
+		t.Fatalf("Auto compound should be disabled")
+	}
+
+	s.AddAccumReward(pubKey, helpers.BipToPip(big.NewInt(100)))
+	s.PayRewards()
+
+	expectedBalance := big.NewInt(0).Add(validatorReward, validatorReward)
+	expectedBalance.Add(expectedBalance, delegatorReward)
+	if balance := s.GetBalance(owner, types.GetBaseCoin()); balance.Cmp(expectedBalance) != 0 {
+		t.Fatalf("Rewards should be paid to balance after auto compound is disabled, expected %s, got %s",
+			expectedBalance, balance)
+	}
+}
+
+func TestStateDB_PayRewardsAutoCompoundDelegators(t *testing.T) {
+	s := getStateWithFeature(t, upgrades.FeatureAutoCompound)
+
+	owner := types.Address{1}
+	createTestValidator(s, owner, 100)
+	pubKey := s.getStateValidators().data[0].PubKey
+
+	delegator1, delegator2, delegator3 := types.Address{2}, types.Address{3}, types.Address{4}
+	s.Delegate(delegator1, pubKey, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(100)))
+	s.Delegate(delegator2, pubKey, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(200)))
+	s.Delegate(delegator3, pubKey, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(100)))
+	s.RecalculateTotalStakeValues()
+
+	s.SetAutoCompound(delegator1, true)
+	s.SetAutoCompound(delegator2, true)
+
+	s.AddAccumReward(pubKey, helpers.BipToPip(big.NewInt(100)))
+	s.PayRewards()
+
+	// 72 BIP are paid to stakes in proportion to their values, 14.4 BIP for every 100 BIP of stake
+	reward := big.NewInt(0).Div(helpers.BipToPip(big.NewInt(144)), big.NewInt(10))
+
+	expectedStakes := map[types.Address]*big.Int{
+		owner:      helpers.BipToPip(big.NewInt(100)),
+		delegator1: big.NewInt(0).Add(helpers.BipToPip(big.NewInt(100)), reward),
+		delegator2: big.NewInt(0).Add(helpers.BipToPip(big.NewInt(200)), big.NewInt(0).Mul(reward, big.NewInt(2))),
+		delegator3: helpers.BipToPip(big.NewInt(100)),
+	}
+
+	candidate := s.GetStateCandidate(pubKey)
+	if len(candidate.Stakes) != len(expectedStakes) {
+		t.Fatalf("Candidate should have %d stakes, got %d", len(expectedStakes), len(candidate.Stakes))
+	}
+
+	expectedTotal := big.NewInt(0)
+	for address, expected := range expectedStakes {
+		stake := candidate.GetStakeOfAddress(address, types.GetBaseCoin())
+		if stake.Value.Cmp(expected) != 0 {
+			t.Fatalf("Stake of %s should be %s, got %s", address.String(), expected, stake.Value)
+		}
+
+		if stake.BipValue.Cmp(expected) != 0 {
+			t.Fatalf("BIP value of stake of %s should be %s, got %s", address.String(), expected, stake.BipValue)
+		}
+
+		expectedTotal.Add(expectedTotal, expected)
+	}
+
+	if candidate.TotalBipStake.Cmp(expectedTotal) != 0 {
+		t.Fatalf("Total stake of candidate should be %s, got %s", expectedTotal, candidate.TotalBipStake)
+	}
+
+	if total := s.getStateValidators().data[0].TotalBipStake; total.Cmp(expectedTotal) != 0 {
+		t.Fatalf("Total stake of validator should be %s, got %s", expectedTotal, total)
+	}
+
+	if balance := s.GetBalance(delegator3, types.GetBaseCoin()); balance.Cmp(reward) != 0 {
+		t.Fatalf("Reward of delegator without auto compound should be paid to balance, got %s", balance)
+	}
+
+	s.RecalculateTotalStakeValues()
+	if total := s.getStateValidators().data[0].TotalBipStake; total.Cmp(expectedTotal) != 0 {
+		t.Fatalf("Recalculated total stake of validator should be %s, got %s", expectedTotal, total)
+	}
+}
+
+func TestStateDB_PayRewardsAutoCompoundBeforeActivation(t *testing.T) {
+	s := getState()
+
+	owner := types.Address{1}
+	createTestValidator(s, owner, 100)
+	pubKey := s.getStateValidators().data[0].PubKey
+
+	s.SetAutoCompound(owner, true)
+
+	s.AddAccumReward(pubKey, helpers.BipToPip(big.NewInt(100)))
+	s.PayRewards()
+
+	if balance := s.GetBalance(owner, types.GetBaseCoin()); balance.Cmp(helpers.BipToPip(big.NewInt(80))) != 0 {
+		t.Fatalf("All rewards should be paid to balance before activation of %s, got %s",
+			upgrades.FeatureAutoCompound, balance)
+	}
+
+	if stake := s.GetStateCandidate(pubKey).Stakes[0].Value; stake.Cmp(helpers.BipToPip(big.NewInt(100))) != 0 {
+		t.Fatalf("Stake should not change before activation of %s, got %s", upgrades.FeatureAutoCompound, stake)
+	}
+}
+
+func TestStateDB_PayRewardsAutoCompoundCustomCoinStake(t *testing.T) {
+	s := getStateWithFeature(t, upgrades.FeatureAutoCompound)
+
+	owner := types.Address{1}
+	createTestValidator(s, owner, 100)
+	pubKey := s.getStateValidators().data[0].PubKey
+
+	coin := types.StrToCoinSymbol("ABC")
+	s.CreateCoin(coin, "COIN", helpers.BipToPip(big.NewInt(100)), 30, helpers.BipToPip(big.NewInt(200)))
+
+	// custom coin stake of the delegator goes before its BIP stake
+	delegator := types.Address{2}
+	s.Delegate(delegator, pubKey, coin, helpers.BipToPip(big.NewInt(50)))
+	s.Delegate(delegator, pubKey, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(100)))
+	s.RecalculateTotalStakeValues()
+	s.SetAutoCompound(delegator, true)
+
+	candidate := s.GetStateCandidate(pubKey)
+	totalStake := big.NewInt(0).Set(s.getStateValidators().data[0].TotalBipStake)
+	totalReward := helpers.BipToPip(big.NewInt(72))
+
+	rewards := map[types.CoinSymbol]*big.Int{}
+	for _, stake := range candidate.Stakes {
+		if stake.Owner != delegator {
+			continue
+		}
+
+		reward := big.NewInt(0).Mul(totalReward, stake.BipValue)
+		rewards[stake.Coin] = reward.Div(reward, totalStake)
+	}
+
+	ownerReward := big.NewInt(0).Mul(totalReward, candidate.GetStakeOfAddress(owner, types.GetBaseCoin()).BipValue)
+	ownerReward.Div(ownerReward, totalStake)
+
+	bipStake := big.NewInt(0).Set(candidate.GetStakeOfAddress(delegator, types.GetBaseCoin()).Value)
+	totalSlashed := big.NewInt(0).Set(s.GetTotalSlashed())
+
+	s.AddAccumReward(pubKey, helpers.BipToPip(big.NewInt(100)))
+	s.PayRewards()
+
+	compounded := big.NewInt(0).Add(rewards[coin], rewards[types.GetBaseCoin()])
+	expectedStake := big.NewInt(0).Add(bipStake, compounded)
+	candidate = s.GetStateCandidate(pubKey)
+	if stake := candidate.GetStakeOfAddress(delegator, types.GetBaseCoin()).Value; stake.Cmp(expectedStake) != 0 {
+		t.Fatalf("Rewards of both stakes should be added to BIP stake, expected %s, got %s", expectedStake, stake)
+	}
+
+	expectedTotal := big.NewInt(0).Add(totalStake, compounded)
+	if total := s.getStateValidators().data[0].TotalBipStake; total.Cmp(expectedTotal) != 0 {
+		t.Fatalf("Total stake of validator should be %s, got %s", expectedTotal, total)
+	}
+
+	// reward of BIP stake is computed from its value before compounding, so nothing is overpaid
+	paid := big.NewInt(0).Add(compounded, ownerReward)
+	remainder := big.NewInt(0).Sub(totalReward, paid)
+	expectedSlashed := big.NewInt(0).Add(totalSlashed, remainder)
+	if slashed := s.GetTotalSlashed(); remainder.Sign() < 0 || slashed.Cmp(expectedSlashed) != 0 {
+		t.Fatalf("Remainder %s of rewards should be added to total slashed %s, got %s", remainder, totalSlashed,
+			slashed)
+	}
+}
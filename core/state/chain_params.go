@@ -0,0 +1,78 @@
+package state
+
+import (
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/rlp"
+)
+
+var chainParamsKey = []byte("p")
+
+// chainParamsVersion is stored before rlp encoded chain params. Params are encoded positionally, so the version
+// should be increased and the previous layout should be decoded explicitly whenever fields of ChainParams change.
+const chainParamsVersion byte = 1
+
+// GetChainParams returns parameters set in genesis of the chain and changed by governance proposals. Chains without
+// parameters in genesis, such as mainnet, have no parameters stored in state and use defaults until a proposal
+// changes them.
+func (s *StateDB) GetChainParams() types.ChainParams {
+	if params := s.getCachedChainParams(); params != nil {
+		return *params
+	}
+
+	params, ok := s.getStoredChainParams()
+	if !ok {
+		params = types.DefaultChainParams()
+	}
+
+	s.setCachedChainParams(&params)
+
+	return params
+}
+
+// SetChainParams stores chain parameters in state. It is called at genesis and when a param change proposal is
+// applied.
+func (s *StateDB) SetChainParams(params types.ChainParams) {
+	data, err := rlp.EncodeToBytes(params)
+	if err != nil {
+		panic(fmt.Errorf("can't encode chain params: %v", err))
+	}
+
+	s.iavl.Set(chainParamsKey, append([]byte{chainParamsVersion}, data...))
+	s.setCachedChainParams(nil)
+}
+
+// getStoredChainParams panics if stored params can't be decoded, falling back to defaults would silently change
+// consensus rules
+func (s *StateDB) getStoredChainParams() (types.ChainParams, bool) {
+	var params types.ChainParams
+
+	_, enc := s.iavl.Get(chainParamsKey)
+	if len(enc) == 0 {
+		return params, false
+	}
+
+	if enc[0] != chainParamsVersion {
+		panic(fmt.Errorf("unsupported chain params version %d, expected %d", enc[0], chainParamsVersion))
+	}
+
+	if err := rlp.DecodeBytes(enc[1:], &params); err != nil {
+		panic(fmt.Errorf("can't decode chain params: %v", err))
+	}
+
+	return params, true
+}
+
+func (s *StateDB) getCachedChainParams() *types.ChainParams {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.chainParams
+}
+
+func (s *StateDB) setCachedChainParams(params *types.ChainParams) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.chainParams = params
+}
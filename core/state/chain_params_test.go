@@ -0,0 +1,88 @@
+package state
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestStateDB_GetChainParamsDefaults(t *testing.T) {
+	state := getState()
+
+	if params := state.GetChainParams(); !reflect.DeepEqual(params, types.DefaultChainParams()) {
+		t.Fatalf("State without chain params should use defaults, got %+v", params)
+	}
+
+	appState := state.Export(1)
+	if appState.ChainParams != nil {
+		t.Fatalf("Default chain params should not be exported")
+	}
+}
+
+func TestStateDB_ChainParamsImportExport(t *testing.T) {
+	params := types.DefaultChainParams()
+	params.UnbondPeriod = 10
+	params.PayRewardsInterval = 2
+	params.Commissions.SendTx = 1
+
+	state := getState()
+	state.Import(types.AppState{
+		TotalSlashed: big.NewInt(0),
+		ChainParams:  &params,
+	})
+
+	if _, _, err := state.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if stored := state.GetChainParams(); !reflect.DeepEqual(stored, params) {
+		t.Fatalf("Stored chain params should be %+v, got %+v", params, stored)
+	}
+
+	appState := state.Export(1)
+	if appState.ChainParams == nil || !reflect.DeepEqual(*appState.ChainParams, params) {
+		t.Fatalf("Exported chain params should be %+v, got %+v", params, appState.ChainParams)
+	}
+}
+
+func TestStateDB_ChainParamsCache(t *testing.T) {
+	state := getState()
+
+	if state.GetChainParams().UnbondPeriod != types.DefaultChainParams().UnbondPeriod {
+		t.Fatal("State without chain params should use defaults")
+	}
+
+	params := types.DefaultChainParams()
+	params.UnbondPeriod = 10
+	state.SetChainParams(params)
+
+	if unbondPeriod := state.GetChainParams().UnbondPeriod; unbondPeriod != 10 {
+		t.Fatalf("Cached chain params should be cleared, got unbond period %d", unbondPeriod)
+	}
+}
+
+func TestStateDB_ChainParamsDecodeFailure(t *testing.T) {
+	state := getState()
+	state.SetChainParams(types.DefaultChainParams())
+
+	_, enc := state.iavl.Get(chainParamsKey)
+
+	for name, data := range map[string][]byte{
+		"unknown version": append([]byte{chainParamsVersion + 1}, enc[1:]...),
+		"truncated":       enc[:len(enc)/2],
+	} {
+		state.iavl.Set(chainParamsKey, data)
+		state.setCachedChainParams(nil)
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Chain params which are %s should not be decoded", name)
+				}
+			}()
+
+			state.GetChainParams()
+		}()
+	}
+}
@@ -0,0 +1,310 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"math/big"
+	"sort"
+)
+
+// Diff describes changes of the state between two heights
+type Diff struct {
+	FromHeight  uint64             `json:"from_height"`
+	ToHeight    uint64             `json:"to_height"`
+	Balances    []BalanceChange    `json:"balances"`
+	Stakes      []StakeChange      `json:"stakes"`
+	Coins       []CoinChange       `json:"coins"`
+	FrozenFunds []FrozenFundChange `json:"frozen_funds"`
+}
+
+type BalanceChange struct {
+	Address types.Address    `json:"address"`
+	Coin    types.CoinSymbol `json:"coin"`
+	From    *big.Int         `json:"from"`
+	To      *big.Int         `json:"to"`
+	Delta   *big.Int         `json:"delta"`
+}
+
+type StakeChange struct {
+	PubKey types.Pubkey     `json:"pub_key"`
+	Owner  types.Address    `json:"owner"`
+	Coin   types.CoinSymbol `json:"coin"`
+	From   *big.Int         `json:"from"`
+	To     *big.Int         `json:"to"`
+	Delta  *big.Int         `json:"delta"`
+}
+
+type CoinChange struct {
+	Symbol       types.CoinSymbol `json:"symbol"`
+	VolumeFrom   *big.Int         `json:"volume_from"`
+	VolumeTo     *big.Int         `json:"volume_to"`
+	VolumeDelta  *big.Int         `json:"volume_delta"`
+	ReserveFrom  *big.Int         `json:"reserve_from"`
+	ReserveTo    *big.Int         `json:"reserve_to"`
+	ReserveDelta *big.Int         `json:"reserve_delta"`
+}
+
+type FrozenFundChange struct {
+	Height       uint64           `json:"height"`
+	Address      types.Address    `json:"address"`
+	CandidateKey types.Pubkey     `json:"candidate_key"`
+	Coin         types.CoinSymbol `json:"coin"`
+	From         *big.Int         `json:"from"`
+	To           *big.Int         `json:"to"`
+	Delta        *big.Int         `json:"delta"`
+}
+
+// GetDiff compares two states key by key and returns decoded changes between them
+func GetDiff(from *StateDB, to *StateDB) *Diff {
+	diff := &Diff{
+		FromHeight:  from.height,
+		ToHeight:    to.height,
+		Balances:    []BalanceChange{},
+		Stakes:      []StakeChange{},
+		Coins:       []CoinChange{},
+		FrozenFunds: []FrozenFundChange{},
+	}
+
+	for _, key := range changedKeys(from, to) {
+		switch key[0] {
+		case addressPrefix[0]:
+			diff.Balances = append(diff.Balances, diffBalances(from, to, types.BytesToAddress(key[1:]))...)
+		case coinPrefix[0]:
+			if change := diffCoin(from, to, types.StrToCoinSymbol(string(key[1:]))); change != nil {
+				diff.Coins = append(diff.Coins, *change)
+			}
+		case frozenFundsPrefix[0]:
+			diff.FrozenFunds = append(diff.FrozenFunds,
+				diffFrozenFunds(from, to, binary.BigEndian.Uint64(key[1:]))...)
+		case candidatesKey[0]:
+			diff.Stakes = diffStakes(from, to)
+		}
+	}
+
+	return diff
+}
+
+// changedKeys returns sorted keys which were added, removed or changed between two states. Both trees are walked in
+// key order in parallel, so neither state is loaded into memory.
+func changedKeys(from *StateDB, to *StateDB) [][]byte {
+	done := make(chan struct{})
+	defer close(done)
+
+	fromPairs, toPairs := iterateTree(from.iavl, done), iterateTree(to.iavl, done)
+	fromPair, fromOk := <-fromPairs
+	toPair, toOk := <-toPairs
+
+	var keys [][]byte
+	for fromOk || toOk {
+		switch {
+		case !toOk || fromOk && bytes.Compare(fromPair.key, toPair.key) < 0:
+			// removed key
+			keys = append(keys, fromPair.key)
+			fromPair, fromOk = <-fromPairs
+		case !fromOk || bytes.Compare(fromPair.key, toPair.key) > 0:
+			// added key
+			keys = append(keys, toPair.key)
+			toPair, toOk = <-toPairs
+		default:
+			if !bytes.Equal(fromPair.value, toPair.value) {
+				keys = append(keys, toPair.key)
+			}
+			fromPair, fromOk = <-fromPairs
+			toPair, toOk = <-toPairs
+		}
+	}
+
+	return keys
+}
+
+type keyValue struct {
+	key   []byte
+	value []byte
+}
+
+// iterateTree streams key-value pairs of the tree in key order, iteration is stopped when done is closed
+func iterateTree(tree Tree, done <-chan struct{}) <-chan keyValue {
+	pairs := make(chan keyValue, 128)
+
+	go func() {
+		defer close(pairs)
+
+		tree.Iterate(func(key []byte, value []byte) bool {
+			select {
+			case pairs <- keyValue{key: key, value: value}:
+				return false
+			case <-done:
+				return true
+			}
+		})
+	}()
+
+	return pairs
+}
+
+func diffBalances(from *StateDB, to *StateDB, address types.Address) []BalanceChange {
+	fromBalances := from.GetBalances(address).Data
+	toBalances := to.GetBalances(address).Data
+
+	coins := map[types.CoinSymbol]struct{}{}
+	for coin := range fromBalances {
+		coins[coin] = struct{}{}
+	}
+	for coin := range toBalances {
+		coins[coin] = struct{}{}
+	}
+
+	var changes []BalanceChange
+	for coin := range coins {
+		fromValue, toValue := valueOrZero(fromBalances[coin]), valueOrZero(toBalances[coin])
+		if fromValue.Cmp(toValue) == 0 {
+			continue
+		}
+
+		changes = append(changes, BalanceChange{
+			Address: address,
+			Coin:    coin,
+			From:    fromValue,
+			To:      toValue,
+			Delta:   big.NewInt(0).Sub(toValue, fromValue),
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Coin.String() < changes[j].Coin.String()
+	})
+
+	return changes
+}
+
+func diffCoin(from *StateDB, to *StateDB, symbol types.CoinSymbol) *CoinChange {
+	volumeFrom, reserveFrom := big.NewInt(0), big.NewInt(0)
+	if coin := from.GetStateCoin(symbol); coin != nil {
+		volumeFrom, reserveFrom = coin.Volume(), coin.ReserveBalance()
+	}
+
+	volumeTo, reserveTo := big.NewInt(0), big.NewInt(0)
+	if coin := to.GetStateCoin(symbol); coin != nil {
+		volumeTo, reserveTo = coin.Volume(), coin.ReserveBalance()
+	}
+
+	if volumeFrom.Cmp(volumeTo) == 0 && reserveFrom.Cmp(reserveTo) == 0 {
+		return nil
+	}
+
+	return &CoinChange{
+		Symbol:       symbol,
+		VolumeFrom:   volumeFrom,
+		VolumeTo:     volumeTo,
+		VolumeDelta:  big.NewInt(0).Sub(volumeTo, volumeFrom),
+		ReserveFrom:  reserveFrom,
+		ReserveTo:    reserveTo,
+		ReserveDelta: big.NewInt(0).Sub(reserveTo, reserveFrom),
+	}
+}
+
+type stakeKey struct {
+	pubKey string
+	owner  types.Address
+	coin   types.CoinSymbol
+}
+
+func diffStakes(from *StateDB, to *StateDB) []StakeChange {
+	values := map[stakeKey][2]*big.Int{}
+	var keys []stakeKey
+
+	for i, s := range []*StateDB{from, to} {
+		candidates := s.getStateCandidates()
+		if candidates == nil {
+			continue
+		}
+
+		for _, candidate := range candidates.data {
+			for _, stake := range candidate.Stakes {
+				key := stakeKey{pubKey: string(candidate.PubKey), owner: stake.Owner, coin: stake.Coin}
+				value, exists := values[key]
+				if !exists {
+					keys = append(keys, key)
+				}
+				value[i] = stake.Value
+				values[key] = value
+			}
+		}
+	}
+
+	changes := []StakeChange{}
+	for _, key := range keys {
+		fromValue, toValue := valueOrZero(values[key][0]), valueOrZero(values[key][1])
+		if fromValue.Cmp(toValue) == 0 {
+			continue
+		}
+
+		changes = append(changes, StakeChange{
+			PubKey: types.Pubkey(key.pubKey),
+			Owner:  key.owner,
+			Coin:   key.coin,
+			From:   fromValue,
+			To:     toValue,
+			Delta:  big.NewInt(0).Sub(toValue, fromValue),
+		})
+	}
+
+	return changes
+}
+
+type frozenFundKey struct {
+	address      types.Address
+	candidateKey string
+	coin         types.CoinSymbol
+}
+
+func diffFrozenFunds(from *StateDB, to *StateDB, height uint64) []FrozenFundChange {
+	values := map[frozenFundKey][2]*big.Int{}
+	var keys []frozenFundKey
+
+	for i, s := range []*StateDB{from, to} {
+		frozenFunds := s.GetStateFrozenFunds(height)
+		if frozenFunds == nil {
+			continue
+		}
+
+		for _, fund := range frozenFunds.List() {
+			key := frozenFundKey{address: fund.Address, candidateKey: string(fund.CandidateKey), coin: fund.Coin}
+			value, exists := values[key]
+			if !exists {
+				keys = append(keys, key)
+			}
+			value[i] = big.NewInt(0).Add(valueOrZero(value[i]), fund.Value)
+			values[key] = value
+		}
+	}
+
+	var changes []FrozenFundChange
+	for _, key := range keys {
+		fromValue, toValue := valueOrZero(values[key][0]), valueOrZero(values[key][1])
+		if fromValue.Cmp(toValue) == 0 {
+			continue
+		}
+
+		changes = append(changes, FrozenFundChange{
+			Height:       height,
+			Address:      key.address,
+			CandidateKey: types.Pubkey(key.candidateKey),
+			Coin:         key.coin,
+			From:         fromValue,
+			To:           toValue,
+			Delta:        big.NewInt(0).Sub(toValue, fromValue),
+		})
+	}
+
+	return changes
+}
+
+func valueOrZero(value *big.Int) *big.Int {
+	if value == nil {
+		return big.NewInt(0)
+	}
+
+	return value
+}
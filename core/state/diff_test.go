@@ -0,0 +1,92 @@
+package state
+
+import (
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/tendermint/tendermint/libs/db"
+	"math/big"
+	"testing"
+)
+
+func TestGetDiff(t *testing.T) {
+	memDB := db.NewMemDB()
+	state, err := New(0, memDB, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := types.HexToAddress("Mx02003587993aba5276925c058ba082d209e61cbb")
+	symbol := types.StrToCoinSymbol("TEST")
+
+	state.SetBalance(address, types.GetBaseCoin(), big.NewInt(100))
+	state.CreateCoin(symbol, "TEST NAME", big.NewInt(10), 10, big.NewInt(10))
+	if _, _, err := state.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	state.SubBalance(address, types.GetBaseCoin(), big.NewInt(30))
+	state.AddBalance(address, symbol, big.NewInt(5))
+	state.AddCoinVolume(symbol, big.NewInt(5))
+	state.AddCoinReserve(symbol, big.NewInt(30))
+	if _, _, err := state.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := NewForCheck(1, memDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := NewForCheck(2, memDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := GetDiff(from, to)
+
+	if len(diff.Balances) != 2 {
+		t.Fatalf("Expected 2 balance changes, got %d", len(diff.Balances))
+	}
+
+	for _, change := range diff.Balances {
+		var target *big.Int
+		switch change.Coin {
+		case types.GetBaseCoin():
+			target = big.NewInt(-30)
+		case symbol:
+			target = big.NewInt(5)
+		}
+
+		if target == nil || change.Delta.Cmp(target) != 0 {
+			t.Fatalf("Unexpected balance change of %s: %s", change.Coin, change.Delta)
+		}
+	}
+
+	if len(diff.Coins) != 1 {
+		t.Fatalf("Expected 1 coin change, got %d", len(diff.Coins))
+	}
+
+	if diff.Coins[0].VolumeDelta.Cmp(big.NewInt(5)) != 0 || diff.Coins[0].ReserveDelta.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("Unexpected coin change: volume %s, reserve %s", diff.Coins[0].VolumeDelta,
+			diff.Coins[0].ReserveDelta)
+	}
+}
+
+func TestChangedKeys(t *testing.T) {
+	from, to := NewMutableTree(db.NewMemDB()), NewMutableTree(db.NewMemDB())
+
+	for _, key := range []string{"a", "b", "c", "e"} {
+		from.Set([]byte(key), []byte("value"))
+	}
+
+	// "a" is removed, "c" is changed, "d" and "f" are added
+	for _, key := range []string{"b", "d", "e", "f"} {
+		to.Set([]byte(key), []byte("value"))
+	}
+	to.Set([]byte("c"), []byte("new value"))
+
+	keys := changedKeys(&StateDB{iavl: from}, &StateDB{iavl: to})
+	if fmt.Sprintf("%s", keys) != "[a c d f]" {
+		t.Fatalf("Unexpected changed keys %s", keys)
+	}
+}
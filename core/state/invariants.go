@@ -0,0 +1,343 @@
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/MinterTeam/go-amino"
+	"github.com/MinterTeam/minter-go-node/core/rewards"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/core/validators"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/upgrades"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	tmTypes "github.com/tendermint/tendermint/types"
+	"math/big"
+	"sort"
+)
+
+// CriticalEmissionDelta is a difference between expected and actual BIP emission which is considered critical
+var CriticalEmissionDelta = helpers.BipToPip(big.NewInt(1000))
+
+// InvariantsReport holds results of state invariants check
+type InvariantsReport struct {
+	Height            uint64             `json:"height"`
+	Coins             []CoinInvariant    `json:"coins"`
+	Emission          EmissionInvariant  `json:"emission"`
+	NegativeBalances  []NegativeBalance  `json:"negative_balances"`
+	OrphanStakes      []OrphanStake      `json:"orphan_stakes"`
+	OrphanFrozenFunds []OrphanFrozenFund `json:"orphan_frozen_funds"`
+	Violations        []string           `json:"violations"`
+}
+
+// CoinInvariant compares coin volume and reserve with amounts actually held in the state
+type CoinInvariant struct {
+	Symbol         types.CoinSymbol `json:"symbol"`
+	ExpectedVolume *big.Int         `json:"expected_volume"`
+	ActualVolume   *big.Int         `json:"actual_volume"`
+	Reserve        *big.Int         `json:"reserve"`
+	Valid          bool             `json:"valid"`
+}
+
+// EmissionInvariant compares total BIP emission with rewards schedule
+type EmissionInvariant struct {
+	Expected     *big.Int `json:"expected"`
+	Actual       *big.Int `json:"actual"`
+	TotalSlashed *big.Int `json:"total_slashed"`
+	Delta        *big.Int `json:"delta"`
+}
+
+type NegativeBalance struct {
+	Address types.Address    `json:"address"`
+	Coin    types.CoinSymbol `json:"coin"`
+	Value   *big.Int         `json:"value"`
+}
+
+type OrphanStake struct {
+	PubKey types.Pubkey     `json:"pub_key"`
+	Owner  types.Address    `json:"owner"`
+	Coin   types.CoinSymbol `json:"coin"`
+	Value  *big.Int         `json:"value"`
+}
+
+type OrphanFrozenFund struct {
+	Height  uint64           `json:"height"`
+	Address types.Address    `json:"address"`
+	Coin    types.CoinSymbol `json:"coin"`
+	Value   *big.Int         `json:"value"`
+}
+
+// IsValid returns true if no invariant is violated
+func (r *InvariantsReport) IsValid() bool {
+	return len(r.Violations) == 0
+}
+
+// IsCritical returns true if BIP emission is less than expected one by more than CriticalEmissionDelta
+func (r *InvariantsReport) IsCritical() bool {
+	return r.Emission.Delta.Cmp(CriticalEmissionDelta) == 1
+}
+
+func (r *InvariantsReport) violation(format string, args ...interface{}) {
+	r.Violations = append(r.Violations, fmt.Sprintf(format, args...))
+}
+
+// LoadGenesisAppState reads app state from given genesis file
+func LoadGenesisAppState(genesisFile string) (types.AppState, error) {
+	var genesisState types.AppState
+
+	genesis, err := tmTypes.GenesisDocFromFile(genesisFile)
+	if err != nil {
+		return genesisState, err
+	}
+
+	err = amino.UnmarshalJSON(genesis.AppState, &genesisState)
+	return genesisState, err
+}
+
+// CheckInvariants checks state against invariants and returns a report with all findings
+func (s *StateDB) CheckInvariants(genesisState types.AppState) *InvariantsReport {
+	params := s.GetChainParams()
+	emission := rewards.GetRewardsBeforeGenesis(params)
+	for i := uint64(1); i < s.height; i++ {
+		emission.Add(emission, rewards.GetRewardForBlock(params, i))
+	}
+	emission.Sub(emission, s.GetTotalSlashed())
+
+	if upgrades.IsActive(upgrades.FeatureEmissionCorrection, s.height) {
+		d, _ := big.NewInt(0).SetString("35703071844419651412692", 10)
+		emission.Sub(emission, d)
+	}
+
+	return s.checkInvariants(genesisState, emission)
+}
+
+// CheckInvariantsWithEmission checks state against invariants. BIP emission is expected to be equal to genesis
+// allocation plus given emission, so it can be used for states which do not follow rewards schedule, e.g. simulations.
+func (s *StateDB) CheckInvariantsWithEmission(genesisState types.AppState, emission *big.Int) *InvariantsReport {
+	return s.checkInvariants(genesisState, emission)
+}
+
+// CheckAppStateInvariants imports app state into an in-memory state and checks it against invariants. App state is
+// treated as a genesis of a new chain, so BIP emission should be equal to its allocation.
+func CheckAppStateInvariants(appState types.AppState) (*InvariantsReport, error) {
+	memDB := dbm.NewMemDB()
+	s, err := New(0, memDB, false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Import(appState)
+	if _, _, err := s.Commit(); err != nil {
+		return nil, err
+	}
+
+	cState, err := NewForCheck(1, memDB)
+	if err != nil {
+		return nil, err
+	}
+
+	// rewards accumulated by validators are not paid yet, but they are already emitted
+	emission := big.NewInt(0)
+	for _, val := range appState.Validators {
+		emission.Add(emission, val.AccumReward)
+	}
+
+	return cState.checkInvariants(appState, emission), nil
+}
+
+// checkInvariants checks state against invariants. BIP emission is expected to be equal to genesis allocation plus
+// given emission.
+func (s *StateDB) checkInvariants(genesisState types.AppState, emission *big.Int) *InvariantsReport {
+	height := s.height
+
+	report := &InvariantsReport{
+		Height:            height,
+		Coins:             []CoinInvariant{},
+		NegativeBalances:  []NegativeBalance{},
+		OrphanStakes:      []OrphanStake{},
+		OrphanFrozenFunds: []OrphanFrozenFund{},
+		Violations:        []string{},
+	}
+
+	genesisAlloc := big.NewInt(0)
+	for _, account := range genesisState.Accounts {
+		for _, bal := range account.Balance {
+			if bal.Coin.IsBaseCoin() {
+				genesisAlloc.Add(genesisAlloc, bal.Value)
+			}
+		}
+	}
+
+	for _, candidate := range genesisState.Candidates {
+		for _, stake := range candidate.Stakes {
+			if stake.Coin.IsBaseCoin() {
+				genesisAlloc.Add(genesisAlloc, stake.Value)
+			}
+		}
+	}
+
+	for _, coin := range genesisState.Coins {
+		genesisAlloc.Add(genesisAlloc, coin.ReserveBalance)
+	}
+
+	for _, ff := range genesisState.FrozenFunds {
+		if ff.Coin.IsBaseCoin() {
+			genesisAlloc.Add(genesisAlloc, ff.Value)
+		}
+	}
+
+	totalBasecoinVolume := big.NewInt(0)
+
+	coins := map[types.CoinSymbol]*stateCoin{}
+	coinTotalOwned := map[types.CoinSymbol]*big.Int{}
+	addOwned := func(coin types.CoinSymbol, value *big.Int) {
+		if coin.IsBaseCoin() {
+			totalBasecoinVolume.Add(totalBasecoinVolume, value)
+			return
+		}
+
+		if coinTotalOwned[coin] == nil {
+			coinTotalOwned[coin] = big.NewInt(0)
+		}
+		coinTotalOwned[coin].Add(coinTotalOwned[coin], value)
+	}
+
+	var frozenFunds []*stateFrozenFund
+
+	s.iavl.Iterate(func(key []byte, value []byte) bool {
+		if key[0] == addressPrefix[0] {
+			account := s.GetOrNewStateObject(types.BytesToAddress(key[1:]))
+
+			for coin, value := range account.Balances().Data {
+				if value.Sign() == -1 {
+					report.NegativeBalances = append(report.NegativeBalances, NegativeBalance{
+						Address: account.address,
+						Coin:    coin,
+						Value:   value,
+					})
+					report.violation("negative balance of %s at %s: %s", coin, account.address, value)
+				}
+
+				addOwned(coin, value)
+			}
+		}
+
+		if key[0] == coinPrefix[0] {
+			coin := s.GetStateCoin(types.StrToCoinSymbol(string(key[1:])))
+
+			totalBasecoinVolume.Add(totalBasecoinVolume, coin.ReserveBalance())
+			coins[coin.symbol] = coin
+		}
+
+		if key[0] == frozenFundsPrefix[0] {
+			frozenFunds = append(frozenFunds, s.GetStateFrozenFunds(binary.BigEndian.Uint64(key[1:])))
+		}
+
+		return false
+	})
+
+	// frozen funds are checked after iteration, when all coins are known
+	for _, ff := range frozenFunds {
+		for _, frozenFund := range ff.List() {
+			if !frozenFund.Coin.IsBaseCoin() && coins[frozenFund.Coin] == nil {
+				report.OrphanFrozenFunds = append(report.OrphanFrozenFunds, OrphanFrozenFund{
+					Height:  ff.BlockHeight(),
+					Address: frozenFund.Address,
+					Coin:    frozenFund.Coin,
+					Value:   frozenFund.Value,
+				})
+				report.violation("frozen funds of %s at block %d refer to missing coin %s", frozenFund.Address,
+					ff.BlockHeight(), frozenFund.Coin)
+			}
+
+			addOwned(frozenFund.Coin, frozenFund.Value)
+		}
+	}
+
+	candidates := s.getStateCandidates()
+	if candidates == nil {
+		candidates = newCandidate(s, Candidates{}, s.MarkStateCandidateDirty)
+	}
+
+	params := s.GetChainParams()
+	if candsCount := len(candidates.data); candsCount > validators.CandidatesCountForBlock(params, height) {
+		report.violation("too many candidates in blockchain. Expected %d, got %d",
+			validators.CandidatesCountForBlock(params, height), candsCount)
+	}
+
+	for _, candidate := range candidates.data {
+		for _, stake := range candidate.Stakes {
+			if !stake.Coin.IsBaseCoin() && coins[stake.Coin] == nil {
+				report.OrphanStakes = append(report.OrphanStakes, OrphanStake{
+					PubKey: candidate.PubKey,
+					Owner:  stake.Owner,
+					Coin:   stake.Coin,
+					Value:  stake.Value,
+				})
+				report.violation("stake of %s in %s refers to missing coin %s", stake.Owner, candidate.PubKey,
+					stake.Coin)
+			}
+
+			addOwned(stake.Coin, stake.Value)
+		}
+	}
+
+	vals := s.getStateValidators()
+	if vals == nil {
+		vals = newValidator(s, Validators{}, s.MarkStateValidatorsDirty)
+	}
+
+	if valsCount := len(vals.data); valsCount > validators.ValidatorsCountForBlock(params, height) {
+		report.violation("too many validators in blockchain. Expected %d, got %d",
+			validators.ValidatorsCountForBlock(params, height), valsCount)
+	}
+
+	for _, val := range vals.data {
+		totalBasecoinVolume.Add(totalBasecoinVolume, val.AccumReward)
+	}
+
+	predictedBasecoinVolume := big.NewInt(0).Set(emission)
+	predictedBasecoinVolume.Add(predictedBasecoinVolume, genesisAlloc)
+
+	report.Emission = EmissionInvariant{
+		Expected:     predictedBasecoinVolume,
+		Actual:       totalBasecoinVolume,
+		TotalSlashed: s.GetTotalSlashed(),
+		Delta:        big.NewInt(0).Sub(predictedBasecoinVolume, totalBasecoinVolume),
+	}
+
+	if report.Emission.Delta.Sign() != 0 {
+		report.violation("smth wrong with total base coins in blockchain. Expected total supply to be %s, got %s",
+			predictedBasecoinVolume, totalBasecoinVolume)
+	}
+
+	for symbol, coin := range coins {
+		owned := coinTotalOwned[symbol]
+		if owned == nil {
+			owned = big.NewInt(0)
+		}
+
+		invariant := CoinInvariant{
+			Symbol:         symbol,
+			ExpectedVolume: owned,
+			ActualVolume:   coin.Volume(),
+			Reserve:        coin.ReserveBalance(),
+			Valid:          coin.Volume().Cmp(owned) == 0 && coin.ReserveBalance().Sign() == 1,
+		}
+		report.Coins = append(report.Coins, invariant)
+
+		if coin.Volume().Cmp(owned) != 0 {
+			report.violation("smth wrong with %s coin in blockchain. Total supply (%s) does not match total owned (%s)",
+				symbol, coin.Volume(), owned)
+		}
+
+		if coin.ReserveBalance().Sign() != 1 {
+			report.violation("reserve of %s coin is not positive: %s", symbol, coin.ReserveBalance())
+		}
+	}
+
+	sort.Slice(report.Coins, func(i, j int) bool {
+		return report.Coins[i].Symbol.String() < report.Coins[j].Symbol.String()
+	})
+
+	return report
+}
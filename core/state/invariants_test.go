@@ -0,0 +1,95 @@
+package state
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/tendermint/tendermint/libs/db"
+	"math/big"
+	"testing"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	memDB := db.NewMemDB()
+	state, err := New(0, memDB, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := types.HexToAddress("Mx02003587993aba5276925c058ba082d209e61cbb")
+	symbol := types.StrToCoinSymbol("TEST")
+
+	state.CreateCoin(symbol, "TEST NAME", big.NewInt(10), 10, big.NewInt(10))
+	state.SetBalance(address, symbol, big.NewInt(5))
+	if _, _, err := state.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	cState, err := NewForCheck(1, memDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesis := types.AppState{
+		Coins: []types.Coin{{Symbol: symbol, Volume: big.NewInt(10), ReserveBalance: big.NewInt(10)}},
+	}
+
+	report := cState.CheckInvariants(genesis)
+
+	if report.IsValid() {
+		t.Fatal("Report should contain violations")
+	}
+
+	if report.Emission.Delta.Sign() != 0 {
+		t.Fatalf("Emission delta should be 0, got %s", report.Emission.Delta)
+	}
+
+	if len(report.Coins) != 1 || report.Coins[0].Valid {
+		t.Fatalf("Coin %s should be invalid", symbol)
+	}
+
+	if report.Coins[0].ExpectedVolume.Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("Expected volume should be 5, got %s", report.Coins[0].ExpectedVolume)
+	}
+}
+
+func TestCheckAppStateInvariants(t *testing.T) {
+	address := types.HexToAddress("Mx02003587993aba5276925c058ba082d209e61cbb")
+	symbol := types.StrToCoinSymbol("TEST")
+
+	appState := types.AppState{
+		Accounts: []types.Account{{
+			Address: address,
+			Balance: []types.Balance{
+				{Coin: types.GetBaseCoin(), Value: big.NewInt(100)},
+				{Coin: symbol, Value: big.NewInt(10)},
+			},
+		}},
+		Coins: []types.Coin{{
+			Name:           "TEST NAME",
+			Symbol:         symbol,
+			Volume:         big.NewInt(10),
+			Crr:            10,
+			ReserveBalance: big.NewInt(10),
+		}},
+		TotalSlashed: big.NewInt(5),
+	}
+
+	report, err := CheckAppStateInvariants(appState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.IsValid() {
+		t.Fatalf("App state should be valid, got violations: %v", report.Violations)
+	}
+
+	appState.Coins[0].Volume = big.NewInt(20)
+
+	report, err = CheckAppStateInvariants(appState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.IsValid() {
+		t.Fatal("Report should contain violations")
+	}
+}
@@ -0,0 +1,114 @@
+package state
+
+import (
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/eventsdb/events"
+	"github.com/MinterTeam/minter-go-node/log"
+	"github.com/MinterTeam/minter-go-node/rlp"
+)
+
+var jailPrefix = []byte("j")
+
+// maxJailSlashPercent limits slashing of repeatedly jailed validators
+const maxJailSlashPercent = 10
+
+// JailRecord holds jail status and history of a candidate. Records exist only for candidates which were jailed.
+type JailRecord struct {
+	Jailed      bool
+	JailedUntil uint64
+	Offences    uint64
+	History     []JailEntry
+}
+
+type JailEntry struct {
+	Height       uint64
+	JailedUntil  uint64
+	SlashPercent uint64
+	UnjailedAt   uint64
+}
+
+// GetJailRecord returns jail record of a candidate or nil if candidate was never jailed
+func (s *StateDB) GetJailRecord(pubKey types.Pubkey) *JailRecord {
+	_, enc := s.iavl.Get(append(jailPrefix, pubKey...))
+	if len(enc) == 0 {
+		return nil
+	}
+
+	var record JailRecord
+	if err := rlp.DecodeBytes(enc, &record); err != nil {
+		log.Error("Failed to decode jail record", "pubkey", pubKey, "err", err)
+		return nil
+	}
+
+	return &record
+}
+
+func (s *StateDB) IsCandidateJailed(pubKey types.Pubkey) bool {
+	record := s.GetJailRecord(pubKey)
+	return record != nil && record.Jailed
+}
+
+// UnjailCandidate releases candidate from jail and sets it online
+func (s *StateDB) UnjailCandidate(pubKey types.Pubkey) {
+	record := s.GetJailRecord(pubKey)
+	if record == nil || !record.Jailed {
+		return
+	}
+
+	record.Jailed = false
+	record.History[len(record.History)-1].UnjailedAt = s.height
+	s.setJailRecord(pubKey, *record)
+
+	s.SetCandidateOnline(pubKey)
+
+	s.events.AddEvent(s.height, events.UnjailEvent{
+		ValidatorPubKey: pubKey,
+	})
+}
+
+// jailCandidate jails candidate for jail period multiplied by number of its offences and returns percent of stakes
+// which should be slashed, it also grows with number of offences
+func (s *StateDB) jailCandidate(pubKey types.Pubkey, jailPeriod uint64) uint64 {
+	record := s.GetJailRecord(pubKey)
+	if record == nil {
+		record = &JailRecord{}
+	}
+
+	record.Offences++
+
+	slashPercent := record.Offences
+	if slashPercent > maxJailSlashPercent {
+		slashPercent = maxJailSlashPercent
+	}
+
+	record.Jailed = true
+	record.JailedUntil = s.height + jailPeriod*record.Offences
+	record.History = append(record.History, JailEntry{
+		Height:       s.height,
+		JailedUntil:  record.JailedUntil,
+		SlashPercent: slashPercent,
+	})
+
+	s.setJailRecord(pubKey, *record)
+
+	s.events.AddEvent(s.height, events.JailEvent{
+		ValidatorPubKey: pubKey,
+		JailedUntil:     record.JailedUntil,
+		Offences:        record.Offences,
+		SlashPercent:    slashPercent,
+	})
+
+	return slashPercent
+}
+
+// setJailRecord writes to the tree directly: jail records are read from it on every access and never cached. They
+// change only in deliver state, when validators are jailed at the end of a block or unjailed by Unjail tx.
+func (s *StateDB) setJailRecord(pubKey types.Pubkey, record JailRecord) {
+	data, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		panic(fmt.Errorf("can't encode jail record: %v", err))
+	}
+
+	s.iavl.Set(append(jailPrefix, pubKey...), data)
+}
@@ -0,0 +1,169 @@
+package state
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/upgrades"
+	"math/big"
+	"testing"
+)
+
+// setAbsentUntilDropped marks validator absent in consecutive blocks until it exceeds max absent times
+func setAbsentUntilDropped(s *StateDB, address [20]byte) {
+	for i := uint64(0); i <= s.GetChainParams().ValidatorMaxAbsentTimes; i++ {
+		s.height++
+		s.SetValidatorAbsent(address)
+	}
+}
+
+func TestStateDB_JailCandidate(t *testing.T) {
+	s := getStateWithFeature(t, upgrades.FeatureJail)
+
+	params := s.GetChainParams()
+	params.JailPeriod = 100
+	s.SetChainParams(params)
+
+	owner := types.Address{1}
+	createTestValidator(s, owner, 100)
+
+	validator := s.getStateValidators().data[0]
+	pubKey := validator.PubKey
+	s.SetCandidateOnline(pubKey)
+
+	setAbsentUntilDropped(s, validator.GetAddress())
+
+	record := s.GetJailRecord(pubKey)
+	if record == nil || !record.Jailed {
+		t.Fatalf("Candidate should be jailed")
+	}
+
+	if record.JailedUntil != s.Height()+params.JailPeriod {
+		t.Fatalf("Candidate should be jailed until %d, got %d", s.Height()+params.JailPeriod, record.JailedUntil)
+	}
+
+	if stake := s.GetStateCandidate(pubKey).Stakes[0].Value; stake.Cmp(helpers.BipToPip(big.NewInt(99))) != 0 {
+		t.Fatalf("1%% of stake should be slashed for the first offence, got stake %s", stake)
+	}
+
+	s.UnjailCandidate(pubKey)
+
+	if s.IsCandidateJailed(pubKey) {
+		t.Fatalf("Candidate should be unjailed")
+	}
+
+	if status := s.GetStateCandidate(pubKey).Status; status != CandidateStatusOnline {
+		t.Fatalf("Unjailed candidate should be online, got status %d", status)
+	}
+
+	setAbsentUntilDropped(s, validator.GetAddress())
+
+	record = s.GetJailRecord(pubKey)
+	if record.Offences != 2 || len(record.History) != 2 {
+		t.Fatalf("Candidate should have 2 offences in history, got %d", len(record.History))
+	}
+
+	if record.History[0].UnjailedAt == 0 {
+		t.Fatalf("First jail entry should have unjail height")
+	}
+
+	if record.JailedUntil != s.Height()+2*params.JailPeriod {
+		t.Fatalf("Jail period should grow with number of offences, jailed until %d", record.JailedUntil)
+	}
+
+	if slashPercent := record.History[1].SlashPercent; slashPercent != 2 {
+		t.Fatalf("2%% of stake should be slashed for the second offence, got %d%%", slashPercent)
+	}
+}
+
+func TestStateDB_JailDisabled(t *testing.T) {
+	s := getState()
+
+	owner := types.Address{1}
+	createTestValidator(s, owner, 100)
+
+	validator := s.getStateValidators().data[0]
+	s.SetCandidateOnline(validator.PubKey)
+
+	setAbsentUntilDropped(s, validator.GetAddress())
+
+	if record := s.GetJailRecord(validator.PubKey); record != nil {
+		t.Fatalf("Candidate should not be jailed when jail period is 0")
+	}
+
+	if status := s.GetStateCandidate(validator.PubKey).Status; status != CandidateStatusOffline {
+		t.Fatalf("Candidate should be set offline, got status %d", status)
+	}
+}
+
+func TestStateDB_JailPenaltyGrowth(t *testing.T) {
+	s := getStateWithFeature(t, upgrades.FeatureJail)
+
+	params := s.GetChainParams()
+	params.JailPeriod = 10
+	s.SetChainParams(params)
+
+	owner := types.Address{1}
+	createTestValidator(s, owner, 1000)
+
+	validator := s.getStateValidators().data[0]
+	pubKey := validator.PubKey
+	s.SetCandidateOnline(pubKey)
+
+	stake := helpers.BipToPip(big.NewInt(1000))
+	for offence := uint64(1); offence <= maxJailSlashPercent+2; offence++ {
+		setAbsentUntilDropped(s, validator.GetAddress())
+
+		record := s.GetJailRecord(pubKey)
+		if record == nil || !record.Jailed || record.Offences != offence {
+			t.Fatalf("Candidate should be jailed for offence %d, got %+v", offence, record)
+		}
+
+		slashPercent := offence
+		if slashPercent > maxJailSlashPercent {
+			slashPercent = maxJailSlashPercent
+		}
+
+		if entry := record.History[offence-1]; entry.SlashPercent != slashPercent {
+			t.Fatalf("%d%% of stake should be slashed for offence %d, got %d%%", slashPercent, offence,
+				entry.SlashPercent)
+		}
+
+		if record.JailedUntil != s.Height()+offence*params.JailPeriod {
+			t.Fatalf("Candidate should be jailed until %d for offence %d, got %d",
+				s.Height()+offence*params.JailPeriod, offence, record.JailedUntil)
+		}
+
+		stake.Mul(stake, big.NewInt(int64(100-slashPercent)))
+		stake.Div(stake, big.NewInt(100))
+		if value := s.GetStateCandidate(pubKey).Stakes[0].Value; value.Cmp(stake) != 0 {
+			t.Fatalf("Stake should be %s after offence %d, got %s", stake, offence, value)
+		}
+
+		s.height = record.JailedUntil
+		s.UnjailCandidate(pubKey)
+	}
+}
+
+func TestStateDB_JailBeforeActivation(t *testing.T) {
+	s := getState()
+
+	params := s.GetChainParams()
+	params.JailPeriod = 100
+	s.SetChainParams(params)
+
+	owner := types.Address{1}
+	createTestValidator(s, owner, 100)
+
+	validator := s.getStateValidators().data[0]
+	s.SetCandidateOnline(validator.PubKey)
+
+	setAbsentUntilDropped(s, validator.GetAddress())
+
+	if record := s.GetJailRecord(validator.PubKey); record != nil {
+		t.Fatalf("Candidate should not be jailed before activation of %s", upgrades.FeatureJail)
+	}
+
+	if stake := s.GetStateCandidate(validator.PubKey).Stakes[0].Value; stake.Cmp(helpers.BipToPip(big.NewInt(99))) != 0 {
+		t.Fatalf("1%% of stake should be slashed before activation of %s, got stake %s", upgrades.FeatureJail, stake)
+	}
+}
@@ -0,0 +1,444 @@
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/eventsdb/events"
+	"github.com/MinterTeam/minter-go-node/log"
+	"github.com/MinterTeam/minter-go-node/rlp"
+	"math/big"
+)
+
+var (
+	// proposals are stored under o<id>, votes of a proposal under o<id>v
+	proposalsPrefix = []byte("o")
+	// proposalsCounterKey holds id of the last created proposal
+	proposalsCounterKey = []byte("oc")
+	// activeProposalsKey holds ids of proposals which are being voted for or passed and not applied yet
+	activeProposalsKey = []byte("oa")
+)
+
+type ProposalType byte
+
+const (
+	// ProposalTypeParamChange sets chain param Param to Value at Height
+	ProposalTypeParamChange ProposalType = 0x01
+	// ProposalTypeUpgrade schedules an upgrade named Param at Height
+	ProposalTypeUpgrade ProposalType = 0x02
+)
+
+func (t ProposalType) String() string {
+	switch t {
+	case ProposalTypeParamChange:
+		return "param_change"
+	case ProposalTypeUpgrade:
+		return "upgrade"
+	}
+
+	return "undefined"
+}
+
+type ProposalStatus byte
+
+const (
+	ProposalStatusVoting ProposalStatus = iota + 1
+	ProposalStatusPassed
+	ProposalStatusRejected
+	ProposalStatusApplied
+	ProposalStatusFailed
+)
+
+func (s ProposalStatus) String() string {
+	switch s {
+	case ProposalStatusVoting:
+		return "voting"
+	case ProposalStatusPassed:
+		return "passed"
+	case ProposalStatusRejected:
+		return "rejected"
+	case ProposalStatusApplied:
+		return "applied"
+	case ProposalStatusFailed:
+		return "failed"
+	}
+
+	return "undefined"
+}
+
+type VoteOption byte
+
+const (
+	VoteYes VoteOption = iota + 1
+	VoteNo
+	VoteAbstain
+)
+
+func (o VoteOption) String() string {
+	switch o {
+	case VoteYes:
+		return "yes"
+	case VoteNo:
+		return "no"
+	case VoteAbstain:
+		return "abstain"
+	}
+
+	return "undefined"
+}
+
+type Proposal struct {
+	ID              uint64
+	Proposer        types.Address
+	Type            ProposalType
+	Param           string
+	Value           uint64
+	Height          uint64
+	VotingEndHeight uint64
+	Status          ProposalStatus
+	Tally           ProposalTally
+}
+
+type ProposalVote struct {
+	Voter  types.Address
+	Option VoteOption
+}
+
+// ProposalTally is a sum of stakes of validators which voted for each option and a total stake of validators
+type ProposalTally struct {
+	Yes     *big.Int
+	No      *big.Int
+	Abstain *big.Int
+	Total   *big.Int
+}
+
+// IsPassed reports whether more than 2/3 of the total stake of validators voted for the proposal
+func (t ProposalTally) IsPassed() bool {
+	yes := big.NewInt(0).Mul(t.Yes, big.NewInt(3))
+	total := big.NewInt(0).Mul(t.Total, big.NewInt(2))
+
+	return t.Total.Sign() > 0 && yes.Cmp(total) == 1
+}
+
+// GetProposals returns all proposals ordered by id
+func (s *StateDB) GetProposals() []Proposal {
+	var proposals []Proposal
+
+	for id := uint64(1); id <= s.lastProposalID(); id++ {
+		if proposal := s.GetProposal(id); proposal != nil {
+			proposals = append(proposals, *proposal)
+		}
+	}
+
+	return proposals
+}
+
+func (s *StateDB) GetProposal(id uint64) *Proposal {
+	_, enc := s.iavl.Get(proposalKey(id))
+	if len(enc) == 0 {
+		return nil
+	}
+
+	var proposal Proposal
+	if err := rlp.DecodeBytes(enc, &proposal); err != nil {
+		log.Error("Failed to decode proposal", "id", id, "err", err)
+		return nil
+	}
+
+	return &proposal
+}
+
+// GetProposalVotes returns votes for proposal in order they were cast
+func (s *StateDB) GetProposalVotes(id uint64) []ProposalVote {
+	var votes []ProposalVote
+
+	_, enc := s.iavl.Get(proposalVotesKey(id))
+	if len(enc) == 0 {
+		return votes
+	}
+
+	if err := rlp.DecodeBytes(enc, &votes); err != nil {
+		log.Error("Failed to decode proposal votes", "id", id, "err", err)
+		return nil
+	}
+
+	return votes
+}
+
+// NextProposalID returns id which will be assigned to the next proposal
+func (s *StateDB) NextProposalID() uint64 {
+	return s.lastProposalID() + 1
+}
+
+// CreateProposal creates a proposal, validators can vote for it during proposal voting period
+func (s *StateDB) CreateProposal(proposer types.Address, proposalType ProposalType, param string, value uint64,
+	height uint64) uint64 {
+	id := s.NextProposalID()
+
+	s.setProposal(Proposal{
+		ID:              id,
+		Proposer:        proposer,
+		Type:            proposalType,
+		Param:           param,
+		Value:           value,
+		Height:          height,
+		VotingEndHeight: s.height + s.GetChainParams().ProposalVotingPeriod,
+		Status:          ProposalStatusVoting,
+		Tally:           newProposalTally(),
+	})
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, id)
+	s.iavl.Set(proposalsCounterKey, counter)
+
+	s.setActiveProposals(append(s.getActiveProposals(), id))
+
+	return id
+}
+
+// SetProposalVote sets vote of voter, previous vote of the same voter is replaced
+func (s *StateDB) SetProposalVote(id uint64, voter types.Address, option VoteOption) {
+	votes := s.GetProposalVotes(id)
+
+	vote := ProposalVote{Voter: voter, Option: option}
+	for i := range votes {
+		if votes[i].Voter == voter {
+			votes[i] = vote
+			s.setProposalVotes(id, votes)
+			return
+		}
+	}
+
+	s.setProposalVotes(id, append(votes, vote))
+}
+
+// GetVotingPower returns total stake of validators owned by address
+func (s *StateDB) GetVotingPower(address types.Address) *big.Int {
+	power := big.NewInt(0)
+
+	for _, validator := range s.getValidatorsData() {
+		candidate := s.GetStateCandidate(validator.PubKey)
+		if candidate != nil && candidate.OwnerAddress == address {
+			power.Add(power, validator.TotalBipStake)
+		}
+	}
+
+	return power
+}
+
+// TallyProposal counts votes of proposal weighted by current stakes of validators
+func (s *StateDB) TallyProposal(proposal Proposal) ProposalTally {
+	tally := newProposalTally()
+
+	for _, validator := range s.getValidatorsData() {
+		tally.Total.Add(tally.Total, validator.TotalBipStake)
+	}
+
+	for _, vote := range s.GetProposalVotes(proposal.ID) {
+		power := s.GetVotingPower(vote.Voter)
+
+		switch vote.Option {
+		case VoteYes:
+			tally.Yes.Add(tally.Yes, power)
+		case VoteNo:
+			tally.No.Add(tally.No, power)
+		case VoteAbstain:
+			tally.Abstain.Add(tally.Abstain, power)
+		}
+	}
+
+	return tally
+}
+
+// TallyProposals finishes voting for proposals which voting period ends at the current height
+func (s *StateDB) TallyProposals() {
+	ids := s.getActiveProposals()
+
+	active := ids[:0:0]
+	for _, id := range ids {
+		proposal := s.getActiveProposal(id)
+		if proposal.Status != ProposalStatusVoting || proposal.VotingEndHeight != s.height {
+			active = append(active, id)
+			continue
+		}
+
+		proposal.Tally = s.TallyProposal(*proposal)
+		proposal.Status = ProposalStatusRejected
+		if proposal.Tally.IsPassed() {
+			proposal.Status = ProposalStatusPassed
+			active = append(active, id)
+		}
+
+		s.setProposal(*proposal)
+		s.addProposalEvent(*proposal)
+	}
+
+	if len(active) != len(ids) {
+		s.setActiveProposals(active)
+	}
+}
+
+// ApplyProposals applies passed proposals scheduled at the current height and returns them. Param changes which
+// can't be applied get failed status.
+func (s *StateDB) ApplyProposals() []Proposal {
+	ids := s.getActiveProposals()
+
+	var applied []Proposal
+	active := ids[:0:0]
+	for _, id := range ids {
+		proposal := s.getActiveProposal(id)
+		if proposal.Status != ProposalStatusPassed || proposal.Height != s.height {
+			active = append(active, id)
+			continue
+		}
+
+		proposal.Status = ProposalStatusApplied
+		if proposal.Type == ProposalTypeParamChange {
+			params := s.GetChainParams()
+			if err := params.SetParam(proposal.Param, proposal.Value); err != nil {
+				proposal.Status = ProposalStatusFailed
+			} else if err := params.Validate(); err != nil {
+				proposal.Status = ProposalStatusFailed
+			} else {
+				s.SetChainParams(params)
+			}
+		}
+
+		s.setProposal(*proposal)
+		s.addProposalEvent(*proposal)
+		applied = append(applied, *proposal)
+	}
+
+	if len(applied) != 0 {
+		s.setActiveProposals(active)
+	}
+
+	return applied
+}
+
+// GetPendingUpgrade returns the nearest passed upgrade proposal which is not applied yet
+func (s *StateDB) GetPendingUpgrade() *Proposal {
+	var upgrade *Proposal
+	for _, id := range s.getActiveProposals() {
+		proposal := s.getActiveProposal(id)
+		if proposal.Type != ProposalTypeUpgrade || proposal.Status != ProposalStatusPassed {
+			continue
+		}
+
+		if upgrade == nil || proposal.Height < upgrade.Height {
+			upgrade = proposal
+		}
+	}
+
+	return upgrade
+}
+
+func (s *StateDB) addProposalEvent(proposal Proposal) {
+	s.events.AddEvent(s.height, events.ProposalEvent{
+		ProposalID: proposal.ID,
+		Status:     proposal.Status.String(),
+		Yes:        proposal.Tally.Yes.Bytes(),
+		No:         proposal.Tally.No.Bytes(),
+		Abstain:    proposal.Tally.Abstain.Bytes(),
+		Total:      proposal.Tally.Total.Bytes(),
+	})
+}
+
+// getActiveProposal panics if active proposal is missing, such state is corrupted and can't be tallied
+func (s *StateDB) getActiveProposal(id uint64) *Proposal {
+	proposal := s.GetProposal(id)
+	if proposal == nil {
+		panic(fmt.Errorf("active proposal %d does not exist", id))
+	}
+
+	return proposal
+}
+
+// setProposal writes proposal to the tree right away, as it's done for used checks and max gas. Proposals are not
+// kept among live objects, so there is nothing to flush on commit and reads see the write immediately. Proposals are
+// changed only by txs in deliver mode and by EndBlock, the tree of check state is immutable.
+func (s *StateDB) setProposal(proposal Proposal) {
+	data, err := rlp.EncodeToBytes(proposal)
+	if err != nil {
+		panic(fmt.Errorf("can't encode proposal: %v", err))
+	}
+
+	s.iavl.Set(proposalKey(proposal.ID), data)
+}
+
+func (s *StateDB) setProposalVotes(id uint64, votes []ProposalVote) {
+	data, err := rlp.EncodeToBytes(votes)
+	if err != nil {
+		panic(fmt.Errorf("can't encode proposal votes: %v", err))
+	}
+
+	s.iavl.Set(proposalVotesKey(id), data)
+}
+
+func (s *StateDB) lastProposalID() uint64 {
+	_, enc := s.iavl.Get(proposalsCounterKey)
+	if len(enc) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(enc)
+}
+
+func (s *StateDB) getActiveProposals() []uint64 {
+	var ids []uint64
+
+	_, enc := s.iavl.Get(activeProposalsKey)
+	if len(enc) == 0 {
+		return ids
+	}
+
+	if err := rlp.DecodeBytes(enc, &ids); err != nil {
+		panic(fmt.Errorf("can't decode active proposals: %v", err))
+	}
+
+	return ids
+}
+
+func (s *StateDB) setActiveProposals(ids []uint64) {
+	if len(ids) == 0 {
+		s.iavl.Remove(activeProposalsKey)
+		return
+	}
+
+	data, err := rlp.EncodeToBytes(ids)
+	if err != nil {
+		panic(fmt.Errorf("can't encode active proposals: %v", err))
+	}
+
+	s.iavl.Set(activeProposalsKey, data)
+}
+
+func proposalKey(id uint64) []byte {
+	key := make([]byte, len(proposalsPrefix)+8)
+	copy(key, proposalsPrefix)
+	binary.BigEndian.PutUint64(key[len(proposalsPrefix):], id)
+
+	return key
+}
+
+func proposalVotesKey(id uint64) []byte {
+	return append(proposalKey(id), 'v')
+}
+
+func (s *StateDB) getValidatorsData() Validators {
+	validators := s.getStateValidators()
+	if validators == nil {
+		return nil
+	}
+
+	return validators.data
+}
+
+func newProposalTally() ProposalTally {
+	return ProposalTally{
+		Yes:     big.NewInt(0),
+		No:      big.NewInt(0),
+		Abstain: big.NewInt(0),
+		Total:   big.NewInt(0),
+	}
+}
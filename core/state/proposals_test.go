@@ -0,0 +1,165 @@
+package state
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"math/big"
+	"testing"
+)
+
+func createTestValidator(s *StateDB, owner types.Address, stake int64) {
+	pubKey := make([]byte, 32)
+	copy(pubKey, owner[:])
+
+	value := helpers.BipToPip(big.NewInt(stake))
+	s.CreateCandidate(owner, owner, pubKey, 10, 0, types.GetBaseCoin(), value)
+	s.CreateValidator(owner, pubKey, 10, 0, types.GetBaseCoin(), value)
+}
+
+func TestStateDB_TallyAndApplyProposals(t *testing.T) {
+	s := getState()
+
+	validator1, validator2, validator3 := types.Address{1}, types.Address{2}, types.Address{3}
+	createTestValidator(s, validator1, 50)
+	createTestValidator(s, validator2, 30)
+	createTestValidator(s, validator3, 20)
+
+	params := s.GetChainParams()
+	height := s.Height() + params.ProposalVotingPeriod + 1
+
+	passed := s.CreateProposal(validator1, ProposalTypeParamChange, "unbond_period", 100, height)
+	rejected := s.CreateProposal(validator1, ProposalTypeParamChange, "commissions.send_tx", 1, height)
+
+	s.SetProposalVote(passed, validator1, VoteYes)
+	s.SetProposalVote(passed, validator2, VoteNo)
+	s.SetProposalVote(passed, validator2, VoteYes)
+	s.SetProposalVote(rejected, validator1, VoteYes)
+	s.SetProposalVote(rejected, validator3, VoteNo)
+
+	tally := s.TallyProposal(*s.GetProposal(passed))
+	if tally.Yes.Cmp(helpers.BipToPip(big.NewInt(80))) != 0 || tally.No.Sign() != 0 {
+		t.Fatalf("Vote of the same voter should be replaced, got yes %s, no %s", tally.Yes, tally.No)
+	}
+
+	// voting ends and passed proposal is applied at the following heights
+	s.height = s.GetProposal(passed).VotingEndHeight
+	s.TallyProposals()
+
+	if status := s.GetProposal(passed).Status; status != ProposalStatusPassed {
+		t.Fatalf("Proposal with 80%% of stake should pass, got status %s", status)
+	}
+
+	if status := s.GetProposal(rejected).Status; status != ProposalStatusRejected {
+		t.Fatalf("Proposal with 50%% of stake should be rejected, got status %s", status)
+	}
+
+	s.height = height
+	if applied := s.ApplyProposals(); len(applied) != 1 || applied[0].ID != passed {
+		t.Fatalf("Only passed proposal should be applied, got %+v", applied)
+	}
+
+	if status := s.GetProposal(passed).Status; status != ProposalStatusApplied {
+		t.Fatalf("Proposal should be applied, got status %s", status)
+	}
+
+	if unbondPeriod := s.GetChainParams().UnbondPeriod; unbondPeriod != 100 {
+		t.Fatalf("Unbond period should be changed to 100, got %d", unbondPeriod)
+	}
+
+	if sendTx := s.GetChainParams().Commissions.SendTx; sendTx != params.Commissions.SendTx {
+		t.Fatalf("Rejected proposal should not change commissions, got %d", sendTx)
+	}
+}
+
+func TestStateDB_GetPendingUpgrade(t *testing.T) {
+	s := getState()
+
+	validator := types.Address{1}
+	createTestValidator(s, validator, 100)
+
+	votingEndHeight := s.Height() + s.GetChainParams().ProposalVotingPeriod
+	later := s.CreateProposal(validator, ProposalTypeUpgrade, "v3", 0, votingEndHeight+100)
+	nearest := s.CreateProposal(validator, ProposalTypeUpgrade, "v2", 0, votingEndHeight+10)
+	s.CreateProposal(validator, ProposalTypeUpgrade, "rejected", 0, votingEndHeight+1)
+
+	s.SetProposalVote(later, validator, VoteYes)
+	s.SetProposalVote(nearest, validator, VoteYes)
+
+	if upgrade := s.GetPendingUpgrade(); upgrade != nil {
+		t.Fatalf("Upgrades should not be pending before end of voting, got %+v", upgrade)
+	}
+
+	s.height = votingEndHeight
+	s.TallyProposals()
+
+	if upgrade := s.GetPendingUpgrade(); upgrade == nil || upgrade.ID != nearest {
+		t.Fatalf("Nearest upgrade should be pending, got %+v", upgrade)
+	}
+
+	s.height = votingEndHeight + 10
+	s.ApplyProposals()
+
+	if upgrade := s.GetPendingUpgrade(); upgrade == nil || upgrade.ID != later {
+		t.Fatalf("Later upgrade should be pending after nearest is applied, got %+v", upgrade)
+	}
+}
+
+func TestStateDB_ProposalsStorage(t *testing.T) {
+	s := getState()
+
+	validator := types.Address{1}
+	createTestValidator(s, validator, 100)
+
+	votingEndHeight := s.Height() + s.GetChainParams().ProposalVotingPeriod
+	first := s.CreateProposal(validator, ProposalTypeUpgrade, "v2", 0, votingEndHeight+1)
+	second := s.CreateProposal(validator, ProposalTypeUpgrade, "v3", 0, votingEndHeight+1)
+
+	if first != 1 || second != 2 || s.NextProposalID() != 3 {
+		t.Fatalf("Proposals should get sequential ids, got %d, %d and next %d", first, second, s.NextProposalID())
+	}
+
+	s.SetProposalVote(second, validator, VoteYes)
+
+	if votes := s.GetProposalVotes(first); len(votes) != 0 {
+		t.Fatalf("First proposal should have no votes, got %+v", votes)
+	}
+
+	if votes := s.GetProposalVotes(second); len(votes) != 1 || votes[0].Voter != validator {
+		t.Fatalf("Second proposal should have a vote of validator, got %+v", votes)
+	}
+
+	if proposals := s.GetProposals(); len(proposals) != 2 || proposals[0].ID != first || proposals[1].ID != second {
+		t.Fatalf("Proposals should be listed in order of ids, got %+v", proposals)
+	}
+
+	s.height = votingEndHeight
+	s.TallyProposals()
+
+	if active := s.getActiveProposals(); len(active) != 1 || active[0] != second {
+		t.Fatalf("Only passed proposal should stay active, got %v", active)
+	}
+
+	s.height = votingEndHeight + 1
+	s.ApplyProposals()
+
+	if active := s.getActiveProposals(); len(active) != 0 {
+		t.Fatalf("Applied proposal should not be active, got %v", active)
+	}
+
+	if s.GetProposal(first).Status != ProposalStatusRejected || s.GetProposal(second).Status != ProposalStatusApplied {
+		t.Fatal("Proposals should keep their final statuses")
+	}
+}
+
+func TestStateDB_TallyProposalsMissingProposal(t *testing.T) {
+	s := getState()
+	s.setActiveProposals([]uint64{1})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Tally of missing active proposal should panic")
+		}
+	}()
+
+	s.TallyProposals()
+}
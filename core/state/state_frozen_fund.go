@@ -1,7 +1,6 @@
 package state
 
 import (
-	"github.com/MinterTeam/minter-go-node/eventsdb"
 	"github.com/MinterTeam/minter-go-node/eventsdb/events"
 	"github.com/MinterTeam/minter-go-node/formula"
 	"io"
@@ -87,7 +86,7 @@ func (c *stateFrozenFund) PunishFund(context *StateDB, candidateAddress [20]byte
 }
 
 func (c *stateFrozenFund) punishFund(context *StateDB, candidateAddress [20]byte, fromBlock uint64) {
-	edb := eventsdb.GetCurrent()
+	edb := context.events
 
 	newList := make([]FrozenFund, len(c.data.List))
 	for i, item := range c.data.List {
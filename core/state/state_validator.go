@@ -39,7 +39,7 @@ type Validator struct {
 func (validator *Validator) CountAbsentTimes() int {
 	count := 0
 
-	for i := 0; i < ValidatorMaxAbsentWindow; i++ {
+	for i := 0; i < int(validator.AbsentTimes.Size()); i++ {
 		if validator.AbsentTimes.GetIndex(i) {
 			count++
 		}
@@ -3,39 +3,27 @@ package state
 import (
 	"encoding/hex"
 	"fmt"
-	"github.com/MinterTeam/go-amino"
-	"github.com/MinterTeam/minter-go-node/cmd/utils"
-	"github.com/MinterTeam/minter-go-node/core/rewards"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/core/validators"
 	"github.com/MinterTeam/minter-go-node/eventsdb"
 	"github.com/MinterTeam/minter-go-node/eventsdb/events"
 	"github.com/MinterTeam/minter-go-node/formula"
-	"github.com/MinterTeam/minter-go-node/helpers"
 	"github.com/MinterTeam/minter-go-node/log"
 	"github.com/MinterTeam/minter-go-node/rlp"
 	"github.com/MinterTeam/minter-go-node/upgrades"
 	dbm "github.com/tendermint/tendermint/libs/db"
-	tmTypes "github.com/tendermint/tendermint/types"
 	"math/big"
-	"os"
 	"sync"
 
 	"bytes"
 	"encoding/binary"
 	"github.com/MinterTeam/minter-go-node/core/check"
-	"github.com/MinterTeam/minter-go-node/core/dao"
-	"github.com/MinterTeam/minter-go-node/core/developers"
 	"sort"
 )
 
-const UnbondPeriod = 518400
 const MaxDelegatorsPerCandidate = 1000
 
 var (
-	ValidatorMaxAbsentWindow = 24
-	ValidatorMaxAbsentTimes  = 12
-
 	addressPrefix     = []byte("a")
 	coinPrefix        = []byte("c")
 	frozenFundsPrefix = []byte("f")
@@ -50,6 +38,9 @@ type StateDB struct {
 	db   dbm.DB
 	iavl Tree
 
+	// events receives events emitted during state transition, events of check states are discarded
+	events eventsdb.IEventsDB
+
 	height uint64
 
 	// This map holds 'live' objects, which will get modified while processing a state transition.
@@ -73,8 +64,12 @@ type StateDB struct {
 
 	stakeCache map[types.CoinSymbol]StakeCache
 
+	// chainParams caches decoded chain params, it's cleared when params are changed
+	chainParams *types.ChainParams
+
 	lock             sync.Mutex
 	keepStateHistory bool
+	versions         retainedVersions
 }
 
 type StakeCache struct {
@@ -107,6 +102,7 @@ func NewForCheck(height uint64, db dbm.DB) (*StateDB, error) {
 		totalSlashed:          nil,
 		totalSlashedDirty:     false,
 		stakeCache:            make(map[types.CoinSymbol]StakeCache),
+		events:                eventsdb.NOOPEventsDB{},
 	}, nil
 }
 
@@ -128,6 +124,7 @@ func NewForCheckFromDeliver(s *StateDB) *StateDB {
 		totalSlashed:          nil,
 		totalSlashedDirty:     false,
 		stakeCache:            make(map[types.CoinSymbol]StakeCache),
+		events:                eventsdb.NOOPEventsDB{},
 	}
 }
 
@@ -158,9 +155,15 @@ func New(height uint64, db dbm.DB, keepState bool) (*StateDB, error) {
 		totalSlashedDirty:     false,
 		stakeCache:            make(map[types.CoinSymbol]StakeCache),
 		keepStateHistory:      keepState,
+		events:                eventsdb.NOOPEventsDB{},
 	}, nil
 }
 
+// SetEventsDB sets db which receives events emitted during state transition
+func (s *StateDB) SetEventsDB(edb eventsdb.IEventsDB) {
+	s.events = edb
+}
+
 func (s *StateDB) Clear() {
 	s.stateAccounts = make(map[types.Address]*stateAccount)
 	s.stateAccountsDirty = make(map[types.Address]struct{})
@@ -338,7 +341,7 @@ func (s *StateDB) deleteStateObject(stateObject *stateAccount) {
 // deleteStateCoin removes the given object from the state trie.
 func (s *StateDB) deleteStateCoin(stateCoin *stateCoin) {
 	symbol := stateCoin.Symbol()
-	eventsdb.GetCurrent().AddEvent(s.height, events.CoinLiquidationEvent{
+	s.events.AddEvent(s.height, events.CoinLiquidationEvent{
 		Coin: symbol,
 	})
 	s.iavl.Remove(append(coinPrefix, symbol[:]...))
@@ -547,6 +550,31 @@ func (s *StateDB) GetStateFrozenFunds(blockHeight uint64) *stateFrozenFund {
 	return s.getStateFrozenFunds(blockHeight)
 }
 
+// GetAllStateFrozenFunds returns frozen funds of all blocks ordered by unbond height
+func (s *StateDB) GetAllStateFrozenFunds() []*stateFrozenFund {
+	var heights []uint64
+	s.iavl.Iterate(func(key []byte, value []byte) bool {
+		if key[0] > frozenFundsPrefix[0] {
+			return true
+		}
+
+		if key[0] == frozenFundsPrefix[0] {
+			heights = append(heights, binary.BigEndian.Uint64(key[1:]))
+		}
+
+		return false
+	})
+
+	frozenFunds := make([]*stateFrozenFund, 0, len(heights))
+	for _, height := range heights {
+		if ff := s.getStateFrozenFunds(height); ff != nil {
+			frozenFunds = append(frozenFunds, ff)
+		}
+	}
+
+	return frozenFunds
+}
+
 func (s *StateDB) GetOrNewStateFrozenFunds(blockHeight uint64) *stateFrozenFund {
 	frozenFund := s.getStateFrozenFunds(blockHeight)
 	if frozenFund == nil {
@@ -649,7 +677,7 @@ func (s *StateDB) CreateValidator(
 		PubKey:        pubkey,
 		Commission:    commission,
 		AccumReward:   big.NewInt(0),
-		AbsentTimes:   types.NewBitArray(ValidatorMaxAbsentWindow),
+		AbsentTimes:   types.NewBitArray(int(s.GetChainParams().ValidatorMaxAbsentWindow)),
 	})
 
 	s.MarkStateValidatorsDirty()
@@ -755,10 +783,12 @@ func (s *StateDB) Commit() (root []byte, version int64, err error) {
 	hash, version, err := s.iavl.SaveVersion()
 
 	if !s.keepStateHistory && version > 1 {
-		err = s.iavl.DeleteVersion(version - 1)
+		for _, prunable := range s.prunableVersions(version - 1) {
+			err = s.iavl.DeleteVersion(prunable)
 
-		if err != nil {
-			panic(err)
+			if err != nil {
+				panic(err)
+			}
 		}
 	}
 
@@ -958,7 +988,8 @@ func (s *StateDB) AddAccumReward(pubkey types.Pubkey, reward *big.Int) {
 }
 
 func (s *StateDB) PayRewards() {
-	edb := eventsdb.GetCurrent()
+	edb := s.events
+	params := s.GetChainParams()
 
 	vals := s.getStateValidators()
 	for i := range vals.data {
@@ -970,26 +1001,26 @@ func (s *StateDB) PayRewards() {
 
 			// pay commission to DAO
 			DAOReward := big.NewInt(0).Set(totalReward)
-			DAOReward.Mul(DAOReward, big.NewInt(int64(dao.Commission)))
+			DAOReward.Mul(DAOReward, big.NewInt(int64(params.DAOCommission)))
 			DAOReward.Div(DAOReward, big.NewInt(100))
-			s.AddBalance(dao.Address, types.GetBaseCoin(), DAOReward)
+			s.AddBalance(params.DAOAddress, types.GetBaseCoin(), DAOReward)
 			remainder.Sub(remainder, DAOReward)
 			edb.AddEvent(s.height, events.RewardEvent{
 				Role:            events.RoleDAO,
-				Address:         dao.Address,
+				Address:         params.DAOAddress,
 				Amount:          DAOReward.Bytes(),
 				ValidatorPubKey: validator.PubKey,
 			})
 
 			// pay commission to Developers
 			DevelopersReward := big.NewInt(0).Set(totalReward)
-			DevelopersReward.Mul(DevelopersReward, big.NewInt(int64(developers.Commission)))
+			DevelopersReward.Mul(DevelopersReward, big.NewInt(int64(params.DevelopersCommission)))
 			DevelopersReward.Div(DevelopersReward, big.NewInt(100))
-			s.AddBalance(developers.Address, types.GetBaseCoin(), DevelopersReward)
+			s.AddBalance(params.DevelopersAddress, types.GetBaseCoin(), DevelopersReward)
 			remainder.Sub(remainder, DevelopersReward)
 			edb.AddEvent(s.height, events.RewardEvent{
 				Role:            events.RoleDevelopers,
-				Address:         developers.Address,
+				Address:         params.DevelopersAddress,
 				Amount:          DevelopersReward.Bytes(),
 				ValidatorPubKey: validator.PubKey,
 			})
@@ -1012,6 +1043,11 @@ func (s *StateDB) PayRewards() {
 			})
 
 			candidate := s.GetStateCandidate(validator.PubKey)
+			autoCompound := upgrades.IsActive(upgrades.FeatureAutoCompound, s.height)
+
+			// rewards to compound are added to stakes only after rewards of all stakes are computed, so they don't
+			// change stakes which are not paid yet
+			var compounds []compoundedReward
 
 			// pay rewards
 			for j := range candidate.Stakes {
@@ -1030,18 +1066,29 @@ func (s *StateDB) PayRewards() {
 					continue
 				}
 
-				s.AddBalance(stake.Owner, types.GetBaseCoin(), reward)
 				remainder.Sub(remainder, reward)
 
-				edb.AddEvent(s.height, events.RewardEvent{
-					Role:            events.RoleDelegator,
-					Address:         stake.Owner,
-					Amount:          reward.Bytes(),
-					ValidatorPubKey: candidate.PubKey,
-				})
+				if autoCompound && s.IsAutoCompound(stake.Owner) {
+					compounds = append(compounds, compoundedReward{owner: stake.Owner, reward: reward})
+					continue
+				}
+
+				s.payDelegatorReward(edb, candidate, stake.Owner, reward)
+			}
+
+			// compounded rewards are counted in total stake of validator after all rewards are paid
+			compounded := big.NewInt(0)
+			for _, c := range compounds {
+				if s.compoundReward(edb, candidate, c.owner, c.reward) {
+					compounded.Add(compounded, c.reward)
+					continue
+				}
+
+				s.payDelegatorReward(edb, candidate, c.owner, c.reward)
 			}
 
 			vals.data[i].AccumReward = big.NewInt(0)
+			vals.data[i].TotalBipStake = big.NewInt(0).Add(validator.TotalBipStake, compounded)
 
 			if remainder.Cmp(big.NewInt(0)) > -1 {
 				s.AddTotalSlashed(remainder)
@@ -1055,6 +1102,18 @@ func (s *StateDB) PayRewards() {
 	s.MarkStateValidatorsDirty()
 }
 
+func (s *StateDB) payDelegatorReward(edb eventsdb.IEventsDB, candidate *Candidate, owner types.Address,
+	reward *big.Int) {
+	s.AddBalance(owner, types.GetBaseCoin(), reward)
+
+	edb.AddEvent(s.height, events.RewardEvent{
+		Role:            events.RoleDelegator,
+		Address:         owner,
+		Amount:          reward.Bytes(),
+		ValidatorPubKey: candidate.PubKey,
+	})
+}
+
 func (s *StateDB) RecalculateTotalStakeValues() {
 	stateCandidates := s.getStateCandidates()
 	vals := s.getStateValidators()
@@ -1217,7 +1276,7 @@ func (s *StateDB) SetValidatorPresent(address [20]byte) {
 	for i := range vals.data {
 		validator := &vals.data[i]
 		if validator.GetAddress() == address {
-			validator.AbsentTimes.SetIndex(int(s.height)%ValidatorMaxAbsentWindow, false)
+			validator.AbsentTimes.SetIndex(int(s.height%s.GetChainParams().ValidatorMaxAbsentWindow), false)
 		}
 	}
 	s.setStateValidators(vals)
@@ -1225,7 +1284,7 @@ func (s *StateDB) SetValidatorPresent(address [20]byte) {
 }
 
 func (s *StateDB) SetValidatorAbsent(address [20]byte) {
-	edb := eventsdb.GetCurrent()
+	edb := s.events
 
 	vals := s.getStateValidators()
 	for i := range vals.data {
@@ -1250,18 +1309,25 @@ func (s *StateDB) SetValidatorAbsent(address [20]byte) {
 				return
 			}
 
-			validator.AbsentTimes.SetIndex(int(s.height)%ValidatorMaxAbsentWindow, true)
+			params := s.GetChainParams()
+			validator.AbsentTimes.SetIndex(int(s.height%params.ValidatorMaxAbsentWindow), true)
 
-			if validator.CountAbsentTimes() > ValidatorMaxAbsentTimes {
+			if uint64(validator.CountAbsentTimes()) > params.ValidatorMaxAbsentTimes {
 				candidate.Status = CandidateStatusOffline
-				validator.AbsentTimes = types.NewBitArray(ValidatorMaxAbsentWindow)
+				validator.AbsentTimes = types.NewBitArray(int(params.ValidatorMaxAbsentWindow))
 				validator.toDrop = true
 
+				// penalty of jailed candidates grows with number of their offences
+				slashPercent := uint64(1)
+				if params.JailPeriod != 0 && upgrades.IsActive(upgrades.FeatureJail, s.height) {
+					slashPercent = s.jailCandidate(candidate.PubKey, params.JailPeriod)
+				}
+
 				totalStake := big.NewInt(0)
 
 				for j, stake := range candidate.Stakes {
 					newValue := big.NewInt(0).Set(stake.Value)
-					newValue.Mul(newValue, big.NewInt(99))
+					newValue.Mul(newValue, big.NewInt(int64(100-slashPercent)))
 					newValue.Div(newValue, big.NewInt(100))
 
 					slashed := big.NewInt(0).Set(stake.Value)
@@ -1309,7 +1375,7 @@ func (s *StateDB) SetValidatorAbsent(address [20]byte) {
 }
 
 func (s *StateDB) PunishByzantineValidator(address [20]byte) {
-	edb := eventsdb.GetCurrent()
+	edb := s.events
 	vals := s.getStateValidators()
 
 	for i := range vals.data {
@@ -1355,8 +1421,8 @@ func (s *StateDB) PunishByzantineValidator(address [20]byte) {
 					ValidatorPubKey: candidate.PubKey,
 				})
 
-				s.GetOrNewStateFrozenFunds(s.height+UnbondPeriod).AddFund(stake.Owner, candidate.PubKey,
-					stake.Coin, newValue)
+				unbondAtBlock := s.height + s.GetChainParams().UnbondPeriod
+				s.GetOrNewStateFrozenFunds(unbondAtBlock).AddFund(stake.Owner, candidate.PubKey, stake.Coin, newValue)
 				s.SanitizeCoin(stake.Coin)
 			}
 
@@ -1393,7 +1459,7 @@ func (s *StateDB) SetNewValidators(candidates []Candidate) {
 
 	for _, candidate := range candidates {
 		accumReward := big.NewInt(0)
-		absentTimes := types.NewBitArray(ValidatorMaxAbsentWindow)
+		absentTimes := types.NewBitArray(int(s.GetChainParams().ValidatorMaxAbsentWindow))
 
 		for _, oldVal := range oldVals.data {
 			if oldVal.GetAddress() == candidate.GetAddress() {
@@ -1509,7 +1575,7 @@ func (s *StateDB) CandidatesCount() int {
 }
 
 func (s *StateDB) ClearCandidates() {
-	maxCandidates := validators.GetCandidatesCountForBlock(s.height)
+	maxCandidates := validators.CandidatesCountForBlock(s.GetChainParams(), s.height)
 
 	candidates := s.getStateCandidates()
 
@@ -1522,7 +1588,7 @@ func (s *StateDB) ClearCandidates() {
 		dropped := candidates.data[maxCandidates:]
 		candidates.data = candidates.data[:maxCandidates]
 
-		unbondAtBlock := s.height + UnbondPeriod
+		unbondAtBlock := s.height + s.GetChainParams().UnbondPeriod
 		for _, candidate := range dropped {
 			for _, stake := range candidate.Stakes {
 				s.GetOrNewStateFrozenFunds(unbondAtBlock).AddFund(stake.Owner, candidate.PubKey, stake.Coin, stake.Value)
@@ -1549,7 +1615,7 @@ func (s *StateDB) ClearStakes() {
 			candidates.data[i].Stakes = candidates.data[i].Stakes[:MaxDelegatorsPerCandidate]
 
 			for _, stake := range dropped {
-				eventsdb.GetCurrent().AddEvent(s.height, events.UnbondEvent{
+				s.events.AddEvent(s.height, events.UnbondEvent{
 					Address:         stake.Owner,
 					Amount:          stake.Value.Bytes(),
 					Coin:            stake.Coin,
@@ -1577,7 +1643,7 @@ func (s *StateDB) IsDelegatorStakeSufficient(sender types.Address, pubKey []byte
 	}
 
 	bipValue := big.NewInt(0)
-	if s.Height() > upgrades.UpgradeBlock1 {
+	if upgrades.IsActive(upgrades.FeatureSimulatedStakeValue, s.Height()) {
 		bipValue = stake.CalcSimulatedBipValue(s)
 	} else {
 		bipValue = stake.CalcBipValue(s)
@@ -1889,10 +1955,18 @@ func (s *StateDB) Export(currentHeight uint64) types.AppState {
 	appState.StartHeight = s.height
 	appState.TotalSlashed = s.GetTotalSlashed()
 
+	if params, ok := s.getStoredChainParams(); ok {
+		appState.ChainParams = &params
+	}
+
 	return appState
 }
 
 func (s *StateDB) Import(appState types.AppState) {
+	if appState.ChainParams != nil {
+		s.SetChainParams(*appState.ChainParams)
+	}
+
 	s.SetMaxGas(appState.MaxGas)
 	s.setTotalSlashed(appState.TotalSlashed)
 
@@ -1970,165 +2044,6 @@ func (s *StateDB) Import(appState types.AppState) {
 	}
 }
 
-func (s *StateDB) CheckForInvariants() error {
-	height := s.height
-	if height <= 1 {
-		return nil
-	}
-
-	genesisFile := utils.GetMinterHome() + "/config/genesis.json"
-	genesis, err := tmTypes.GenesisDocFromFile(genesisFile)
-	if err != nil {
-		panic(err)
-	}
-
-	var genesisState types.AppState
-	_ = amino.UnmarshalJSON(genesis.AppState, &genesisState)
-
-	GenesisAlloc := big.NewInt(0)
-	for _, account := range genesisState.Accounts {
-		for _, bal := range account.Balance {
-			if bal.Coin.IsBaseCoin() {
-				GenesisAlloc.Add(GenesisAlloc, bal.Value)
-			}
-		}
-	}
-
-	for _, candidate := range genesisState.Candidates {
-		for _, stake := range candidate.Stakes {
-			if stake.Coin.IsBaseCoin() {
-				GenesisAlloc.Add(GenesisAlloc, stake.Value)
-			}
-		}
-	}
-
-	for _, coin := range genesisState.Coins {
-		GenesisAlloc.Add(GenesisAlloc, coin.ReserveBalance)
-	}
-
-	for _, ff := range genesisState.FrozenFunds {
-		if ff.Coin.IsBaseCoin() {
-			GenesisAlloc.Add(GenesisAlloc, ff.Value)
-		}
-	}
-
-	totalBasecoinVolume := big.NewInt(0)
-
-	coinSupplies := map[types.CoinSymbol]*big.Int{}
-	coinTotalOwned := map[types.CoinSymbol]*big.Int{}
-
-	s.iavl.Iterate(func(key []byte, value []byte) bool {
-		if key[0] == addressPrefix[0] {
-			account := s.GetOrNewStateObject(types.BytesToAddress(key[1:]))
-
-			for coin, value := range account.Balances().Data {
-				if coin.IsBaseCoin() {
-					totalBasecoinVolume.Add(totalBasecoinVolume, value)
-					continue
-				}
-
-				if coinTotalOwned[coin] == nil {
-					coinTotalOwned[coin] = big.NewInt(0)
-				}
-				coinTotalOwned[coin].Add(coinTotalOwned[coin], value)
-			}
-
-		}
-
-		if key[0] == coinPrefix[0] {
-			coin := s.GetStateCoin(types.StrToCoinSymbol(string(key[1:])))
-
-			totalBasecoinVolume.Add(totalBasecoinVolume, coin.ReserveBalance())
-			coinSupplies[coin.symbol] = coin.Volume()
-		}
-
-		if key[0] == frozenFundsPrefix[0] {
-			height := binary.BigEndian.Uint64(key[1:])
-			frozenFunds := s.GetStateFrozenFunds(height)
-
-			for _, frozenFund := range frozenFunds.List() {
-				if frozenFund.Coin.IsBaseCoin() {
-					totalBasecoinVolume.Add(totalBasecoinVolume, frozenFund.Value)
-					continue
-				}
-
-				if coinTotalOwned[frozenFund.Coin] == nil {
-					coinTotalOwned[frozenFund.Coin] = big.NewInt(0)
-				}
-				coinTotalOwned[frozenFund.Coin].Add(coinTotalOwned[frozenFund.Coin], frozenFund.Value)
-			}
-		}
-
-		return false
-	})
-
-	candidates := s.getStateCandidates()
-	if candsCount := len(candidates.data); candsCount > validators.GetCandidatesCountForBlock(height) {
-		return fmt.Errorf("too many candidates in blockchain. Expected %d, got %d",
-			validators.GetCandidatesCountForBlock(height), candsCount)
-	}
-
-	for _, candidate := range candidates.data {
-		for _, stake := range candidate.Stakes {
-			if stake.Coin.IsBaseCoin() {
-				totalBasecoinVolume.Add(totalBasecoinVolume, stake.Value)
-				continue
-			}
-
-			if coinTotalOwned[stake.Coin] == nil {
-				coinTotalOwned[stake.Coin] = big.NewInt(0)
-			}
-			coinTotalOwned[stake.Coin].Add(coinTotalOwned[stake.Coin], stake.Value)
-		}
-	}
-
-	vals := s.getStateValidators()
-	if valsCount := len(vals.data); valsCount > validators.GetValidatorsCountForBlock(height) {
-		return fmt.Errorf("too many validators in blockchain. Expected %d, got %d",
-			validators.GetValidatorsCountForBlock(height), valsCount)
-	}
-
-	for _, val := range vals.data {
-		totalBasecoinVolume.Add(totalBasecoinVolume, val.AccumReward)
-	}
-
-	predictedBasecoinVolume := big.NewInt(0)
-	predictedBasecoinVolume.Add(predictedBasecoinVolume, rewards.BeforeGenesis)
-	for i := uint64(1); i < height; i++ {
-		predictedBasecoinVolume.Add(predictedBasecoinVolume, rewards.GetRewardForBlock(i))
-	}
-	predictedBasecoinVolume.Sub(predictedBasecoinVolume, s.GetTotalSlashed())
-	predictedBasecoinVolume.Add(predictedBasecoinVolume, GenesisAlloc)
-
-	if height >= upgrades.UpgradeBlock0 {
-		d, _ := big.NewInt(0).SetString("35703071844419651412692", 10)
-		predictedBasecoinVolume.Sub(predictedBasecoinVolume, d)
-	}
-
-	delta := big.NewInt(0).Sub(predictedBasecoinVolume, totalBasecoinVolume)
-
-	if delta.Cmp(big.NewInt(0)) != 0 {
-		e := fmt.Errorf("smth wrong with total base coins in blockchain. Expected total supply to be %s, got %s",
-			predictedBasecoinVolume, totalBasecoinVolume)
-
-		if delta.Cmp(helpers.BipToPip(big.NewInt(1000))) == 1 {
-			println(fmt.Sprintf("CRITICAL INVARIANTS FAILURE (H:%d): %s", height, e))
-			os.Exit(1)
-		}
-
-		return e
-	}
-
-	for coin, volume := range coinSupplies {
-		if volume.Cmp(coinTotalOwned[coin]) != 0 {
-			return fmt.Errorf("smth wrong with %s coin in blockchain. Total supply (%s) does not match total owned (%s)",
-				coin, volume, coinTotalOwned[coin])
-		}
-	}
-
-	return nil
-}
-
 func (s *StateDB) Height() uint64 {
 	return s.height
 }
@@ -5,11 +5,26 @@ import (
 	"encoding/hex"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/upgrades"
 	"github.com/tendermint/tendermint/libs/db"
 	"math/big"
+	"os"
 	"testing"
 )
 
+// testFeaturesHeight is activation height of features which are not scheduled on public chains yet
+const testFeaturesHeight = 100
+
+func TestMain(m *testing.M) {
+	for _, feature := range []upgrades.Feature{upgrades.FeatureJail, upgrades.FeatureAutoCompound} {
+		if _, ok := upgrades.ActivationHeight(feature); !ok {
+			upgrades.SetActivationHeight(feature, testFeaturesHeight)
+		}
+	}
+
+	os.Exit(m.Run())
+}
+
 func getState() *StateDB {
 	s, err := New(0, db.NewMemDB(), false)
 	if err != nil {
@@ -19,6 +34,21 @@ func getState() *StateDB {
 	return s
 }
 
+// getStateWithFeature returns an empty state which height is equal to activation height of feature
+func getStateWithFeature(t *testing.T, feature upgrades.Feature) *StateDB {
+	height, ok := upgrades.ActivationHeight(feature)
+	if !ok {
+		t.Fatalf("Feature %s is not scheduled", feature)
+	}
+
+	s, err := New(height-1, db.NewMemDB(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
 func TestStateDB_AddBalance(t *testing.T) {
 	state := getState()
 
@@ -135,3 +165,158 @@ func TestStateDB_GetEmptyBalances(t *testing.T) {
 		t.Errorf("Balances of %s are not like expected", address.String())
 	}
 }
+
+func TestRollbackTree(t *testing.T) {
+	memDB := db.NewMemDB()
+	state, err := New(0, memDB, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := types.HexToAddress("Mx02003587993aba5276925c058ba082d209e61cbb")
+	for i := int64(1); i <= 3; i++ {
+		state.AddBalance(address, types.GetBaseCoin(), big.NewInt(i))
+		if _, _, err := state.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := RollbackTree(memDB, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewMutableTree(memDB).GetImmutableAtHeight(2); err == nil {
+		t.Fatal("Version 2 should be removed")
+	}
+
+	state, err = New(1, memDB, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state.AddBalance(address, types.GetBaseCoin(), big.NewInt(10))
+	if _, version, err := state.Commit(); err != nil || version != 2 {
+		t.Fatalf("Version 2 should be saved again, got %d (%v)", version, err)
+	}
+
+	balance := state.GetBalance(address, types.GetBaseCoin())
+	if balance.Cmp(big.NewInt(11)) != 0 {
+		t.Fatalf("Balance should be 11, got %s", balance)
+	}
+}
+
+func TestRollbackPrunedTree(t *testing.T) {
+	memDB := db.NewMemDB()
+	state, err := New(0, memDB, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		state.AddBalance(types.HexToAddress("Mx02003587993aba5276925c058ba082d209e61cbb"), types.GetBaseCoin(),
+			big.NewInt(i))
+		if _, _, err := state.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := RollbackTree(memDB, 1); err == nil {
+		t.Fatal("Rollback to pruned version should fail")
+	}
+}
+
+func TestExportTree(t *testing.T) {
+	memDB := db.NewMemDB()
+	state, err := New(0, memDB, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := types.HexToAddress("Mx02003587993aba5276925c058ba082d209e61cbb")
+	var hash []byte
+	for i := int64(1); i <= 3; i++ {
+		state.AddBalance(address, types.GetBaseCoin(), big.NewInt(i))
+		if h, _, err := state.Commit(); err != nil {
+			t.Fatal(err)
+		} else if i == 2 {
+			hash = h
+		}
+	}
+
+	target := db.NewMemDB()
+	if err := ExportTree(memDB, 2, func(key, value []byte) error {
+		target.Set(key, value)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewMutableTree(target).GetImmutableAtHeight(3); err == nil {
+		t.Fatal("Only version 2 should be exported")
+	}
+
+	tree, err := NewMutableTree(target).GetImmutableAtHeight(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(tree.Hash(), hash) {
+		t.Fatalf("Exported tree hash %X does not match %X", tree.Hash(), hash)
+	}
+
+	state, err = New(2, target, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance := state.GetBalance(address, types.GetBaseCoin())
+	if balance.Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("Balance should be 3, got %s", balance)
+	}
+
+	if err := ExportTree(memDB, 4, func(key, value []byte) error { return nil }); err == nil {
+		t.Fatal("Export of missing version should fail")
+	}
+}
+
+func TestVerifyTree(t *testing.T) {
+	memDB := db.NewMemDB()
+	tree := NewMutableTree(memDB)
+	for i := 0; i < 100; i++ {
+		tree.Set([]byte{byte(i)}, []byte{byte(i), byte(i)})
+	}
+
+	if _, _, err := tree.SaveVersion(); err != nil {
+		t.Fatal(err)
+	}
+
+	leaves, err := VerifyTree(memDB, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if leaves != 100 {
+		t.Fatalf("Tree should have 100 leaves, got %d", leaves)
+	}
+
+	// replace a value of some leaf keeping node encoding valid
+	it := memDB.Iterator([]byte{'n'}, []byte{'o'})
+	for ; it.Valid(); it.Next() {
+		node, err := decodeTreeNode(it.Value())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if node.height == 0 {
+			corrupted := append([]byte{}, it.Value()...)
+			corrupted[len(corrupted)-1]++
+			memDB.Set(it.Key(), corrupted)
+			break
+		}
+	}
+	it.Close()
+
+	if _, err := VerifyTree(memDB, 1); err == nil {
+		t.Fatal("Corrupted tree should not be verified")
+	}
+}
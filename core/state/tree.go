@@ -1,7 +1,12 @@
 package state
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
 	"github.com/danil-lashin/iavl"
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 	dbm "github.com/tendermint/tendermint/libs/db"
 	"sync"
 )
@@ -112,6 +117,203 @@ func (t *MutableTree) DeleteVersion(version int64) error {
 	return t.tree.DeleteVersion(version)
 }
 
+// RollbackTree removes all versions of the tree saved after given one, so the next saved version will be
+// version+1. Nodes referenced only by removed versions are left in db and overwritten on re-execution.
+func RollbackTree(db dbm.DB, version int64) error {
+	if _, err := NewMutableTree(db).GetImmutableAtHeight(version); err != nil {
+		return fmt.Errorf("version %d of the state does not exist or was pruned", version)
+	}
+
+	var keys [][]byte
+
+	// roots: r<version>
+	it := dbm.IteratePrefix(db, []byte{'r'})
+	for ; it.Valid(); it.Next() {
+		key := it.Key()
+		if len(key) == 9 && int64(binary.BigEndian.Uint64(key[1:])) > version {
+			keys = append(keys, key)
+		}
+	}
+	it.Close()
+
+	// orphans: o<last-version><first-version><hash>. Orphans with last version >= given one were
+	// produced by saving removed versions, so the nodes they point to are alive again.
+	it = dbm.IteratePrefix(db, []byte{'o'})
+	for ; it.Valid(); it.Next() {
+		key := it.Key()
+		if len(key) > 9 && int64(binary.BigEndian.Uint64(key[1:9])) >= version {
+			keys = append(keys, key)
+		}
+	}
+	it.Close()
+
+	batch := db.NewBatch()
+	for _, key := range keys {
+		batch.Delete(key)
+	}
+	batch.WriteSync()
+
+	return nil
+}
+
+// ExportTree calls fn for every db record of the tree at given version: its root and all nodes reachable from it.
+// Other versions and orphans are skipped, exported records are enough to load the version from an empty db.
+func ExportTree(db dbm.DB, version int64, fn func(key, value []byte) error) error {
+	return walkTree(db, version, func(key, value []byte, node *treeNode) error {
+		return fn(key, value)
+	})
+}
+
+// VerifyTree recomputes hashes of all nodes of the tree at given version, so every stored node and value is read
+// and checked against the root hash. Returns number of leaves in the tree.
+func VerifyTree(db dbm.DB, version int64) (uint64, error) {
+	var leaves uint64
+
+	err := walkTree(db, version, func(key, value []byte, node *treeNode) error {
+		if node == nil {
+			return nil
+		}
+
+		if hash := node.hash(); !bytes.Equal(hash, key[1:]) {
+			return fmt.Errorf("node %X of version %d of the state has hash %X", key[1:], version, hash)
+		}
+
+		if node.height == 0 {
+			leaves++
+		}
+
+		return nil
+	})
+
+	return leaves, err
+}
+
+// walkTree calls fn for the root record of the tree at given version and for every node record reachable from it
+// along with the decoded node
+func walkTree(db dbm.DB, version int64, fn func(key, value []byte, node *treeNode) error) error {
+	// root: r<version>
+	rootKey := make([]byte, 9)
+	rootKey[0] = 'r'
+	binary.BigEndian.PutUint64(rootKey[1:], uint64(version))
+
+	if !db.Has(rootKey) {
+		return fmt.Errorf("version %d of the state does not exist or was pruned", version)
+	}
+
+	rootHash := db.Get(rootKey)
+	if err := fn(rootKey, rootHash, nil); err != nil {
+		return err
+	}
+
+	if len(rootHash) == 0 {
+		return nil
+	}
+
+	// nodes: n<hash>
+	hashes := [][]byte{rootHash}
+	for len(hashes) > 0 {
+		hash := hashes[len(hashes)-1]
+		hashes = hashes[:len(hashes)-1]
+
+		key := append([]byte{'n'}, hash...)
+		value := db.Get(key)
+		if value == nil {
+			return fmt.Errorf("node %X of version %d of the state is missing", hash, version)
+		}
+
+		node, err := decodeTreeNode(value)
+		if err != nil {
+			return fmt.Errorf("node %X of version %d of the state is corrupted: %s", hash, version, err)
+		}
+
+		if err := fn(key, value, node); err != nil {
+			return err
+		}
+
+		if node.height != 0 {
+			hashes = append(hashes, node.rightHash, node.leftHash)
+		}
+	}
+
+	return nil
+}
+
+// treeNode is an iavl node as it's stored in db: height, size, version and key are followed by a value for leaves
+// or by hashes of left and right nodes otherwise
+type treeNode struct {
+	height    int8
+	size      int64
+	version   int64
+	key       []byte
+	value     []byte
+	leftHash  []byte
+	rightHash []byte
+}
+
+func decodeTreeNode(buf []byte) (*treeNode, error) {
+	node := &treeNode{}
+
+	var n int
+	var err error
+	if node.height, n, err = amino.DecodeInt8(buf); err != nil {
+		return nil, err
+	}
+	buf = buf[n:]
+
+	if node.size, n, err = amino.DecodeVarint(buf); err != nil {
+		return nil, err
+	}
+	buf = buf[n:]
+
+	if node.version, n, err = amino.DecodeVarint(buf); err != nil {
+		return nil, err
+	}
+	buf = buf[n:]
+
+	if node.key, n, err = amino.DecodeByteSlice(buf); err != nil {
+		return nil, err
+	}
+	buf = buf[n:]
+
+	if node.height == 0 {
+		if node.value, _, err = amino.DecodeByteSlice(buf); err != nil {
+			return nil, err
+		}
+
+		return node, nil
+	}
+
+	if node.leftHash, n, err = amino.DecodeByteSlice(buf); err != nil {
+		return nil, err
+	}
+	buf = buf[n:]
+
+	if node.rightHash, _, err = amino.DecodeByteSlice(buf); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// hash computes the node hash the way iavl does: leaves commit to a hash of their value, inner nodes to hashes
+// of their children
+func (n *treeNode) hash() []byte {
+	var buf bytes.Buffer
+	_ = amino.EncodeInt8(&buf, n.height)
+	_ = amino.EncodeVarint(&buf, n.size)
+	_ = amino.EncodeVarint(&buf, n.version)
+
+	if n.height == 0 {
+		_ = amino.EncodeByteSlice(&buf, n.key)
+		_ = amino.EncodeByteSlice(&buf, tmhash.Sum(n.value))
+	} else {
+		_ = amino.EncodeByteSlice(&buf, n.leftHash)
+		_ = amino.EncodeByteSlice(&buf, n.rightHash)
+	}
+
+	return tmhash.Sum(buf.Bytes())
+}
+
 func NewImmutableTree(db dbm.DB) *ImmutableTree {
 	return &ImmutableTree{
 		tree: iavl.NewImmutableTree(db, 1024),
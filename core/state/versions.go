@@ -0,0 +1,59 @@
+package state
+
+import "sync"
+
+// retainedVersions are versions of the state tree which are not deleted by commits until they are released, so
+// they can be read in background, e.g. while a snapshot is exported
+type retainedVersions struct {
+	lock sync.Mutex
+	// retained maps retained versions to whether a commit has already skipped their deletion
+	retained map[int64]bool
+	// released are released versions which deletion was skipped, they are deleted by the next commit
+	released []int64
+}
+
+// RetainVersion prevents deletion of given version of the tree by commits until it's released. It should be called
+// before the next version is committed.
+func (s *StateDB) RetainVersion(version int64) {
+	s.versions.lock.Lock()
+	defer s.versions.lock.Unlock()
+
+	if s.versions.retained == nil {
+		s.versions.retained = map[int64]bool{}
+	}
+
+	s.versions.retained[version] = false
+}
+
+// ReleaseVersion allows deletion of retained version, the version is deleted by the next commit if it was pruned
+// while retained
+func (s *StateDB) ReleaseVersion(version int64) {
+	s.versions.lock.Lock()
+	defer s.versions.lock.Unlock()
+
+	skipped, ok := s.versions.retained[version]
+	if !ok {
+		return
+	}
+
+	delete(s.versions.retained, version)
+	if skipped {
+		s.versions.released = append(s.versions.released, version)
+	}
+}
+
+// prunableVersions returns versions which should be deleted by commit which prunes given version
+func (s *StateDB) prunableVersions(version int64) []int64 {
+	s.versions.lock.Lock()
+	defer s.versions.lock.Unlock()
+
+	versions := s.versions.released
+	s.versions.released = nil
+
+	if _, ok := s.versions.retained[version]; ok {
+		s.versions.retained[version] = true
+		return versions
+	}
+
+	return append(versions, version)
+}
@@ -0,0 +1,158 @@
+// Package tmdb contains helpers to manipulate Tendermint's databases while the node is stopped
+package tmdb
+
+import (
+	"bytes"
+	"fmt"
+	bc "github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/libs/db"
+	sm "github.com/tendermint/tendermint/state"
+)
+
+var (
+	stateKey = []byte("stateKey")
+)
+
+func BlockMetaKey(height int64) []byte {
+	return []byte(fmt.Sprintf("H:%v", height))
+}
+
+func BlockPartKey(height int64, partIndex int) []byte {
+	return []byte(fmt.Sprintf("P:%v:%v", height, partIndex))
+}
+
+func BlockCommitKey(height int64) []byte {
+	return []byte(fmt.Sprintf("C:%v", height))
+}
+
+func SeenCommitKey(height int64) []byte {
+	return []byte(fmt.Sprintf("SC:%v", height))
+}
+
+func validatorsKey(height int64) []byte {
+	return []byte(fmt.Sprintf("validatorsKey:%v", height))
+}
+
+func consensusParamsKey(height int64) []byte {
+	return []byte(fmt.Sprintf("consensusParamsKey:%v", height))
+}
+
+// SetStateBytes replaces Tendermint's state with given amino encoded one
+func SetStateBytes(stateDB db.DB, state []byte) {
+	stateDB.SetSync(stateKey, state)
+}
+
+// SaveState saves Tendermint's state along with validator sets and consensus params needed to continue
+// block processing from state.LastBlockHeight. Unlike sm.SaveState it does not rely on previously saved
+// validator sets.
+func SaveState(stateDB db.DB, state sm.State) {
+	height := state.LastBlockHeight
+
+	state.LastHeightValidatorsChanged = height + 1
+	if !bytes.Equal(state.NextValidators.Hash(), state.Validators.Hash()) {
+		state.LastHeightValidatorsChanged = height + 2
+	}
+	state.LastHeightConsensusParamsChanged = height + 1
+
+	batch := stateDB.NewBatch()
+
+	valSets := map[int64]*sm.ValidatorsInfo{
+		height:     {ValidatorSet: state.LastValidators, LastHeightChanged: height},
+		height + 1: {ValidatorSet: state.Validators, LastHeightChanged: height + 1},
+		height + 2: {ValidatorSet: state.NextValidators, LastHeightChanged: state.LastHeightValidatorsChanged},
+	}
+	for h, info := range valSets {
+		if info.ValidatorSet == nil || info.ValidatorSet.Size() == 0 {
+			continue
+		}
+		batch.Set(validatorsKey(h), info.Bytes())
+	}
+
+	params := sm.ConsensusParamsInfo{
+		ConsensusParams:   state.ConsensusParams,
+		LastHeightChanged: height + 1,
+	}
+	batch.Set(consensusParamsKey(height+1), params.Bytes())
+	batch.Set(stateKey, state.Bytes())
+
+	batch.WriteSync()
+}
+
+// StateAtHeight reconstructs Tendermint's state right after the block at given height was committed
+func StateAtHeight(stateDB db.DB, blockStore *bc.BlockStore, height int64, appHash []byte) (sm.State, error) {
+	current := sm.LoadState(stateDB)
+	if current.IsEmpty() {
+		return sm.State{}, fmt.Errorf("tendermint state is empty")
+	}
+
+	if height > current.LastBlockHeight {
+		return sm.State{}, fmt.Errorf("tendermint state is at height %d, cannot move it forward to %d",
+			current.LastBlockHeight, height)
+	}
+
+	meta := blockStore.LoadBlockMeta(height)
+	if meta == nil {
+		return sm.State{}, fmt.Errorf("block meta at height %d not found", height)
+	}
+
+	lastValidators, err := sm.LoadValidators(stateDB, height)
+	if err != nil {
+		return sm.State{}, err
+	}
+
+	validators, err := sm.LoadValidators(stateDB, height+1)
+	if err != nil {
+		return sm.State{}, err
+	}
+
+	nextValidators, err := sm.LoadValidators(stateDB, height+2)
+	if err != nil {
+		return sm.State{}, err
+	}
+
+	consensusParams, err := sm.LoadConsensusParams(stateDB, height+1)
+	if err != nil {
+		return sm.State{}, err
+	}
+
+	abciResponses, err := sm.LoadABCIResponses(stateDB, height)
+	if err != nil {
+		return sm.State{}, err
+	}
+
+	state := current.Copy()
+	state.Version.Consensus = meta.Header.Version
+	state.LastBlockHeight = height
+	state.LastBlockTotalTx = meta.Header.TotalTxs
+	state.LastBlockID = meta.BlockID
+	state.LastBlockTime = meta.Header.Time
+	state.LastValidators = lastValidators
+	state.Validators = validators
+	state.NextValidators = nextValidators
+	state.ConsensusParams = consensusParams
+	state.LastResultsHash = abciResponses.ResultsHash()
+	state.AppHash = appHash
+
+	return state, nil
+}
+
+// RollbackBlockStore removes all blocks above given height from Tendermint's block store
+func RollbackBlockStore(blockStoreDB db.DB, height int64) {
+	blockStore := bc.NewBlockStore(blockStoreDB)
+
+	batch := blockStoreDB.NewBatch()
+	for h := blockStore.Height(); h > height; h-- {
+		if meta := blockStore.LoadBlockMeta(h); meta != nil {
+			for i := 0; i < meta.BlockID.PartsHeader.Total; i++ {
+				batch.Delete(BlockPartKey(h, i))
+			}
+		}
+
+		batch.Delete(BlockMetaKey(h))
+		batch.Delete(BlockCommitKey(h - 1))
+		batch.Delete(SeenCommitKey(h))
+	}
+	batch.WriteSync()
+
+	bc.BlockStoreStateJSON{Height: height}.Save(blockStoreDB)
+}
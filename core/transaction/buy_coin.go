@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -25,8 +24,8 @@ func (data BuyCoinData) String() string {
 		data.CoinToSell.String(), data.ValueToBuy.String(), data.CoinToBuy.String())
 }
 
-func (data BuyCoinData) Gas() int64 {
-	return commissions.ConvertTx
+func (data BuyCoinData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.ConvertTx)
 }
 
 func (data BuyCoinData) TotalSpend(tx *Transaction, context *state.StateDB) (TotalSpends,
@@ -191,7 +190,7 @@ func (data BuyCoinData) TotalSpend(tx *Transaction, context *state.StateDB) (Tot
 
 		toReserve := big.NewInt(0).Set(baseCoinNeeded)
 		if tx.GasCoin == data.CoinToSell {
-			if context.Height() < upgrades.UpgradeBlock0 {
+			if !upgrades.IsActive(upgrades.FeatureBuyCoinCommissionReserve, context.Height()) {
 				toReserve.Sub(toReserve, baseCoinNeeded)
 			} else {
 				toReserve.Sub(toReserve, commissionInBaseCoin)
@@ -84,7 +84,7 @@ func (data CreateCoinData) String() string {
 		data.Symbol.String(), data.InitialReserve, data.InitialAmount, data.ConstantReserveRatio)
 }
 
-func (data CreateCoinData) Gas() int64 {
+func (data CreateCoinData) Gas(commissions types.Commissions) int64 {
 	switch len(data.Symbol.String()) {
 	case 3:
 		return 1000000000 // 1mln bips
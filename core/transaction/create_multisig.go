@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -48,8 +47,8 @@ func (data CreateMultisigData) String() string {
 	return fmt.Sprintf("CREATE MULTISIG")
 }
 
-func (data CreateMultisigData) Gas() int64 {
-	return commissions.CreateMultisig
+func (data CreateMultisigData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.CreateMultisig)
 }
 
 func (data CreateMultisigData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
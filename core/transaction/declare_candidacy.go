@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/core/validators"
@@ -67,8 +66,8 @@ func (data DeclareCandidacyData) String() string {
 		data.Address.String(), data.PubKey.String(), data.Commission)
 }
 
-func (data DeclareCandidacyData) Gas() int64 {
-	return commissions.DeclareCandidacyTx
+func (data DeclareCandidacyData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.DeclareCandidacyTx)
 }
 
 func (data DeclareCandidacyData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
@@ -79,7 +78,7 @@ func (data DeclareCandidacyData) Run(tx *Transaction, context *state.StateDB, is
 		return *response
 	}
 
-	maxCandidatesCount := validators.GetCandidatesCountForBlock(currentBlock)
+	maxCandidatesCount := validators.CandidatesCountForBlock(context.GetChainParams(), currentBlock)
 
 	if context.CandidatesCount() >= maxCandidatesCount && !context.IsNewCandidateStakeSufficient(data.Coin, data.Stake) {
 		return Response{
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/rlp"
 	"reflect"
+	"sort"
 )
 
 var TxDecoder = Decoder{
@@ -26,6 +27,10 @@ func init() {
 	TxDecoder.RegisterType(TypeCreateMultisig, CreateMultisigData{})
 	TxDecoder.RegisterType(TypeMultisend, MultisendData{})
 	TxDecoder.RegisterType(TypeEditCandidate, EditCandidateData{})
+	TxDecoder.RegisterType(TypeSubmitProposal, SubmitProposalData{})
+	TxDecoder.RegisterType(TypeVote, VoteData{})
+	TxDecoder.RegisterType(TypeUnjail, UnjailData{})
+	TxDecoder.RegisterType(TypeSetAutoCompound, SetAutoCompoundData{})
 }
 
 type Decoder struct {
@@ -36,6 +41,20 @@ func (decoder *Decoder) RegisterType(t TxType, d Data) {
 	decoder.registeredTypes[t] = d
 }
 
+// RegisteredTypes returns all registered tx types in ascending order
+func (decoder *Decoder) RegisteredTypes() []TxType {
+	var txTypes []TxType
+	for t := range decoder.registeredTypes {
+		txTypes = append(txTypes, t)
+	}
+
+	sort.Slice(txTypes, func(i, j int) bool {
+		return txTypes[i] < txTypes[j]
+	})
+
+	return txTypes
+}
+
 func (decoder *Decoder) DecodeFromBytes(buf []byte) (*Transaction, error) {
 	var tx Transaction
 	err := rlp.DecodeBytes(buf, &tx)
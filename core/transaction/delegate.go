@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -64,8 +63,8 @@ func (data DelegateData) String() string {
 		hexutil.Encode(data.PubKey))
 }
 
-func (data DelegateData) Gas() int64 {
-	return commissions.DelegateTx
+func (data DelegateData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.DelegateTx)
 }
 
 func (data DelegateData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -40,8 +39,8 @@ func (data EditCandidateData) String() string {
 		data.PubKey)
 }
 
-func (data EditCandidateData) Gas() int64 {
-	return commissions.EditCandidate
+func (data EditCandidateData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.EditCandidate)
 }
 
 func (data EditCandidateData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
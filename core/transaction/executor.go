@@ -6,6 +6,7 @@ import (
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/log"
+	"github.com/MinterTeam/minter-go-node/upgrades"
 	"github.com/tendermint/tendermint/libs/common"
 	"math/big"
 	"sync"
@@ -60,6 +61,14 @@ func RunTx(context *state.StateDB,
 			Log:  "Wrong chain id"}
 	}
 
+	tx.SetCommissions(context.GetChainParams().Commissions)
+
+	if feature, ok := txTypeFeatures[tx.Type]; ok && !upgrades.IsActive(feature, context.Height()) {
+		return Response{
+			Code: code.TxTypeNotActive,
+			Log:  fmt.Sprintf("Tx type %d is not active until %s feature is activated", tx.Type, feature)}
+	}
+
 	if !context.CoinExists(tx.GasCoin) {
 		return Response{
 			Code: code.CoinNotExists,
@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -49,8 +48,8 @@ func (data MultisendData) String() string {
 	return fmt.Sprintf("MULTISEND")
 }
 
-func (data MultisendData) Gas() int64 {
-	return commissions.SendTx + ((int64(len(data.List)) - 1) * commissions.MultisendDelta)
+func (data MultisendData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.SendTx) + ((int64(len(data.List)) - 1) * int64(commissions.MultisendDelta))
 }
 
 func (data MultisendData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
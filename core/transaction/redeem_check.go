@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/check"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/crypto"
@@ -58,8 +57,8 @@ func (data RedeemCheckData) String() string {
 	return fmt.Sprintf("REDEEM CHECK proof: %x", data.Proof)
 }
 
-func (data RedeemCheckData) Gas() int64 {
-	return commissions.RedeemCheckTx
+func (data RedeemCheckData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.RedeemCheckTx)
 }
 
 func (data RedeemCheckData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
@@ -77,7 +76,7 @@ func (data RedeemCheckData) Run(tx *Transaction, context *state.StateDB, isCheck
 			Log:  err.Error()}
 	}
 
-	if context.Height() > upgrades.UpgradeBlock1 {
+	if upgrades.IsActive(upgrades.FeatureCheckChainID, context.Height()) {
 		if decodedCheck.ChainID != types.CurrentChainID {
 			return Response{
 				Code: code.WrongChainID,
@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -158,8 +157,8 @@ func (data SellAllCoinData) String() string {
 		data.CoinToSell.String(), data.CoinToBuy.String())
 }
 
-func (data SellAllCoinData) Gas() int64 {
-	return commissions.ConvertTx
+func (data SellAllCoinData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.ConvertTx)
 }
 
 func (data SellAllCoinData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
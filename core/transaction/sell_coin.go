@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -264,8 +263,8 @@ func (data SellCoinData) String() string {
 		data.ValueToSell.String(), data.CoinToBuy.String(), data.CoinToSell.String())
 }
 
-func (data SellCoinData) Gas() int64 {
-	return commissions.ConvertTx
+func (data SellCoinData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.ConvertTx)
 }
 
 func (data SellCoinData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -72,8 +71,8 @@ func (data SendData) String() string {
 		data.To.String(), data.Coin.String(), data.Value.String())
 }
 
-func (data SendData) Gas() int64 {
-	return commissions.SendTx
+func (data SendData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.SendTx)
 }
 
 func (data SendData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
@@ -0,0 +1,87 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/formula"
+	"github.com/tendermint/tendermint/libs/common"
+	"math/big"
+)
+
+// SetAutoCompoundData switches adding of delegator rewards of sender to its BIP stakes instead of balance
+type SetAutoCompoundData struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (data SetAutoCompoundData) TotalSpend(tx *Transaction, context *state.StateDB) (TotalSpends, []Conversion, *big.Int, *Response) {
+	panic("implement me")
+}
+
+func (data SetAutoCompoundData) BasicCheck(tx *Transaction, context *state.StateDB) *Response {
+	return nil
+}
+
+func (data SetAutoCompoundData) String() string {
+	return fmt.Sprintf("SET AUTO COMPOUND enabled: %t",
+		data.Enabled)
+}
+
+func (data SetAutoCompoundData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.SetAutoCompound)
+}
+
+func (data SetAutoCompoundData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
+	sender, _ := tx.Sender()
+
+	response := data.BasicCheck(tx, context)
+	if response != nil {
+		return *response
+	}
+
+	commissionInBaseCoin := tx.CommissionInBaseCoin()
+	commission := big.NewInt(0).Set(commissionInBaseCoin)
+
+	if !tx.GasCoin.IsBaseCoin() {
+		coin := context.GetStateCoin(tx.GasCoin)
+
+		if coin.ReserveBalance().Cmp(commissionInBaseCoin) < 0 {
+			return Response{
+				Code: code.CoinReserveNotSufficient,
+				Log:  fmt.Sprintf("Coin reserve balance is not sufficient for transaction. Has: %s, required %s", coin.ReserveBalance().String(), commissionInBaseCoin.String())}
+		}
+
+		commission = formula.CalculateSaleAmount(coin.Volume(), coin.ReserveBalance(), coin.Data().Crr, commissionInBaseCoin)
+	}
+
+	if context.GetBalance(sender, tx.GasCoin).Cmp(commission) < 0 {
+		return Response{
+			Code: code.InsufficientFunds,
+			Log:  fmt.Sprintf("Insufficient funds for sender account: %s. Wanted %s %s", sender.String(), commission, tx.GasCoin)}
+	}
+
+	if !isCheck {
+		rewardPool.Add(rewardPool, commissionInBaseCoin)
+
+		context.SubCoinReserve(tx.GasCoin, commissionInBaseCoin)
+		context.SubCoinVolume(tx.GasCoin, commission)
+
+		context.SubBalance(sender, tx.GasCoin, commission)
+		context.SetAutoCompound(sender, data.Enabled)
+		context.SetNonce(sender, tx.Nonce)
+	}
+
+	tags := common.KVPairs{
+		common.KVPair{Key: []byte("tx.type"), Value: []byte(hex.EncodeToString([]byte{byte(TypeSetAutoCompound)}))},
+		common.KVPair{Key: []byte("tx.from"), Value: []byte(hex.EncodeToString(sender[:]))},
+	}
+
+	return Response{
+		Code:      code.OK,
+		GasUsed:   tx.Gas(),
+		GasWanted: tx.Gas(),
+		Tags:      tags,
+	}
+}
@@ -0,0 +1,48 @@
+package transaction
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/upgrades"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestSetAutoCompoundTx(t *testing.T) {
+	cState := getStateWithFeature(t, upgrades.FeatureAutoCompound)
+
+	privateKey, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	coin := types.GetBaseCoin()
+	cState.AddBalance(addr, coin, helpers.BipToPip(big.NewInt(1000000)))
+
+	encodedTx := makeTestTx(t, privateKey, 1, TypeSetAutoCompound, SetAutoCompoundData{Enabled: true})
+
+	response := RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != code.OK {
+		t.Fatalf("Response code is not 0. Error %s", response.Log)
+	}
+
+	if !cState.IsAutoCompound(addr) {
+		t.Fatalf("Auto compound should be enabled")
+	}
+
+	targetBalance, _ := big.NewInt(0).SetString("999999900000000000000000", 10)
+	if balance := cState.GetBalance(addr, coin); balance.Cmp(targetBalance) != 0 {
+		t.Fatalf("Target %s balance is not correct. Expected %s, got %s", coin, targetBalance, balance)
+	}
+
+	encodedTx = makeTestTx(t, privateKey, 2, TypeSetAutoCompound, SetAutoCompoundData{Enabled: false})
+
+	response = RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != code.OK {
+		t.Fatalf("Response code is not 0. Error %s", response.Log)
+	}
+
+	if cState.IsAutoCompound(addr) {
+		t.Fatalf("Auto compound should be disabled")
+	}
+}
@@ -0,0 +1,129 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/formula"
+	"github.com/tendermint/tendermint/libs/common"
+	"math/big"
+	"strconv"
+)
+
+const maxProposalParamLength = 64
+
+type SubmitProposalData struct {
+	Type   state.ProposalType `json:"type"`
+	Param  string             `json:"param"`
+	Value  uint64             `json:"value"`
+	Height uint64             `json:"height"`
+}
+
+func (data SubmitProposalData) TotalSpend(tx *Transaction, context *state.StateDB) (TotalSpends, []Conversion, *big.Int, *Response) {
+	panic("implement me")
+}
+
+func (data SubmitProposalData) BasicCheck(tx *Transaction, context *state.StateDB) *Response {
+	if len(data.Param) == 0 || len(data.Param) > maxProposalParamLength {
+		return &Response{
+			Code: code.InvalidProposal,
+			Log:  fmt.Sprintf("Proposal param should be from 1 to %d bytes", maxProposalParamLength)}
+	}
+
+	params := context.GetChainParams()
+
+	switch data.Type {
+	case state.ProposalTypeParamChange:
+		if err := params.SetParam(data.Param, data.Value); err != nil {
+			return &Response{
+				Code: code.InvalidProposal,
+				Log:  err.Error()}
+		}
+
+		if err := params.Validate(); err != nil {
+			return &Response{
+				Code: code.InvalidProposal,
+				Log:  err.Error()}
+		}
+	case state.ProposalTypeUpgrade:
+	default:
+		return &Response{
+			Code: code.InvalidProposal,
+			Log:  fmt.Sprintf("Unknown proposal type %d", data.Type)}
+	}
+
+	if votingEndHeight := context.Height() + params.ProposalVotingPeriod; data.Height <= votingEndHeight {
+		return &Response{
+			Code: code.InvalidProposal,
+			Log:  fmt.Sprintf("Proposal height should be greater than end of voting period %d", votingEndHeight)}
+	}
+
+	return nil
+}
+
+func (data SubmitProposalData) String() string {
+	return fmt.Sprintf("SUBMIT PROPOSAL type: %s param: %s value: %d height: %d",
+		data.Type, data.Param, data.Value, data.Height)
+}
+
+func (data SubmitProposalData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.SubmitProposal)
+}
+
+func (data SubmitProposalData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
+	sender, _ := tx.Sender()
+
+	response := data.BasicCheck(tx, context)
+	if response != nil {
+		return *response
+	}
+
+	commissionInBaseCoin := tx.CommissionInBaseCoin()
+	commission := big.NewInt(0).Set(commissionInBaseCoin)
+
+	if !tx.GasCoin.IsBaseCoin() {
+		coin := context.GetStateCoin(tx.GasCoin)
+
+		if coin.ReserveBalance().Cmp(commissionInBaseCoin) < 0 {
+			return Response{
+				Code: code.CoinReserveNotSufficient,
+				Log:  fmt.Sprintf("Coin reserve balance is not sufficient for transaction. Has: %s, required %s", coin.ReserveBalance().String(), commissionInBaseCoin.String())}
+		}
+
+		commission = formula.CalculateSaleAmount(coin.Volume(), coin.ReserveBalance(), coin.Data().Crr, commissionInBaseCoin)
+	}
+
+	if context.GetBalance(sender, tx.GasCoin).Cmp(commission) < 0 {
+		return Response{
+			Code: code.InsufficientFunds,
+			Log:  fmt.Sprintf("Insufficient funds for sender account: %s. Wanted %s %s", sender.String(), commission, tx.GasCoin)}
+	}
+
+	proposalID := context.NextProposalID()
+
+	if !isCheck {
+		rewardPool.Add(rewardPool, commissionInBaseCoin)
+
+		context.SubCoinReserve(tx.GasCoin, commissionInBaseCoin)
+		context.SubCoinVolume(tx.GasCoin, commission)
+
+		context.SubBalance(sender, tx.GasCoin, commission)
+		proposalID = context.CreateProposal(sender, data.Type, data.Param, data.Value, data.Height)
+		context.SetNonce(sender, tx.Nonce)
+	}
+
+	tags := common.KVPairs{
+		common.KVPair{Key: []byte("tx.type"), Value: []byte(hex.EncodeToString([]byte{byte(TypeSubmitProposal)}))},
+		common.KVPair{Key: []byte("tx.from"), Value: []byte(hex.EncodeToString(sender[:]))},
+		common.KVPair{Key: []byte("tx.proposal_id"), Value: []byte(strconv.FormatUint(proposalID, 10))},
+	}
+
+	return Response{
+		Code:      code.OK,
+		GasUsed:   tx.Gas(),
+		GasWanted: tx.Gas(),
+		Tags:      tags,
+	}
+}
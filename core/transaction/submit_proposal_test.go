@@ -0,0 +1,101 @@
+package transaction
+
+import (
+	"crypto/ecdsa"
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/rlp"
+	"github.com/MinterTeam/minter-go-node/upgrades"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func makeTestTx(t *testing.T, privateKey *ecdsa.PrivateKey, nonce uint64, txType TxType, data interface{}) []byte {
+	encodedData, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := Transaction{
+		Nonce:         nonce,
+		GasPrice:      1,
+		ChainID:       types.CurrentChainID,
+		GasCoin:       types.GetBaseCoin(),
+		Type:          txType,
+		Data:          encodedData,
+		SignatureType: SigTypeSingle,
+	}
+
+	if err := tx.Sign(privateKey); err != nil {
+		t.Fatal(err)
+	}
+
+	encodedTx, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return encodedTx
+}
+
+func TestSubmitProposalTx(t *testing.T) {
+	cState := getStateWithFeature(t, upgrades.FeatureGovernance)
+
+	privateKey, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	cState.AddBalance(addr, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(1000000)))
+
+	height := cState.Height() + cState.GetChainParams().ProposalVotingPeriod + 1
+	encodedTx := makeTestTx(t, privateKey, 1, TypeSubmitProposal, SubmitProposalData{
+		Type:   state.ProposalTypeParamChange,
+		Param:  "commissions.send_tx",
+		Value:  1,
+		Height: height,
+	})
+
+	response := RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != 0 {
+		t.Fatalf("Response code is not 0. Error %s", response.Log)
+	}
+
+	proposal := cState.GetProposal(1)
+	if proposal == nil {
+		t.Fatalf("Proposal is not created")
+	}
+
+	if proposal.Proposer != addr || proposal.Param != "commissions.send_tx" || proposal.Value != 1 ||
+		proposal.Height != height || proposal.Status != state.ProposalStatusVoting {
+		t.Fatalf("Proposal is not correct: %+v", proposal)
+	}
+}
+
+func TestSubmitProposalTxInvalidProposal(t *testing.T) {
+	cState := getStateWithFeature(t, upgrades.FeatureGovernance)
+
+	privateKey, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	cState.AddBalance(addr, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(1000000)))
+
+	height := cState.Height() + cState.GetChainParams().ProposalVotingPeriod + 1
+	proposals := []SubmitProposalData{
+		{Type: state.ProposalTypeParamChange, Param: "first_reward", Value: 1, Height: height},
+		{Type: state.ProposalTypeParamChange, Param: "unknown", Value: 1, Height: height},
+		{Type: state.ProposalTypeParamChange, Param: "pay_rewards_interval", Value: 0, Height: height},
+		{Type: state.ProposalTypeParamChange, Param: "unbond_period", Value: 1, Height: height - 1},
+		{Type: state.ProposalTypeUpgrade, Param: "", Height: height},
+		{Type: 0xFF, Param: "unbond_period", Height: height},
+	}
+
+	for _, data := range proposals {
+		encodedTx := makeTestTx(t, privateKey, 1, TypeSubmitProposal, data)
+
+		response := RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+		if response.Code != code.InvalidProposal {
+			t.Errorf("Proposal %+v should be invalid, got code %d", data, response.Code)
+		}
+	}
+}
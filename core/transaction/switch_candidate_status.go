@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -25,7 +24,17 @@ func (data SetCandidateOnData) TotalSpend(tx *Transaction, context *state.StateD
 }
 
 func (data SetCandidateOnData) BasicCheck(tx *Transaction, context *state.StateDB) *Response {
-	return checkCandidateOwnership(data, tx, context)
+	if response := checkCandidateOwnership(data, tx, context); response != nil {
+		return response
+	}
+
+	if context.IsCandidateJailed(data.PubKey) {
+		return &Response{
+			Code: code.CandidateJailed,
+			Log:  fmt.Sprintf("Candidate is jailed, send unjail transaction instead")}
+	}
+
+	return nil
 }
 
 func (data SetCandidateOnData) String() string {
@@ -33,8 +42,8 @@ func (data SetCandidateOnData) String() string {
 		data.PubKey)
 }
 
-func (data SetCandidateOnData) Gas() int64 {
-	return commissions.ToggleCandidateStatus
+func (data SetCandidateOnData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.ToggleCandidateStatus)
 }
 
 func (data SetCandidateOnData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
@@ -111,8 +120,8 @@ func (data SetCandidateOffData) String() string {
 		data.PubKey)
 }
 
-func (data SetCandidateOffData) Gas() int64 {
-	return commissions.ToggleCandidateStatus
+func (data SetCandidateOffData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.ToggleCandidateStatus)
 }
 
 func (data SetCandidateOffData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
@@ -4,12 +4,12 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/crypto"
 	"github.com/MinterTeam/minter-go-node/crypto/sha3"
 	"github.com/MinterTeam/minter-go-node/rlp"
+	"github.com/MinterTeam/minter-go-node/upgrades"
 	"math/big"
 )
 
@@ -31,11 +31,23 @@ const (
 	TypeCreateMultisig      TxType = 0x0C
 	TypeMultisend           TxType = 0x0D
 	TypeEditCandidate       TxType = 0x0E
+	TypeSubmitProposal      TxType = 0x0F
+	TypeVote                TxType = 0x10
+	TypeUnjail              TxType = 0x11
+	TypeSetAutoCompound     TxType = 0x12
 
 	SigTypeSingle SigType = 0x01
 	SigTypeMulti  SigType = 0x02
 )
 
+// txTypeFeatures are features which enable tx types, such txs are rejected before activation of the feature
+var txTypeFeatures = map[TxType]upgrades.Feature{
+	TypeSubmitProposal:  upgrades.FeatureGovernance,
+	TypeVote:            upgrades.FeatureGovernance,
+	TypeUnjail:          upgrades.FeatureJail,
+	TypeSetAutoCompound: upgrades.FeatureAutoCompound,
+}
+
 var (
 	ErrInvalidSig = errors.New("invalid transaction v, r, s values")
 	MaxCoinSupply = big.NewInt(0).Exp(big.NewInt(10), big.NewInt(15+18), nil) // 1,000,000,000,000,000 bips
@@ -57,6 +69,7 @@ type Transaction struct {
 	sig         *Signature
 	multisig    *SignatureMulti
 	sender      *types.Address
+	commissions *types.Commissions
 }
 
 type Signature struct {
@@ -104,7 +117,7 @@ type Conversion struct {
 
 type Data interface {
 	String() string
-	Gas() int64
+	Gas(commissions types.Commissions) int64
 	TotalSpend(tx *Transaction, context *state.StateDB) (TotalSpends, []Conversion, *big.Int, *Response)
 	BasicCheck(tx *Transaction, context *state.StateDB) *Response
 	Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response
@@ -114,12 +127,17 @@ func (tx *Transaction) Serialize() ([]byte, error) {
 	return rlp.EncodeToBytes(tx)
 }
 
+// Gas returns gas of tx according to commissions set by SetCommissions
 func (tx *Transaction) Gas() int64 {
-	return tx.decodedData.Gas() + tx.payloadGas()
+	if tx.commissions == nil {
+		panic("commissions of tx are not set")
+	}
+
+	return tx.decodedData.Gas(*tx.commissions) + tx.payloadGas()
 }
 
 func (tx *Transaction) payloadGas() int64 {
-	return int64(len(tx.Payload)+len(tx.ServiceData)) * commissions.PayloadByte
+	return int64(len(tx.Payload)+len(tx.ServiceData)) * int64(tx.commissions.PayloadByte)
 }
 
 func (tx *Transaction) CommissionInBaseCoin() *big.Int {
@@ -237,6 +255,11 @@ func (tx *Transaction) GetDecodedData() Data {
 	return tx.decodedData
 }
 
+// SetCommissions sets commissions of the chain which are used to compute gas of tx
+func (tx *Transaction) SetCommissions(commissions types.Commissions) {
+	tx.commissions = &commissions
+}
+
 func (tx *Transaction) SetMultisigAddress(address types.Address) {
 	if tx.multisig == nil {
 		tx.multisig = &SignatureMulti{}
@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/MinterTeam/minter-go-node/core/code"
-	"github.com/MinterTeam/minter-go-node/core/commissions"
 	"github.com/MinterTeam/minter-go-node/core/state"
 	"github.com/MinterTeam/minter-go-node/core/types"
 	"github.com/MinterTeam/minter-go-node/formula"
@@ -13,8 +12,6 @@ import (
 	"math/big"
 )
 
-const unbondPeriod = 518400
-
 type UnbondData struct {
 	PubKey types.Pubkey     `json:"pub_key"`
 	Coin   types.CoinSymbol `json:"coin"`
@@ -69,8 +66,8 @@ func (data UnbondData) String() string {
 		hexutil.Encode(data.PubKey))
 }
 
-func (data UnbondData) Gas() int64 {
-	return commissions.UnbondTx
+func (data UnbondData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.UnbondTx)
 }
 
 func (data UnbondData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
@@ -104,7 +101,7 @@ func (data UnbondData) Run(tx *Transaction, context *state.StateDB, isCheck bool
 
 	if !isCheck {
 		// now + 30 days
-		unbondAtBlock := uint64(currentBlock + unbondPeriod)
+		unbondAtBlock := currentBlock + context.GetChainParams().UnbondPeriod
 
 		rewardPool.Add(rewardPool, commissionInBaseCoin)
 
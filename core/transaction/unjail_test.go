@@ -0,0 +1,85 @@
+package transaction
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/upgrades"
+	"math/big"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestUnjailTx(t *testing.T) {
+	cState := getStateWithFeature(t, upgrades.FeatureJail)
+
+	params := cState.GetChainParams()
+	params.JailPeriod = 10
+	cState.SetChainParams(params)
+
+	privateKey, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	coin := types.GetBaseCoin()
+	cState.AddBalance(addr, coin, helpers.BipToPip(big.NewInt(1000000)))
+
+	pubkey := make([]byte, 32)
+	rand.Read(pubkey)
+
+	stake := helpers.BipToPip(big.NewInt(100))
+	cState.CreateCandidate(addr, addr, pubkey, 10, 0, coin, stake)
+	cState.CreateValidator(addr, pubkey, 10, 0, coin, stake)
+	cState.SetCandidateOnline(pubkey)
+
+	encodedTx := makeTestTx(t, privateKey, 1, TypeUnjail, UnjailData{PubKey: pubkey})
+
+	response := RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != code.CandidateNotJailed {
+		t.Fatalf("Response code is not %d. Got %d", code.CandidateNotJailed, response.Code)
+	}
+
+	address := cState.GetStateValidators().Data()[0].GetAddress()
+	for i := uint64(0); i <= params.ValidatorMaxAbsentTimes; i++ {
+		cState.SetValidatorAbsent(address)
+		if _, _, err := cState.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !cState.IsCandidateJailed(pubkey) {
+		t.Fatalf("Candidate should be jailed")
+	}
+
+	encodedOnTx := makeTestTx(t, privateKey, 1, TypeSetCandidateOnline, SetCandidateOnData{PubKey: pubkey})
+
+	response = RunTx(cState, false, encodedOnTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != code.CandidateJailed {
+		t.Fatalf("Response code is not %d. Got %d", code.CandidateJailed, response.Code)
+	}
+
+	response = RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != code.JailNotExpired {
+		t.Fatalf("Response code is not %d. Got %d", code.JailNotExpired, response.Code)
+	}
+
+	for cState.Height() < cState.GetJailRecord(pubkey).JailedUntil {
+		if _, _, err := cState.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	response = RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != code.OK {
+		t.Fatalf("Response code is not 0. Error %s", response.Log)
+	}
+
+	if cState.IsCandidateJailed(pubkey) {
+		t.Fatalf("Candidate should be unjailed")
+	}
+
+	if status := cState.GetStateCandidate(pubkey).Status; status != state.CandidateStatusOnline {
+		t.Fatalf("Unjailed candidate should be online, got status %d", status)
+	}
+}
@@ -0,0 +1,312 @@
+package transaction
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	c "github.com/MinterTeam/minter-go-node/core/check"
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/crypto/sha3"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/rlp"
+	"github.com/MinterTeam/minter-go-node/upgrades"
+	"github.com/tendermint/tendermint/libs/db"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+)
+
+// testFeaturesHeight is activation height of features which are not scheduled on public chains yet
+const testFeaturesHeight = 100
+
+func TestMain(m *testing.M) {
+	for _, feature := range []upgrades.Feature{upgrades.FeatureGovernance, upgrades.FeatureJail,
+		upgrades.FeatureAutoCompound} {
+		if _, ok := upgrades.ActivationHeight(feature); !ok {
+			upgrades.SetActivationHeight(feature, testFeaturesHeight)
+		}
+	}
+
+	os.Exit(m.Run())
+}
+
+// getStateAtHeight returns an empty state which height is equal to given one
+func getStateAtHeight(height uint64) *state.StateDB {
+	s, err := state.New(height-1, db.NewMemDB(), false)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// getStateWithFeature returns an empty state at activation height of feature
+func getStateWithFeature(t *testing.T, feature upgrades.Feature) *state.StateDB {
+	return getStateAtHeight(getActivationHeights(t, feature)[1])
+}
+
+// getActivationHeights returns the last height before activation of feature and the activation height
+func getActivationHeights(t *testing.T, feature upgrades.Feature) []uint64 {
+	height, ok := upgrades.ActivationHeight(feature)
+	if !ok {
+		t.Fatalf("Feature %s is not scheduled", feature)
+	}
+
+	return []uint64{height - 1, height}
+}
+
+func TestBuyCoinTxCommissionReserveActivation(t *testing.T) {
+	feature := upgrades.FeatureBuyCoinCommissionReserve
+
+	for _, height := range getActivationHeights(t, feature) {
+		cState := getStateAtHeight(height)
+
+		coinToSell := types.StrToCoinSymbol("TEST1")
+		coinToBuy := types.StrToCoinSymbol("TEST2")
+		cState.CreateCoin(coinToSell, "TEST COIN 1", helpers.BipToPip(big.NewInt(100000)), 10,
+			helpers.BipToPip(big.NewInt(100000)))
+		cState.CreateCoin(coinToBuy, "TEST COIN 2", helpers.BipToPip(big.NewInt(100000)), 10,
+			helpers.BipToPip(big.NewInt(100000)))
+
+		privateKey, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+		cState.AddBalance(addr, coinToSell, helpers.BipToPip(big.NewInt(10000)))
+
+		encodedData, err := rlp.EncodeToBytes(BuyCoinData{
+			CoinToBuy:          coinToBuy,
+			ValueToBuy:         helpers.BipToPip(big.NewInt(10)),
+			CoinToSell:         coinToSell,
+			MaximumValueToSell: helpers.BipToPip(big.NewInt(10000)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tx := Transaction{
+			Nonce:         1,
+			GasPrice:      1,
+			ChainID:       types.CurrentChainID,
+			GasCoin:       coinToSell,
+			Type:          TypeBuyCoin,
+			Data:          encodedData,
+			SignatureType: SigTypeSingle,
+		}
+
+		if err := tx.Sign(privateKey); err != nil {
+			t.Fatal(err)
+		}
+
+		encodedTx, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reserve := big.NewInt(0).Set(cState.GetStateCoin(coinToBuy).ReserveBalance())
+
+		response := RunTx(cState, false, encodedTx, big.NewInt(0), height, sync.Map{}, 0)
+		if response.Code != 0 {
+			t.Fatalf("Response code is not 0 at height %d. Error %s", height, response.Log)
+		}
+
+		newReserve := cState.GetStateCoin(coinToBuy).ReserveBalance()
+		active := upgrades.IsActive(feature, height)
+		if reserveChanged := newReserve.Cmp(reserve) != 0; reserveChanged != active {
+			t.Fatalf("Reserve of bought coin should change only if %s is active. Height %d, reserve %s, new "+
+				"reserve %s", feature, height, reserve, newReserve)
+		}
+	}
+}
+
+func makeRedeemCheckTx(t *testing.T, checkChainID types.ChainID, senderPrivateKey,
+	receiverPrivateKey *ecdsa.PrivateKey) []byte {
+	passphraseHash := sha256.Sum256([]byte("password"))
+	passphrasePk, err := crypto.ToECDSA(passphraseHash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := c.Check{
+		Nonce:    []byte{1, 2, 3},
+		ChainID:  checkChainID,
+		DueBlock: 1 << 32,
+		Coin:     types.GetBaseCoin(),
+		Value:    helpers.BipToPip(big.NewInt(10)),
+	}
+
+	lock, err := crypto.Sign(check.HashWithoutLock().Bytes(), passphrasePk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check.Lock = big.NewInt(0).SetBytes(lock)
+
+	if err := check.Sign(senderPrivateKey); err != nil {
+		t.Fatal(err)
+	}
+
+	rawCheck, _ := rlp.EncodeToBytes(check)
+
+	var senderAddressHash types.Hash
+	hw := sha3.NewKeccak256()
+	_ = rlp.Encode(hw, []interface{}{
+		crypto.PubkeyToAddress(receiverPrivateKey.PublicKey),
+	})
+	hw.Sum(senderAddressHash[:0])
+
+	sig, err := crypto.Sign(senderAddressHash.Bytes(), passphrasePk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof := [65]byte{}
+	copy(proof[:], sig)
+
+	encodedData, err := rlp.EncodeToBytes(RedeemCheckData{
+		RawCheck: rawCheck,
+		Proof:    proof,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := Transaction{
+		Nonce:         1,
+		GasPrice:      1,
+		ChainID:       types.CurrentChainID,
+		GasCoin:       types.GetBaseCoin(),
+		Type:          TypeRedeemCheck,
+		Data:          encodedData,
+		SignatureType: SigTypeSingle,
+	}
+
+	if err := tx.Sign(receiverPrivateKey); err != nil {
+		t.Fatal(err)
+	}
+
+	encodedTx, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return encodedTx
+}
+
+func TestRedeemCheckTxChainIDActivation(t *testing.T) {
+	feature := upgrades.FeatureCheckChainID
+
+	for _, height := range getActivationHeights(t, feature) {
+		cState := getStateAtHeight(height)
+
+		senderPrivateKey, _ := crypto.GenerateKey()
+		senderAddr := crypto.PubkeyToAddress(senderPrivateKey.PublicKey)
+		cState.AddBalance(senderAddr, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(1000000)))
+
+		receiverPrivateKey, _ := crypto.GenerateKey()
+
+		encodedTx := makeRedeemCheckTx(t, types.ChainTestnet, senderPrivateKey, receiverPrivateKey)
+		response := RunTx(cState, false, encodedTx, big.NewInt(0), height, sync.Map{}, 0)
+
+		expectedCode := code.OK
+		if upgrades.IsActive(feature, height) {
+			expectedCode = code.WrongChainID
+		}
+
+		if response.Code != expectedCode {
+			t.Fatalf("Check of another chain at height %d should give code %d, got %d: %s", height, expectedCode,
+				response.Code, response.Log)
+		}
+	}
+}
+
+func TestSubmitProposalTxGovernanceActivation(t *testing.T) {
+	feature := upgrades.FeatureGovernance
+
+	for _, height := range getActivationHeights(t, feature) {
+		cState := getStateAtHeight(height)
+
+		privateKey, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+		cState.AddBalance(addr, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(1000000)))
+
+		encodedTx := makeTestTx(t, privateKey, 1, TypeSubmitProposal, SubmitProposalData{
+			Type:   state.ProposalTypeParamChange,
+			Param:  "commissions.send_tx",
+			Value:  1,
+			Height: height + cState.GetChainParams().ProposalVotingPeriod + 1,
+		})
+		response := RunTx(cState, false, encodedTx, big.NewInt(0), height, sync.Map{}, 0)
+
+		expectedCode := code.TxTypeNotActive
+		if upgrades.IsActive(feature, height) {
+			expectedCode = code.OK
+		}
+
+		if response.Code != expectedCode {
+			t.Fatalf("Proposal at height %d should give code %d, got %d: %s", height, expectedCode, response.Code,
+				response.Log)
+		}
+	}
+}
+
+func TestUnjailTxJailActivation(t *testing.T) {
+	feature := upgrades.FeatureJail
+
+	for _, height := range getActivationHeights(t, feature) {
+		cState := getStateAtHeight(height)
+
+		privateKey, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+		cState.AddBalance(addr, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(1000000)))
+
+		pubkey := make([]byte, 32)
+		pubkey[0] = 1
+
+		stake := helpers.BipToPip(big.NewInt(100))
+		cState.CreateCandidate(addr, addr, pubkey, 10, 0, types.GetBaseCoin(), stake)
+
+		encodedTx := makeTestTx(t, privateKey, 1, TypeUnjail, UnjailData{PubKey: pubkey})
+		response := RunTx(cState, false, encodedTx, big.NewInt(0), height, sync.Map{}, 0)
+
+		expectedCode := code.TxTypeNotActive
+		if upgrades.IsActive(feature, height) {
+			expectedCode = code.CandidateNotJailed
+		}
+
+		if response.Code != expectedCode {
+			t.Fatalf("Unjail at height %d should give code %d, got %d: %s", height, expectedCode, response.Code,
+				response.Log)
+		}
+	}
+}
+
+func TestSetAutoCompoundTxAutoCompoundActivation(t *testing.T) {
+	feature := upgrades.FeatureAutoCompound
+
+	for _, height := range getActivationHeights(t, feature) {
+		cState := getStateAtHeight(height)
+
+		privateKey, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+		cState.AddBalance(addr, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(1000000)))
+
+		encodedTx := makeTestTx(t, privateKey, 1, TypeSetAutoCompound, SetAutoCompoundData{Enabled: true})
+		response := RunTx(cState, false, encodedTx, big.NewInt(0), height, sync.Map{}, 0)
+
+		expectedCode := code.TxTypeNotActive
+		if upgrades.IsActive(feature, height) {
+			expectedCode = code.OK
+		}
+
+		if response.Code != expectedCode {
+			t.Fatalf("SetAutoCompound at height %d should give code %d, got %d: %s", height, expectedCode,
+				response.Code, response.Log)
+		}
+
+		if cState.IsAutoCompound(addr) != upgrades.IsActive(feature, height) {
+			t.Fatalf("Auto compound at height %d should be enabled only after activation", height)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/formula"
+	"github.com/tendermint/tendermint/libs/common"
+	"math/big"
+	"strconv"
+)
+
+type VoteData struct {
+	ProposalID uint64           `json:"proposal_id"`
+	Option     state.VoteOption `json:"option"`
+}
+
+func (data VoteData) TotalSpend(tx *Transaction, context *state.StateDB) (TotalSpends, []Conversion, *big.Int, *Response) {
+	panic("implement me")
+}
+
+func (data VoteData) BasicCheck(tx *Transaction, context *state.StateDB) *Response {
+	if data.Option != state.VoteYes && data.Option != state.VoteNo && data.Option != state.VoteAbstain {
+		return &Response{
+			Code: code.InvalidVoteOption,
+			Log:  fmt.Sprintf("Unknown vote option %d", data.Option)}
+	}
+
+	proposal := context.GetProposal(data.ProposalID)
+	if proposal == nil {
+		return &Response{
+			Code: code.ProposalNotFound,
+			Log:  fmt.Sprintf("Proposal %d not found", data.ProposalID)}
+	}
+
+	if proposal.Status != state.ProposalStatusVoting || context.Height() > proposal.VotingEndHeight {
+		return &Response{
+			Code: code.ProposalVotingClosed,
+			Log:  fmt.Sprintf("Voting for proposal %d is closed", data.ProposalID)}
+	}
+
+	sender, _ := tx.Sender()
+	if context.GetVotingPower(sender).Sign() == 0 {
+		return &Response{
+			Code: code.IsNotOwnerOfValidator,
+			Log:  fmt.Sprintf("Sender is not an owner of a validator")}
+	}
+
+	return nil
+}
+
+func (data VoteData) String() string {
+	return fmt.Sprintf("VOTE proposal: %d option: %s",
+		data.ProposalID, data.Option)
+}
+
+func (data VoteData) Gas(commissions types.Commissions) int64 {
+	return int64(commissions.Vote)
+}
+
+func (data VoteData) Run(tx *Transaction, context *state.StateDB, isCheck bool, rewardPool *big.Int, currentBlock uint64) Response {
+	sender, _ := tx.Sender()
+
+	response := data.BasicCheck(tx, context)
+	if response != nil {
+		return *response
+	}
+
+	commissionInBaseCoin := tx.CommissionInBaseCoin()
+	commission := big.NewInt(0).Set(commissionInBaseCoin)
+
+	if !tx.GasCoin.IsBaseCoin() {
+		coin := context.GetStateCoin(tx.GasCoin)
+
+		if coin.ReserveBalance().Cmp(commissionInBaseCoin) < 0 {
+			return Response{
+				Code: code.CoinReserveNotSufficient,
+				Log:  fmt.Sprintf("Coin reserve balance is not sufficient for transaction. Has: %s, required %s", coin.ReserveBalance().String(), commissionInBaseCoin.String())}
+		}
+
+		commission = formula.CalculateSaleAmount(coin.Volume(), coin.ReserveBalance(), coin.Data().Crr, commissionInBaseCoin)
+	}
+
+	if context.GetBalance(sender, tx.GasCoin).Cmp(commission) < 0 {
+		return Response{
+			Code: code.InsufficientFunds,
+			Log:  fmt.Sprintf("Insufficient funds for sender account: %s. Wanted %s %s", sender.String(), commission, tx.GasCoin)}
+	}
+
+	if !isCheck {
+		rewardPool.Add(rewardPool, commissionInBaseCoin)
+
+		context.SubCoinReserve(tx.GasCoin, commissionInBaseCoin)
+		context.SubCoinVolume(tx.GasCoin, commission)
+
+		context.SubBalance(sender, tx.GasCoin, commission)
+		context.SetProposalVote(data.ProposalID, sender, data.Option)
+		context.SetNonce(sender, tx.Nonce)
+	}
+
+	tags := common.KVPairs{
+		common.KVPair{Key: []byte("tx.type"), Value: []byte(hex.EncodeToString([]byte{byte(TypeVote)}))},
+		common.KVPair{Key: []byte("tx.from"), Value: []byte(hex.EncodeToString(sender[:]))},
+		common.KVPair{Key: []byte("tx.proposal_id"), Value: []byte(strconv.FormatUint(data.ProposalID, 10))},
+	}
+
+	return Response{
+		Code:      code.OK,
+		GasUsed:   tx.Gas(),
+		GasWanted: tx.Gas(),
+		Tags:      tags,
+	}
+}
@@ -0,0 +1,83 @@
+package transaction
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/code"
+	"github.com/MinterTeam/minter-go-node/core/state"
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"github.com/MinterTeam/minter-go-node/crypto"
+	"github.com/MinterTeam/minter-go-node/helpers"
+	"github.com/MinterTeam/minter-go-node/upgrades"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestVoteTx(t *testing.T) {
+	cState := getStateWithFeature(t, upgrades.FeatureGovernance)
+
+	privateKey, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	cState.AddBalance(addr, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(1000000)))
+
+	pubkey := make([]byte, 32)
+	stake := helpers.BipToPip(big.NewInt(100))
+	cState.CreateCandidate(addr, addr, pubkey, 10, 0, types.GetBaseCoin(), stake)
+	cState.CreateValidator(addr, pubkey, 10, 0, types.GetBaseCoin(), stake)
+
+	height := cState.Height() + cState.GetChainParams().ProposalVotingPeriod + 1
+	id := cState.CreateProposal(addr, state.ProposalTypeUpgrade, "v2", 0, height)
+
+	encodedTx := makeTestTx(t, privateKey, 1, TypeVote, VoteData{
+		ProposalID: id,
+		Option:     state.VoteYes,
+	})
+
+	response := RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != 0 {
+		t.Fatalf("Response code is not 0. Error %s", response.Log)
+	}
+
+	votes := cState.GetProposalVotes(id)
+	if len(votes) != 1 || votes[0].Voter != addr || votes[0].Option != state.VoteYes {
+		t.Fatalf("Vote is not stored, got %+v", votes)
+	}
+}
+
+func TestVoteTxNotValidatorOwner(t *testing.T) {
+	cState := getStateWithFeature(t, upgrades.FeatureGovernance)
+
+	privateKey, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	cState.AddBalance(addr, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(1000000)))
+
+	height := cState.Height() + cState.GetChainParams().ProposalVotingPeriod + 1
+	id := cState.CreateProposal(addr, state.ProposalTypeUpgrade, "v2", 0, height)
+
+	encodedTx := makeTestTx(t, privateKey, 1, TypeVote, VoteData{
+		ProposalID: id,
+		Option:     state.VoteYes,
+	})
+
+	response := RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != code.IsNotOwnerOfValidator {
+		t.Fatalf("Response code is not %d. Got %d", code.IsNotOwnerOfValidator, response.Code)
+	}
+}
+
+func TestVoteTxProposalNotFound(t *testing.T) {
+	cState := getStateWithFeature(t, upgrades.FeatureGovernance)
+
+	privateKey, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	cState.AddBalance(addr, types.GetBaseCoin(), helpers.BipToPip(big.NewInt(1000000)))
+
+	encodedTx := makeTestTx(t, privateKey, 1, TypeVote, VoteData{
+		ProposalID: 1,
+		Option:     state.VoteYes,
+	})
+
+	response := RunTx(cState, false, encodedTx, big.NewInt(0), 0, sync.Map{}, 0)
+	if response.Code != code.ProposalNotFound {
+		t.Fatalf("Response code is not %d. Got %d", code.ProposalNotFound, response.Code)
+	}
+}
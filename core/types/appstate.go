@@ -15,6 +15,7 @@ type AppState struct {
 	UsedChecks   []UsedCheck  `json:"used_checks,omitempty"`
 	MaxGas       uint64       `json:"max_gas"`
 	TotalSlashed *big.Int     `json:"total_slashed"`
+	ChainParams  *ChainParams `json:"chain_params,omitempty"`
 }
 
 type Validator struct {
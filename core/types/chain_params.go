@@ -0,0 +1,221 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ChainParams are economic parameters of a chain. They are set in genesis, stored in state and can be changed
+// afterwards only by governance proposals. Chains without parameters in genesis use DefaultChainParams.
+// Params are stored in state with positional rlp encoding, changing fields requires a new version of the encoding.
+type ChainParams struct {
+	// Block reward in BIP starts with FirstReward and decreases by 1 BIP every RewardDecreaseInterval blocks,
+	// but never goes below MinReward. LastReward is paid at RewardsLastBlock, there are no rewards after it.
+	FirstReward            uint64 `json:"first_reward" yaml:"first_reward"`
+	LastReward             uint64 `json:"last_reward" yaml:"last_reward"`
+	MinReward              uint64 `json:"min_reward" yaml:"min_reward"`
+	RewardDecreaseInterval uint64 `json:"reward_decrease_interval" yaml:"reward_decrease_interval"`
+	RewardsLastBlock       uint64 `json:"rewards_last_block" yaml:"rewards_last_block"`
+
+	// Validators count starts with ValidatorsCount and grows by ValidatorsCountStep every ValidatorsCountInterval
+	// blocks up to MaxValidatorsCount
+	ValidatorsCount         uint64 `json:"validators_count" yaml:"validators_count"`
+	ValidatorsCountStep     uint64 `json:"validators_count_step" yaml:"validators_count_step"`
+	ValidatorsCountInterval uint64 `json:"validators_count_interval" yaml:"validators_count_interval"`
+	MaxValidatorsCount      uint64 `json:"max_validators_count" yaml:"max_validators_count"`
+	CandidatesPerValidator  uint64 `json:"candidates_per_validator" yaml:"candidates_per_validator"`
+
+	UnbondPeriod             uint64 `json:"unbond_period" yaml:"unbond_period"`
+	ValidatorMaxAbsentWindow uint64 `json:"validator_max_absent_window" yaml:"validator_max_absent_window"`
+	ValidatorMaxAbsentTimes  uint64 `json:"validator_max_absent_times" yaml:"validator_max_absent_times"`
+
+	// Validators which exceed ValidatorMaxAbsentTimes are jailed for JailPeriod blocks multiplied by number of their
+	// offences, 0 disables jailing
+	JailPeriod uint64 `json:"jail_period" yaml:"jail_period"`
+
+	DAOAddress           Address `json:"dao_address" yaml:"dao_address"`
+	DAOCommission        uint64  `json:"dao_commission" yaml:"dao_commission"`
+	DevelopersAddress    Address `json:"developers_address" yaml:"developers_address"`
+	DevelopersCommission uint64  `json:"developers_commission" yaml:"developers_commission"`
+
+	PayRewardsInterval       uint64 `json:"pay_rewards_interval" yaml:"pay_rewards_interval"`
+	UpdateValidatorsInterval uint64 `json:"update_validators_interval" yaml:"update_validators_interval"`
+	CheckInvariantsInterval  uint64 `json:"check_invariants_interval" yaml:"check_invariants_interval"`
+
+	// Max gas of a block is decreased by MaxGasDecreasePercent if last MaxGasWindow blocks took more than
+	// MaxGasTargetBlockTime seconds per block, otherwise it is increased by MaxGasIncreasePercent. Max gas is kept
+	// between MinMaxGas and MaxMaxGas.
+	MaxGasTargetBlockTime uint64 `json:"max_gas_target_block_time" yaml:"max_gas_target_block_time"`
+	MaxGasWindow          uint64 `json:"max_gas_window" yaml:"max_gas_window"`
+	MaxGasDecreasePercent uint64 `json:"max_gas_decrease_percent" yaml:"max_gas_decrease_percent"`
+	MaxGasIncreasePercent uint64 `json:"max_gas_increase_percent" yaml:"max_gas_increase_percent"`
+	MinMaxGas             uint64 `json:"min_max_gas" yaml:"min_max_gas"`
+	MaxMaxGas             uint64 `json:"max_max_gas" yaml:"max_max_gas"`
+
+	// ProposalVotingPeriod is number of blocks during which validators can vote for a governance proposal
+	ProposalVotingPeriod uint64 `json:"proposal_voting_period" yaml:"proposal_voting_period"`
+
+	Commissions Commissions `json:"commissions" yaml:"commissions"`
+}
+
+// Commissions are gas values of transactions, actual commission is gas * 10^15 PIP
+type Commissions struct {
+	SendTx                uint64 `json:"send_tx" yaml:"send_tx"`
+	CreateMultisig        uint64 `json:"create_multisig" yaml:"create_multisig"`
+	ConvertTx             uint64 `json:"convert_tx" yaml:"convert_tx"`
+	DeclareCandidacyTx    uint64 `json:"declare_candidacy_tx" yaml:"declare_candidacy_tx"`
+	DelegateTx            uint64 `json:"delegate_tx" yaml:"delegate_tx"`
+	UnbondTx              uint64 `json:"unbond_tx" yaml:"unbond_tx"`
+	PayloadByte           uint64 `json:"payload_byte" yaml:"payload_byte"`
+	ToggleCandidateStatus uint64 `json:"toggle_candidate_status" yaml:"toggle_candidate_status"`
+	EditCandidate         uint64 `json:"edit_candidate" yaml:"edit_candidate"`
+	MultisendDelta        uint64 `json:"multisend_delta" yaml:"multisend_delta"`
+	RedeemCheckTx         uint64 `json:"redeem_check_tx" yaml:"redeem_check_tx"`
+	SubmitProposal        uint64 `json:"submit_proposal" yaml:"submit_proposal"`
+	Vote                  uint64 `json:"vote" yaml:"vote"`
+	Unjail                uint64 `json:"unjail" yaml:"unjail"`
+	SetAutoCompound       uint64 `json:"set_auto_compound" yaml:"set_auto_compound"`
+}
+
+// DefaultChainParams returns parameters of Minter mainnet
+func DefaultChainParams() ChainParams {
+	return ChainParams{
+		FirstReward:            333,
+		LastReward:             68,
+		MinReward:              1,
+		RewardDecreaseInterval: 200000,
+		RewardsLastBlock:       43702611,
+
+		ValidatorsCount:         16,
+		ValidatorsCountStep:     4,
+		ValidatorsCountInterval: 518400,
+		MaxValidatorsCount:      256,
+		CandidatesPerValidator:  3,
+
+		UnbondPeriod:             518400,
+		ValidatorMaxAbsentWindow: 24,
+		ValidatorMaxAbsentTimes:  12,
+
+		DAOAddress:           HexToAddress("Mx18467bbb64a8edf890201d526c35957d82be3d95"),
+		DAOCommission:        10,
+		DevelopersAddress:    HexToAddress("Mx04bea23efb744dc93b4fda4c20bf4a21c6e195f1"),
+		DevelopersCommission: 10,
+
+		PayRewardsInterval:       12,
+		UpdateValidatorsInterval: 120,
+		CheckInvariantsInterval:  720,
+
+		MaxGasTargetBlockTime: 7,
+		MaxGasWindow:          3,
+		MaxGasDecreasePercent: 30,
+		MaxGasIncreasePercent: 5,
+		MinMaxGas:             5000,
+		MaxMaxGas:             100000,
+
+		ProposalVotingPeriod: 17280,
+
+		Commissions: Commissions{
+			SendTx:                10,
+			CreateMultisig:        100,
+			ConvertTx:             100,
+			DeclareCandidacyTx:    10000,
+			DelegateTx:            200,
+			UnbondTx:              200,
+			PayloadByte:           2,
+			ToggleCandidateStatus: 100,
+			EditCandidate:         10000,
+			MultisendDelta:        5,
+			RedeemCheckTx:         30,
+			SubmitProposal:        10000,
+			Vote:                  100,
+			Unjail:                100,
+			SetAutoCompound:       100,
+		},
+	}
+}
+
+// Validate checks that parameters can drive a chain
+func (p ChainParams) Validate() error {
+	if p.RewardDecreaseInterval == 0 || p.ValidatorsCountInterval == 0 || p.PayRewardsInterval == 0 ||
+		p.UpdateValidatorsInterval == 0 || p.CheckInvariantsInterval == 0 || p.ProposalVotingPeriod == 0 {
+		return errors.New("intervals of chain params should be positive")
+	}
+
+	if p.ValidatorsCount == 0 || p.CandidatesPerValidator == 0 || p.MaxValidatorsCount < p.ValidatorsCount {
+		return errors.New("validators count should be positive and not greater than max validators count")
+	}
+
+	if p.ValidatorMaxAbsentWindow == 0 || p.ValidatorMaxAbsentTimes >= p.ValidatorMaxAbsentWindow {
+		return errors.New("validator max absent times should be less than absent window")
+	}
+
+	if p.MaxGasTargetBlockTime == 0 || p.MaxGasWindow == 0 || p.MaxGasDecreasePercent >= 100 {
+		return errors.New("max gas target block time and window should be positive, decrease should be below 100%")
+	}
+
+	if p.MinMaxGas == 0 || p.MinMaxGas > p.MaxMaxGas {
+		return errors.New("min max gas should be positive and not greater than max max gas")
+	}
+
+	if p.DAOCommission+p.DevelopersCommission > 100 {
+		return errors.New("sum of DAO and developers commissions should not exceed 100")
+	}
+
+	return nil
+}
+
+// nonGovernableParams can't be changed by governance proposals. BIP emission is calculated from the whole rewards
+// schedule, absent times of validators are stored as bit arrays of validator_max_absent_window size.
+var nonGovernableParams = map[string]bool{
+	"first_reward":                true,
+	"last_reward":                 true,
+	"min_reward":                  true,
+	"reward_decrease_interval":    true,
+	"rewards_last_block":          true,
+	"validator_max_absent_window": true,
+}
+
+// SetParam sets a parameter by its json name, parameters of nested sections are named as "section.param", e.g.
+// "commissions.send_tx". Only numeric parameters can be set.
+func (p *ChainParams) SetParam(name string, value uint64) error {
+	if nonGovernableParams[name] {
+		return fmt.Errorf("param %s can't be changed", name)
+	}
+
+	field, ok := findParam(reflect.ValueOf(p).Elem(), name)
+	if !ok {
+		return fmt.Errorf("unknown param %s", name)
+	}
+
+	field.SetUint(value)
+
+	return nil
+}
+
+func findParam(v reflect.Value, name string) (reflect.Value, bool) {
+	section, rest := name, ""
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		section, rest = name[:i], name[i+1:]
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		if strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0] != section {
+			continue
+		}
+
+		field := v.Field(i)
+		if rest == "" && field.Kind() == reflect.Uint64 {
+			return field, true
+		}
+
+		if rest != "" && field.Kind() == reflect.Struct {
+			return findParam(field, rest)
+		}
+
+		break
+	}
+
+	return reflect.Value{}, false
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestChainParams_SetParam(t *testing.T) {
+	params := DefaultChainParams()
+
+	if err := params.SetParam("unbond_period", 100); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := params.SetParam("commissions.send_tx", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if params.UnbondPeriod != 100 || params.Commissions.SendTx != 1 {
+		t.Fatalf("Params are not set, got unbond period %d, send tx commission %d", params.UnbondPeriod,
+			params.Commissions.SendTx)
+	}
+
+	for _, name := range []string{"first_reward", "validator_max_absent_window", "unknown", "commissions", "dao_address", "commissions.unknown"} {
+		if err := params.SetParam(name, 1); err == nil {
+			t.Errorf("Param %s should not be set", name)
+		}
+	}
+}
+
+func TestChainParams_ValidateMaxGas(t *testing.T) {
+	if err := DefaultChainParams().Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, change := range []func(p *ChainParams){
+		func(p *ChainParams) { p.MaxGasWindow = 0 },
+		func(p *ChainParams) { p.MaxGasTargetBlockTime = 0 },
+		func(p *ChainParams) { p.MaxGasDecreasePercent = 100 },
+		func(p *ChainParams) { p.MinMaxGas = 0 },
+		func(p *ChainParams) { p.MinMaxGas = p.MaxMaxGas + 1 },
+	} {
+		params := DefaultChainParams()
+		change(&params)
+
+		if err := params.Validate(); err == nil {
+			t.Errorf("Params %+v should be invalid", params)
+		}
+	}
+}
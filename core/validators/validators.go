@@ -1,22 +1,34 @@
 package validators
 
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"math/big"
+)
+
 var startHeight uint64 = 0
 
-func GetValidatorsCountForBlock(block uint64) int {
+// ValidatorsCountForBlock returns validators count at given block of a chain with given params
+func ValidatorsCountForBlock(chainParams types.ChainParams, block uint64) int {
 	block += startHeight
-	count := 16 + (block/518400)*4
+	count := chainParams.ValidatorsCount + (block/chainParams.ValidatorsCountInterval)*chainParams.ValidatorsCountStep
 
-	if count > 256 {
-		return 256
+	if count > chainParams.MaxValidatorsCount {
+		return int(chainParams.MaxValidatorsCount)
 	}
 
 	return int(count)
 }
 
-func GetCandidatesCountForBlock(block uint64) int {
-	return GetValidatorsCountForBlock(block) * 3
+// CandidatesCountForBlock returns max candidates count at given block of a chain with given params
+func CandidatesCountForBlock(chainParams types.ChainParams, block uint64) int {
+	return ValidatorsCountForBlock(chainParams, block) * int(chainParams.CandidatesPerValidator)
 }
 
 func SetStartHeight(sHeight uint64) {
 	startHeight = sHeight
 }
+
+// GetValidatorPower returns voting power of a validator, which is its share of total stake multiplied by 10^8
+func GetValidatorPower(stake *big.Int, totalStake *big.Int) int64 {
+	return big.NewInt(0).Div(big.NewInt(0).Mul(stake, big.NewInt(100000000)), totalStake).Int64()
+}
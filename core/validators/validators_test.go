@@ -1,6 +1,8 @@
 package validators
 
 import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"math/big"
 	"testing"
 )
 
@@ -29,11 +31,24 @@ func TestGetValidatorsCountForBlock(t *testing.T) {
 		},
 	}
 
+	params := types.DefaultChainParams()
 	for _, item := range data {
-		result := GetValidatorsCountForBlock(item.Block)
+		result := ValidatorsCountForBlock(params, item.Block)
 
 		if result != item.Result {
-			t.Errorf("GetValidatorsCountForBlock result is not correct. Expected %d, got %d", item.Result, result)
+			t.Errorf("ValidatorsCountForBlock result is not correct. Expected %d, got %d", item.Result, result)
 		}
 	}
 }
+
+func TestGetValidatorPower(t *testing.T) {
+	totalStake := big.NewInt(300)
+
+	if power := GetValidatorPower(big.NewInt(100), totalStake); power != 33333333 {
+		t.Errorf("GetValidatorPower result is not correct. Expected %d, got %d", 33333333, power)
+	}
+
+	if power := GetValidatorPower(totalStake, totalStake); power != 100000000 {
+		t.Errorf("GetValidatorPower result is not correct. Expected %d, got %d", 100000000, power)
+	}
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"encoding/json"
+	"github.com/MinterTeam/minter-go-node/core/types"
+)
+
+type JailEvent struct {
+	ValidatorPubKey types.Pubkey
+	JailedUntil     uint64
+	Offences        uint64
+	SlashPercent    uint64
+}
+
+func (e JailEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ValidatorPubKey types.Pubkey `json:"validator_pub_key"`
+		JailedUntil     uint64       `json:"jailed_until"`
+		Offences        uint64       `json:"offences"`
+		SlashPercent    uint64       `json:"slash_percent"`
+	}{
+		ValidatorPubKey: e.ValidatorPubKey,
+		JailedUntil:     e.JailedUntil,
+		Offences:        e.Offences,
+		SlashPercent:    e.SlashPercent,
+	})
+}
+
+type UnjailEvent struct {
+	ValidatorPubKey types.Pubkey
+}
+
+func (e UnjailEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ValidatorPubKey types.Pubkey `json:"validator_pub_key"`
+	}{
+		ValidatorPubKey: e.ValidatorPubKey,
+	})
+}
@@ -0,0 +1,33 @@
+package events
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+type ProposalEvent struct {
+	ProposalID uint64
+	Status     string
+	Yes        []byte
+	No         []byte
+	Abstain    []byte
+	Total      []byte
+}
+
+func (e ProposalEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ProposalID uint64 `json:"proposal_id"`
+		Status     string `json:"status"`
+		Yes        string `json:"yes"`
+		No         string `json:"no"`
+		Abstain    string `json:"abstain"`
+		Total      string `json:"total"`
+	}{
+		ProposalID: e.ProposalID,
+		Status:     e.Status,
+		Yes:        big.NewInt(0).SetBytes(e.Yes).String(),
+		No:         big.NewInt(0).SetBytes(e.No).String(),
+		Abstain:    big.NewInt(0).SetBytes(e.Abstain).String(),
+		Total:      big.NewInt(0).SetBytes(e.Total).String(),
+	})
+}
@@ -12,6 +12,12 @@ func RegisterAminoEvents(codec *amino.Codec) {
 		"minter/UnbondEvent", nil)
 	codec.RegisterConcrete(CoinLiquidationEvent{},
 		"minter/CoinLiquidationEvent", nil)
+	codec.RegisterConcrete(ProposalEvent{},
+		"minter/ProposalEvent", nil)
+	codec.RegisterConcrete(JailEvent{},
+		"minter/JailEvent", nil)
+	codec.RegisterConcrete(UnjailEvent{},
+		"minter/UnjailEvent", nil)
 }
 
 type Role byte
@@ -26,6 +32,8 @@ func (r Role) String() string {
 		return "DAO"
 	case RoleDevelopers:
 		return "Developers"
+	case RoleAutoCompound:
+		return "AutoCompound"
 	}
 
 	return "Undefined"
@@ -36,6 +44,8 @@ const (
 	RoleDelegator
 	RoleDAO
 	RoleDevelopers
+	// RoleAutoCompound is a delegator reward added to BIP stake of the delegator
+	RoleAutoCompound
 )
 
 type Event interface{}
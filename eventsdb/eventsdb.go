@@ -12,26 +12,17 @@ import (
 
 var cdc = amino.NewCodec()
 
-var edb IEventsDB
-
 func init() {
 	e.RegisterAminoEvents(cdc)
 }
 
-func InitDB(cfg *config.Config) {
+// Open opens events db in the Minter home directory. Events are not stored in validator mode.
+func Open(cfg *config.Config) IEventsDB {
 	if cfg.ValidatorMode {
-		edb = NOOPEventsDB{}
-	} else {
-		edb = NewEventsDB(db.NewDB("events", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data"))
+		return NOOPEventsDB{}
 	}
-}
 
-func GetCurrent() IEventsDB {
-	if edb == nil {
-		panic("Events db is not initialized")
-	}
-
-	return edb
+	return NewEventsDB(db.NewDB("events", db.DBBackendType(cfg.DBBackend), utils.GetMinterHome()+"/data"))
 }
 
 type IEventsDB interface {
@@ -145,6 +136,42 @@ func (db *EventsDB) LoadEvents(height uint64) e.Events {
 	return decoded
 }
 
+// LastHeight returns the latest height with saved events
+func (db *EventsDB) LastHeight() uint64 {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	it := db.db.ReverseIterator(nil, nil)
+	defer it.Close()
+
+	if !it.Valid() {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(it.Key())
+}
+
+// Rollback removes events of all blocks above given height
+func (db *EventsDB) Rollback(height uint64) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	var keys [][]byte
+	it := db.db.Iterator(getKeyForHeight(height+1), nil)
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, it.Key())
+	}
+	it.Close()
+
+	batch := db.db.NewBatch()
+	for _, key := range keys {
+		batch.Delete(key)
+	}
+	batch.WriteSync()
+
+	db.cache.Clear()
+}
+
 func (db *EventsDB) getEvents(height uint64) e.Events {
 	if db.cache.height == height {
 		return db.cache.get()
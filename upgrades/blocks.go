@@ -1,4 +0,0 @@
-package upgrades
-
-const UpgradeBlock0 = 5760
-const UpgradeBlock1 = 250000
@@ -0,0 +1,128 @@
+package upgrades
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"sort"
+)
+
+// Feature is a named change of blockchain behaviour which is activated at some height
+type Feature string
+
+const (
+	// FeatureBuyCoinCommissionReserve takes only commission, not the whole amount, from reserve of the sold coin
+	// when it is also used as a gas coin in BuyCoin tx
+	FeatureBuyCoinCommissionReserve Feature = "buy_coin_commission_reserve"
+
+	// FeatureEmissionCorrection excludes BIP lost before FeatureBuyCoinCommissionReserve from expected emission
+	FeatureEmissionCorrection Feature = "emission_correction"
+
+	// FeatureSimulatedStakeValue compares new stakes with the smallest stake of a full candidate by simulated BIP value
+	FeatureSimulatedStakeValue Feature = "simulated_stake_value"
+
+	// FeatureCheckChainID rejects checks issued for another chain
+	FeatureCheckChainID Feature = "check_chain_id"
+
+	// FeatureGovernance enables SubmitProposal and Vote txs
+	FeatureGovernance Feature = "governance"
+
+	// FeatureJail jails validators for jail_period blocks with penalty growing with their offences and enables
+	// Unjail tx
+	FeatureJail Feature = "jail"
+
+	// FeatureAutoCompound enables SetAutoCompound tx and adds delegator rewards of such addresses to their BIP stakes
+	FeatureAutoCompound Feature = "auto_compound"
+)
+
+// Schedule maps features to their activation heights
+type Schedule map[Feature]uint64
+
+// schedules of public chains. Features which are not scheduled yet, such as governance, jail and auto compound, are
+// disabled until a release sets their activation heights.
+var schedules = map[types.ChainID]Schedule{
+	types.ChainMainnet: {
+		FeatureBuyCoinCommissionReserve: 5760,
+		FeatureEmissionCorrection:       5760,
+		FeatureSimulatedStakeValue:      250001,
+		FeatureCheckChainID:             250001,
+	},
+	types.ChainTestnet: {
+		FeatureBuyCoinCommissionReserve: 5760,
+		FeatureEmissionCorrection:       5760,
+		FeatureSimulatedStakeValue:      250001,
+		FeatureCheckChainID:             250001,
+	},
+}
+
+// supportedUpgrades are names of upgrades approved by governance which are implemented by this version of the node.
+// The node halts at height of an approved upgrade it doesn't support.
+var supportedUpgrades = map[string]bool{}
+
+// IsUpgradeSupported reports whether this version of the node can continue after the upgrade with given name
+func IsUpgradeSupported(name string) bool {
+	return supportedUpgrades[name]
+}
+
+// SetActivationHeight schedules feature at given height on the current chain. It's used to test features which are
+// not scheduled on public chains yet. Returned function restores the previous schedule of the feature.
+func SetActivationHeight(feature Feature, height uint64) (restore func()) {
+	schedule, ok := schedules[types.CurrentChainID]
+	if !ok {
+		schedule = Schedule{}
+		schedules[types.CurrentChainID] = schedule
+	}
+
+	prevHeight, scheduled := schedule[feature]
+	schedule[feature] = height
+
+	return func() {
+		if scheduled {
+			schedule[feature] = prevHeight
+			return
+		}
+
+		delete(schedule, feature)
+	}
+}
+
+// FeatureStatus describes a feature as it is seen at some height
+type FeatureStatus struct {
+	Name             Feature `json:"name"`
+	ActivationHeight uint64  `json:"activation_height"`
+	Active           bool    `json:"active"`
+}
+
+// IsActive reports whether feature is active at given height of the current chain. Features missing in the schedule
+// of the chain are never active.
+func IsActive(feature Feature, height uint64) bool {
+	activationHeight, ok := ActivationHeight(feature)
+	return ok && height >= activationHeight
+}
+
+// ActivationHeight returns height at which feature is activated on the current chain
+func ActivationHeight(feature Feature) (uint64, bool) {
+	height, ok := schedules[types.CurrentChainID][feature]
+	return height, ok
+}
+
+// Features returns statuses of all features scheduled on the current chain at given height, ordered by activation
+// height and name
+func Features(height uint64) []FeatureStatus {
+	var features []FeatureStatus
+	for feature, activationHeight := range schedules[types.CurrentChainID] {
+		features = append(features, FeatureStatus{
+			Name:             feature,
+			ActivationHeight: activationHeight,
+			Active:           height >= activationHeight,
+		})
+	}
+
+	sort.Slice(features, func(i, j int) bool {
+		if features[i].ActivationHeight != features[j].ActivationHeight {
+			return features[i].ActivationHeight < features[j].ActivationHeight
+		}
+
+		return features[i].Name < features[j].Name
+	})
+
+	return features
+}
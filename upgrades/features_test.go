@@ -0,0 +1,58 @@
+package upgrades
+
+import (
+	"github.com/MinterTeam/minter-go-node/core/types"
+	"testing"
+)
+
+func TestIsActive(t *testing.T) {
+	for feature := range schedules[types.CurrentChainID] {
+		height, ok := ActivationHeight(feature)
+		if !ok {
+			t.Fatalf("Feature %s should be scheduled", feature)
+		}
+
+		if IsActive(feature, height-1) {
+			t.Errorf("Feature %s should not be active before height %d", feature, height)
+		}
+
+		if !IsActive(feature, height) {
+			t.Errorf("Feature %s should be active at height %d", feature, height)
+		}
+	}
+
+	if IsActive("unknown", 1<<60) {
+		t.Errorf("Unknown feature should never be active")
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	features := Features(5760)
+	if len(features) != len(schedules[types.CurrentChainID]) {
+		t.Fatalf("Expected %d features, got %d", len(schedules[types.CurrentChainID]), len(features))
+	}
+
+	for i, feature := range features {
+		if i > 0 && features[i-1].ActivationHeight > feature.ActivationHeight {
+			t.Errorf("Features should be ordered by activation height")
+		}
+
+		if feature.Active != (feature.ActivationHeight <= 5760) {
+			t.Errorf("Feature %s has wrong status", feature.Name)
+		}
+	}
+}
+
+func TestSetActivationHeight(t *testing.T) {
+	var feature Feature = "test_feature"
+
+	restore := SetActivationHeight(feature, 100)
+	if !IsActive(feature, 100) || IsActive(feature, 99) {
+		t.Fatalf("Feature %s should be active from height 100", feature)
+	}
+
+	restore()
+	if _, ok := ActivationHeight(feature); ok {
+		t.Fatalf("Feature %s should not be scheduled after restore", feature)
+	}
+}